@@ -20,8 +20,133 @@ const (
 	GroupUserMember GroupUserRole = "member"
 )
 
+// MidpointInclusion is the admin's choice of whether a member counts towards the group midpoint
+type MidpointInclusion string
+
+const (
+	// MidpointInclusionAuto includes the member unless they are detected as an outlier
+	MidpointInclusionAuto MidpointInclusion = "auto"
+	// MidpointInclusionIncluded always includes the member, even if they are an outlier
+	MidpointInclusionIncluded MidpointInclusion = "included"
+	// MidpointInclusionExcluded never includes the member, but keeps them in the group
+	MidpointInclusionExcluded MidpointInclusion = "excluded"
+)
+
+// MidpointExclusionReason explains why a member was left out of the last midpoint calculation
+type MidpointExclusionReason string
+
+const (
+	MidpointExclusionNone            MidpointExclusionReason = ""
+	MidpointExclusionByAdmin         MidpointExclusionReason = "excluded_by_admin"
+	MidpointExclusionDistanceOutlier MidpointExclusionReason = "distance_outlier"
+)
+
+// MidpointStrategy decides how the group midpoint is chosen from the members' locations
+type MidpointStrategy string
+
+const (
+	// MidpointStrategyCentroid is the average of the members' coordinates
+	MidpointStrategyCentroid MidpointStrategy = "centroid"
+	// MidpointStrategyMinTotalTime minimises the sum of everyone's travel time
+	MidpointStrategyMinTotalTime MidpointStrategy = "min_total_time"
+	// MidpointStrategyMinMaxTime minimises the longest travel time of any member
+	MidpointStrategyMinMaxTime MidpointStrategy = "min_max_time"
+)
+
+func SupportedMidpointStrategies() []MidpointStrategy {
+	return []MidpointStrategy{MidpointStrategyCentroid, MidpointStrategyMinTotalTime, MidpointStrategyMinMaxTime}
+}
+
+func IsSupportedMidpointStrategy(strategy MidpointStrategy) bool {
+	switch strategy {
+	case MidpointStrategyCentroid, MidpointStrategyMinTotalTime, MidpointStrategyMinMaxTime:
+		return true
+	default:
+		return false
+	}
+}
+
+// ClusterMethod decides how a large group is split into sub-clusters, each with its own midpoint
+type ClusterMethod string
+
+const (
+	// ClusterMethodNone keeps the whole group around a single midpoint
+	ClusterMethodNone ClusterMethod = "none"
+	// ClusterMethodKMeans splits the members into a fixed number of clusters
+	ClusterMethodKMeans ClusterMethod = "kmeans"
+	// ClusterMethodDBSCAN puts members within a maximum distance of each other in the same cluster
+	ClusterMethodDBSCAN ClusterMethod = "dbscan"
+)
+
+// MaxGroupClusters limits how many clusters a group can be split into, as each one needs its own place search
+const MaxGroupClusters = 10
+
+func IsSupportedClusterMethod(method ClusterMethod) bool {
+	switch method {
+	case ClusterMethodNone, ClusterMethodKMeans, ClusterMethodDBSCAN:
+		return true
+	default:
+		return false
+	}
+}
+
+// TravelMode is how a member gets to the meeting place
+type TravelMode string
+
+const (
+	TravelModeWalk    TravelMode = "walk"
+	TravelModeBike    TravelMode = "bike"
+	TravelModeDrive   TravelMode = "drive"
+	TravelModeTransit TravelMode = "transit"
+)
+
+// DefaultTravelMode is used for members who haven't picked a travel mode
+const DefaultTravelMode = TravelModeDrive
+
+func SupportedTravelModes() []TravelMode {
+	return []TravelMode{TravelModeWalk, TravelModeBike, TravelModeDrive, TravelModeTransit}
+}
+
+func IsSupportedTravelMode(mode TravelMode) bool {
+	switch mode {
+	case TravelModeWalk, TravelModeBike, TravelModeDrive, TravelModeTransit:
+		return true
+	default:
+		return false
+	}
+}
+
+// LocationPrivacy decides how precisely the other members of a group see where a member is
+// The midpoint is always calculated from the exact location
+type LocationPrivacy string
+
+const (
+	LocationPrivacyExact LocationPrivacy = "exact"
+	// LocationPrivacyApproximate shows the centre of the ApproximateLocationGridSize grid cell the member is in
+	LocationPrivacyApproximate LocationPrivacy = "approximate"
+	// LocationPrivacyHidden doesn't show the location at all
+	LocationPrivacyHidden LocationPrivacy = "hidden"
+)
+
+// DefaultLocationPrivacy is used for members who haven't picked a privacy level
+const DefaultLocationPrivacy = LocationPrivacyApproximate
+
+// ApproximateLocationGridSize in meters, approximate locations are at most ~350m (half the cell's diagonal) from the exact one
+const ApproximateLocationGridSize = 500
+
+func IsSupportedLocationPrivacy(privacy LocationPrivacy) bool {
+	switch privacy {
+	case LocationPrivacyExact, LocationPrivacyApproximate, LocationPrivacyHidden:
+		return true
+	default:
+		return false
+	}
+}
+
+// PlaceType is one of the types in the place type taxonomy, see place_types.go
 type PlaceType string
 
+// place types which code and tests refer to, the full list is in the taxonomy
 const (
 	PlaceTypeRestaurant PlaceType = "restaurant"
 	PlaceTypeBar        PlaceType = "bar"
@@ -31,20 +156,141 @@ const (
 	PlaceTypeBookstore  PlaceType = "bookstore"
 )
 
-func DefaultGroupPlaceTypes() []PlaceType {
-	return []PlaceType{
-		PlaceTypeRestaurant,
-		PlaceTypeBar,
-		PlaceTypeCafe,
-		PlaceTypePark,
+// JobStatus is where a background job is in its lifecycle
+type JobStatus string
+
+const (
+	// JobStatusQueued jobs run once their run_at has passed, this includes failed jobs waiting to be retried
+	JobStatusQueued  JobStatus = "queued"
+	JobStatusRunning JobStatus = "running"
+	// JobStatusDead jobs failed too many times, they are kept for admins to look at and retry
+	JobStatusDead JobStatus = "dead"
+)
+
+func IsSupportedJobStatus(status JobStatus) bool {
+	switch status {
+	case JobStatusQueued, JobStatusRunning, JobStatusDead:
+		return true
+	default:
+		return false
 	}
 }
 
-func IsSupportedPlaceType(placeType PlaceType) bool {
-	switch placeType {
-	case PlaceTypeRestaurant, PlaceTypeBar, PlaceTypeCafe, PlaceTypePark, PlaceTypeMuseum, PlaceTypeBookstore:
+// JobKindGroupRefresh recalculates a group's midpoint and clusters, and searches for places around them
+const JobKindGroupRefresh = "group_refresh"
+
+// PlaceVote is a member's upvote or downvote on one of the group's places
+type PlaceVote string
+
+const (
+	PlaceVoteUp   PlaceVote = "up"
+	PlaceVoteDown PlaceVote = "down"
+	// PlaceVoteNone takes back the member's vote
+	PlaceVoteNone PlaceVote = "none"
+)
+
+func IsSupportedPlaceVote(vote PlaceVote) bool {
+	switch vote {
+	case PlaceVoteUp, PlaceVoteDown, PlaceVoteNone:
 		return true
 	default:
 		return false
 	}
 }
+
+// PlacesSort is the order group places are listed in
+type PlacesSort string
+
+const (
+	// PlacesSortTravelTime puts the places where the last member arrives soonest first
+	PlacesSortTravelTime PlacesSort = "travel_time"
+	// PlacesSortVotes puts the places with the highest vote score first
+	PlacesSortVotes PlacesSort = "votes"
+	// PlacesSortScore puts the places with the highest ranking score first, see PlaceScoreWeights
+	PlacesSortScore PlacesSort = "score"
+)
+
+// PlaceScoreInput is one of the inputs of a group place's ranking score
+type PlaceScoreInput string
+
+const (
+	PlaceScoreInputRating             PlaceScoreInput = "rating"
+	PlaceScoreInputReviewCount        PlaceScoreInput = "review_count"
+	PlaceScoreInputMidpointDistance   PlaceScoreInput = "midpoint_distance"
+	PlaceScoreInputMaxMemberDistance  PlaceScoreInput = "max_member_distance"
+	PlaceScoreInputMeanMemberDistance PlaceScoreInput = "mean_member_distance"
+	PlaceScoreInputVotes              PlaceScoreInput = "votes"
+)
+
+// PlaceScoreInputs are all the inputs, in the order score breakdowns list them
+var PlaceScoreInputs = []PlaceScoreInput{
+	PlaceScoreInputRating,
+	PlaceScoreInputReviewCount,
+	PlaceScoreInputMidpointDistance,
+	PlaceScoreInputMaxMemberDistance,
+	PlaceScoreInputMeanMemberDistance,
+	PlaceScoreInputVotes,
+}
+
+// MaxRankedPlaces limits how many places a member can rank
+const MaxRankedPlaces = 20
+
+// PlaceSource tells where a group place came from
+type PlaceSource string
+
+const (
+	// PlaceSourceProvider places are found by the places provider, and replaced whenever the group is refreshed
+	PlaceSourceProvider PlaceSource = "provider"
+	// PlaceSourceCustom places are added by members, and stay until they are removed
+	PlaceSourceCustom PlaceSource = "custom"
+)
+
+// CustomPlaceIDPrefix marks the PlaceID of custom places, so they never clash with the provider's IDs
+const CustomPlaceIDPrefix = "custom:"
+
+// PriceLevel is how expensive a place is, on the same scale as the Google Places API
+type PriceLevel int
+
+const (
+	// PriceLevelUnknown is for places whose price the provider doesn't know
+	PriceLevelUnknown PriceLevel = iota
+	PriceLevelFree
+	PriceLevelInexpensive
+	PriceLevelModerate
+	PriceLevelExpensive
+	PriceLevelVeryExpensive
+)
+
+// MaxPlacesResultsPerType is the most places a search finds for each place type, which is the Google Places API limit
+const MaxPlacesResultsPerType = 20
+
+// MaxPlaceSearchRadius in meters, for ad-hoc place searches, which is the Google Places API limit
+const MaxPlaceSearchRadius = 50000
+
+// MaxPlaceSearchTextLength limits the text of ad-hoc place searches
+const MaxPlaceSearchTextLength = 100
+
+// MaxAddressLength limits the addresses members can join groups from
+const MaxAddressLength = 200
+
+// DefaultSavedLocation names the location saved on the user itself, which is set along with the user
+const DefaultSavedLocation = "default"
+
+// MaxSavedLocations is how many named locations a user can save, besides the default one
+const MaxSavedLocations = 10
+
+// MaxSavedLocationNameLength limits the names of saved locations, like "home" or "work"
+const MaxSavedLocationNameLength = 30
+
+// DefaultPlaceSearchPageSize is how many places a page of ad-hoc search results has, up to MaxPlacesResultsPerType
+const DefaultPlaceSearchPageSize = 10
+
+// PlacesOperation is a kind of call to the places provider, which is metered against the places budgets
+type PlacesOperation string
+
+const (
+	PlacesOperationNearbySearch PlacesOperation = "nearby_search"
+	PlacesOperationTextSearch   PlacesOperation = "text_search"
+	PlacesOperationPlaceDetails PlacesOperation = "place_details"
+	PlacesOperationPlacePhoto   PlacesOperation = "place_photo"
+)
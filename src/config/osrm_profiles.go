@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// ParseOSRMProfiles reads the OSRM profile of each travel mode, written like "drive=driving,bike=cycling"
+// Routed travel modes need a profile of their own, as a car's travel times are no use for someone walking.
+func ParseOSRMProfiles(profiles string) (map[TravelMode]string, error) {
+	parsed := map[TravelMode]string{}
+	for _, pair := range strings.Split(profiles, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		mode, profile, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(profile) == "" {
+			return nil, fmt.Errorf("OSRM profile %q is not mode=profile", pair)
+		}
+		if !lo.Contains(SupportedTravelModes(), TravelMode(strings.TrimSpace(mode))) {
+			return nil, fmt.Errorf("unknown travel mode %q", mode)
+		}
+		parsed[TravelMode(strings.TrimSpace(mode))] = strings.TrimSpace(profile)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOSRMProfiles(t *testing.T) {
+	profiles, err := ParseOSRMProfiles("drive=driving, bike=cycling,walk=foot")
+	assert.NoError(t, err)
+	assert.Equal(t, map[TravelMode]string{
+		TravelModeDrive: "driving",
+		TravelModeBike:  "cycling",
+		TravelModeWalk:  "foot",
+	}, profiles)
+
+	profiles, err = ParseOSRMProfiles("")
+	assert.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	for _, invalid := range []string{"drive", "drive=", "fly=flying"} {
+		_, err := ParseOSRMProfiles(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
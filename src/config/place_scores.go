@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// ParsePlaceScoreWeights reads weights written like "rating=2,votes=3"
+// Inputs left out weigh 0, so they don't count towards the score. At least one weight must be positive.
+func ParsePlaceScoreWeights(weights string) (map[PlaceScoreInput]float64, error) {
+	parsed := map[PlaceScoreInput]float64{}
+	for _, pair := range strings.Split(weights, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		input, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("place score weight %q is not input=weight", pair)
+		}
+		if !lo.Contains(PlaceScoreInputs, PlaceScoreInput(strings.TrimSpace(input))) {
+			return nil, fmt.Errorf("unknown place score input %q", input)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("place score weight of %s must be a number >= 0", input)
+		}
+		parsed[PlaceScoreInput(strings.TrimSpace(input))] = weight
+	}
+	if lo.Sum(lo.Values(parsed)) <= 0 {
+		return nil, fmt.Errorf("place score weights must have a positive weight")
+	}
+	return parsed, nil
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlaceScoreWeights(t *testing.T) {
+	weights, err := ParsePlaceScoreWeights("rating=2, votes=3.5,review_count=0")
+	assert.NoError(t, err)
+	assert.Equal(t, map[PlaceScoreInput]float64{
+		PlaceScoreInputRating:      2,
+		PlaceScoreInputVotes:       3.5,
+		PlaceScoreInputReviewCount: 0,
+	}, weights)
+
+	for _, invalid := range []string{"", "rating", "stars=1", "rating=-1", "rating=high", "rating=0,votes=0"} {
+		_, err := ParsePlaceScoreWeights(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
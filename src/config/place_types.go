@@ -0,0 +1,108 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/samber/lo"
+)
+
+// the place types shipped with the app, used unless PLACE_TYPES_FILE points to another file
+//
+//go:embed place_types.json
+var defaultPlaceTypesJSON []byte
+
+// PlaceTypeInfo describes one of the place types groups can search for
+type PlaceTypeInfo struct {
+	ID    PlaceType `json:"id"`
+	Label string    `json:"label"`
+	// Icon is the name of a Material Symbols icon
+	Icon string `json:"icon"`
+	// Default types are searched for in groups which haven't chosen their own
+	Default bool `json:"default"`
+	// Providers maps the name of a places provider to the types it uses for this one,
+	// e.g. Google place types, or key=value tags for OSM. Every place type needs at least one Google type.
+	Providers map[string][]string `json:"providers"`
+}
+
+var placeTypes []PlaceTypeInfo
+
+// LoadPlaceTypes reads the place type taxonomy from a JSON file, or the one shipped with the app if path is empty
+func LoadPlaceTypes(path string) ([]PlaceTypeInfo, error) {
+	contents := defaultPlaceTypesJSON
+	if path != "" {
+		var err error
+		if contents, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var taxonomy struct {
+		PlaceTypes []PlaceTypeInfo `json:"place_types"`
+	}
+	if err := json.Unmarshal(contents, &taxonomy); err != nil {
+		return nil, fmt.Errorf("invalid place types file: %w", err)
+	}
+	if len(taxonomy.PlaceTypes) == 0 {
+		return nil, errors.New("place types file has no place types")
+	}
+	for i, placeType := range taxonomy.PlaceTypes {
+		if placeType.ID == "" {
+			return nil, fmt.Errorf("place type %d has no id", i)
+		}
+		if lo.ContainsBy(taxonomy.PlaceTypes[:i], func(other PlaceTypeInfo) bool { return other.ID == placeType.ID }) {
+			return nil, fmt.Errorf("place type %s is listed twice", placeType.ID)
+		}
+		// Google searches for any kind of place when given no types, rather than for none
+		if len(placeType.Providers["google"]) == 0 {
+			return nil, fmt.Errorf("place type %s has no google types", placeType.ID)
+		}
+		for provider, types := range placeType.Providers {
+			if len(types) == 0 {
+				return nil, fmt.Errorf("place type %s has no %s types", placeType.ID, provider)
+			}
+		}
+	}
+	if !lo.SomeBy(taxonomy.PlaceTypes, func(placeType PlaceTypeInfo) bool { return placeType.Default }) {
+		return nil, errors.New("place types file has no default place types")
+	}
+	return taxonomy.PlaceTypes, nil
+}
+
+// SetPlaceTypes replaces the place type taxonomy
+func SetPlaceTypes(taxonomy []PlaceTypeInfo) {
+	placeTypes = taxonomy
+}
+
+// PlaceTypeInfos returns every place type, in the order of the taxonomy
+func PlaceTypeInfos() []PlaceTypeInfo {
+	return placeTypes
+}
+
+func DefaultGroupPlaceTypes() []PlaceType {
+	return lo.FilterMap(placeTypes, func(placeType PlaceTypeInfo, _ int) (PlaceType, bool) {
+		return placeType.ID, placeType.Default
+	})
+}
+
+func SupportedPlaceTypes() []PlaceType {
+	return lo.Map(placeTypes, func(placeType PlaceTypeInfo, _ int) PlaceType {
+		return placeType.ID
+	})
+}
+
+func IsSupportedPlaceType(placeType PlaceType) bool {
+	return lo.ContainsBy(placeTypes, func(info PlaceTypeInfo) bool { return info.ID == placeType })
+}
+
+// ProviderPlaceTypes returns what the places provider calls the place type, nothing if it has no such type
+func ProviderPlaceTypes(placeType PlaceType, provider string) []string {
+	info, found := lo.Find(placeTypes, func(info PlaceTypeInfo) bool { return info.ID == placeType })
+	if !found {
+		return []string{}
+	}
+	return lo.Ternary(info.Providers[provider] == nil, []string{}, info.Providers[provider])
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPlaceTypes_Default(t *testing.T) {
+	placeTypes, err := LoadPlaceTypes("")
+	require.NoError(t, err)
+	assert.Equal(t, placeTypes, PlaceTypeInfos())
+
+	assert.Equal(t, []PlaceType{PlaceTypeRestaurant, PlaceTypeBar, PlaceTypeCafe, PlaceTypePark}, DefaultGroupPlaceTypes())
+	assert.True(t, IsSupportedPlaceType(PlaceTypeBookstore))
+	assert.True(t, IsSupportedPlaceType("cinema"))
+	assert.False(t, IsSupportedPlaceType("castle"))
+	assert.Equal(t, []string{"amenity=cinema"}, ProviderPlaceTypes("cinema", "osm"))
+	assert.Equal(t, []string{}, ProviderPlaceTypes("cinema", "fake"))
+	assert.Equal(t, []string{}, ProviderPlaceTypes("castle", "google"))
+}
+
+func TestLoadPlaceTypes_File(t *testing.T) {
+	load := func(contents string) ([]PlaceTypeInfo, error) {
+		path := filepath.Join(t.TempDir(), "place_types.json")
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+		return LoadPlaceTypes(path)
+	}
+
+	placeTypes, err := load(`{"place_types": [
+		{"id": "climbing_gym", "label": "Climbing Gym", "icon": "landscape", "default": true,
+		 "providers": {"google": ["gym"], "osm": ["sport=climbing"]}}
+	]}`)
+	require.NoError(t, err)
+	assert.Equal(t, []PlaceTypeInfo{{
+		ID:        "climbing_gym",
+		Label:     "Climbing Gym",
+		Icon:      "landscape",
+		Default:   true,
+		Providers: map[string][]string{"google": {"gym"}, "osm": {"sport=climbing"}},
+	}}, placeTypes)
+
+	_, err = load(`{"place_types": []}`)
+	assert.Error(t, err)
+	_, err = load(`{"place_types": [{"id": "gym", "providers": {"google": ["gym"]}}]}`)
+	assert.ErrorContains(t, err, "no default")
+	_, err = load(`{"place_types": [
+		{"id": "gym", "default": true, "providers": {"google": ["gym"]}},
+		{"id": "gym", "providers": {"google": ["gym"]}}
+	]}`)
+	assert.ErrorContains(t, err, "listed twice")
+	_, err = load(`{"place_types": [{"id": "gym", "default": true, "providers": {"osm": ["leisure=fitness_centre"]}}]}`)
+	assert.ErrorContains(t, err, "no google types")
+	_, err = load(`{"place_types": [{"id": "gym", "default": true, "providers": {"google": ["gym"], "osm": []}}]}`)
+	assert.ErrorContains(t, err, "no osm types")
+	_, err = load(`{"place_types": [{"label": "Gym", "default": true}]}`)
+	assert.ErrorContains(t, err, "no id")
+	_, err = LoadPlaceTypes(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
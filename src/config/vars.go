@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/samber/lo"
 )
@@ -18,10 +20,113 @@ var Port string
 var JWTSigningKey string
 var JWTExpirationDays int
 
+// PasswordHashCost is the bcrypt cost of password hashes, tests use the lowest one to run faster
+var PasswordHashCost int
+
 var GoogleMapsAPIKey string
 
+// PlacesProvider is one of "google", "osm" or "fake"
+var PlacesProvider string
+var OSMPlacesFile string
+
+// PlacesCache is one of "memory", "redis" or "none"
+var PlacesCache string
+var PlacesCacheTTL time.Duration
+var PlacesCacheMaxEntries int
+
+// PlacesCacheGeohashPrecision decides how close two searches must be to share results (6 is ~1km)
+var PlacesCacheGeohashPrecision int
+
+// PlacesCacheRadiusBucket in meters, search radii are rounded up to a multiple of it
+var PlacesCacheRadiusBucket int
+var RedisUrl string
+
+// PlaceTypesFile is a JSON place type taxonomy to use instead of the one shipped with the app
+var PlaceTypesFile string
+
+// PlacesSearchConcurrency limits how many place searches run at the same time, across all groups
+var PlacesSearchConcurrency int
+
+// PlacesSearchTimeout is how long a single place search can take
+var PlacesSearchTimeout time.Duration
+
+// PlacesSearchMaxRadius in meters, searches which find fewer than PlacesSearchMinResults places are widened
+// by PlacesSearchRadiusGrowth at a time, up to it
+var PlacesSearchMaxRadius int
+var PlacesSearchRadiusGrowth float64
+var PlacesSearchMinResults int
+
+// PlacesResultsPerType is how many places are found for each place type, unless the group asks for another number
+var PlacesResultsPerType int
+
+// PlaceDetailsCacheTTL is how long the details of a place are kept in memory, for up to PlaceDetailsCacheMaxEntries places
+var PlaceDetailsCacheTTL time.Duration
+var PlaceDetailsCacheMaxEntries int
+
+// PlaceSearchCacheTTL is how long the results of ad-hoc place searches are kept in memory, for up to PlaceSearchCacheMaxEntries searches
+var PlaceSearchCacheTTL time.Duration
+var PlaceSearchCacheMaxEntries int
+
+// GroupPlacesHistoryLimit is how many snapshots of its places each group keeps, older ones are dropped
+var GroupPlacesHistoryLimit int
+
+// PlaceScoreWeights decide how much each input counts towards the ranking score of group places
+var PlaceScoreWeights map[PlaceScoreInput]float64
+
+// PlacesDailyBudget limits the calls made to the places provider in a (UTC) day, across the app
+// PlacesGroupDailyBudget and PlacesUserDailyBudget limit them for each group and user, 0 is no limit
+var PlacesDailyBudget int64
+var PlacesGroupDailyBudget int64
+var PlacesUserDailyBudget int64
+
+// PlacesFallbackProvider is searched instead of the places provider once a budget is used up, empty for none
+var PlacesFallbackProvider string
+
+// Geocoder is one of "google", "gazetteer" or "none", it turns member addresses into locations
+// and labels group midpoints with their neighbourhood
+var Geocoder string
+
+// GazetteerFile is a GeoNames-style file of named places, for the gazetteer geocoder
+var GazetteerFile string
+
+// GazetteerMaxDistance in meters, midpoints further than it from every place of the gazetteer have no label
+var GazetteerMaxDistance int
+
+// AdminEmails are the users who can use the /admin endpoints
+var AdminEmails []string
+
 var GroupsQueryLimit int
 
+// GroupRefreshDelay is how long to wait for more joins and leaves before refreshing a group's midpoint and places
+// GroupRefreshMaxWait caps that wait when changes keep coming
+var GroupRefreshDelay time.Duration
+var GroupRefreshMaxWait time.Duration
+
+// JobWorkers is how many background jobs run at the same time
+var JobWorkers int
+var JobMaxAttempts int
+
+// JobRetryBackoff is the wait before the first retry of a failed job, it doubles with each attempt
+var JobRetryBackoff time.Duration
+
+// JobPollInterval is how often idle workers look for jobs which became due (e.g. retries)
+var JobPollInterval time.Duration
+
+// TravelTimeProvider is one of "straight_line", "road_graph" or "osrm"
+var TravelTimeProvider string
+var RoadGraphFile string
+var OSRMUrl string
+
+// OSRMProfiles is the OSRM profile each routed travel mode is routed with
+var OSRMProfiles map[TravelMode]string
+
+// RoutedTravelModes use the TravelTimeProvider, other modes use their speed model
+var RoutedTravelModes []TravelMode
+
+// MidpointOutlierThreshold is the modified z-score above which a member is treated as an outlier
+// set to 0 to disable outlier detection
+var MidpointOutlierThreshold float64
+
 // should run after env.go#init as this `vars` is alphabetically after `env`
 func init() {
 	Env, _ = lo.Coalesce(
@@ -42,8 +147,61 @@ func init() {
 
 	JWTSigningKey = os.Getenv("JWT_SIGNING_KEY")
 	JWTExpirationDays = lo.Must(strconv.Atoi(os.Getenv("JWT_EXPIRATION_DAYS")))
+	PasswordHashCost = lo.Must(strconv.Atoi(os.Getenv("PASSWORD_HASH_COST")))
 
 	GoogleMapsAPIKey = os.Getenv("GOOGLE_MAPS_API_KEY")
+	PlacesProvider = os.Getenv("PLACES_PROVIDER")
+	OSMPlacesFile = os.Getenv("OSM_PLACES_FILE")
+
+	PlacesCache = os.Getenv("PLACES_CACHE")
+	PlacesCacheTTL = lo.Must(time.ParseDuration(os.Getenv("PLACES_CACHE_TTL")))
+	PlacesCacheMaxEntries = lo.Must(strconv.Atoi(os.Getenv("PLACES_CACHE_MAX_ENTRIES")))
+	PlacesCacheGeohashPrecision = lo.Must(strconv.Atoi(os.Getenv("PLACES_CACHE_GEOHASH_PRECISION")))
+	PlacesCacheRadiusBucket = lo.Must(strconv.Atoi(os.Getenv("PLACES_CACHE_RADIUS_BUCKET")))
+	RedisUrl = os.Getenv("REDIS_URL")
+	PlaceTypesFile = os.Getenv("PLACE_TYPES_FILE")
+	SetPlaceTypes(lo.Must(LoadPlaceTypes(PlaceTypesFile)))
+	PlacesSearchConcurrency = lo.Must(strconv.Atoi(os.Getenv("PLACES_SEARCH_CONCURRENCY")))
+	PlacesSearchTimeout = lo.Must(time.ParseDuration(os.Getenv("PLACES_SEARCH_TIMEOUT")))
+	PlacesSearchMaxRadius = lo.Must(strconv.Atoi(os.Getenv("PLACES_SEARCH_MAX_RADIUS")))
+	PlacesSearchRadiusGrowth = lo.Must(strconv.ParseFloat(os.Getenv("PLACES_SEARCH_RADIUS_GROWTH"), 64))
+	PlacesSearchMinResults = lo.Must(strconv.Atoi(os.Getenv("PLACES_SEARCH_MIN_RESULTS")))
+	PlacesResultsPerType = lo.Must(strconv.Atoi(os.Getenv("PLACES_RESULTS_PER_TYPE")))
+	PlaceDetailsCacheTTL = lo.Must(time.ParseDuration(os.Getenv("PLACE_DETAILS_CACHE_TTL")))
+	PlaceDetailsCacheMaxEntries = lo.Must(strconv.Atoi(os.Getenv("PLACE_DETAILS_CACHE_MAX_ENTRIES")))
+	PlaceSearchCacheTTL = lo.Must(time.ParseDuration(os.Getenv("PLACE_SEARCH_CACHE_TTL")))
+	PlaceSearchCacheMaxEntries = lo.Must(strconv.Atoi(os.Getenv("PLACE_SEARCH_CACHE_MAX_ENTRIES")))
+	GroupPlacesHistoryLimit = lo.Must(strconv.Atoi(os.Getenv("GROUP_PLACES_HISTORY_LIMIT")))
+	PlaceScoreWeights = lo.Must(ParsePlaceScoreWeights(os.Getenv("PLACE_SCORE_WEIGHTS")))
+	PlacesDailyBudget = lo.Must(strconv.ParseInt(os.Getenv("PLACES_DAILY_BUDGET"), 10, 64))
+	PlacesGroupDailyBudget = lo.Must(strconv.ParseInt(os.Getenv("PLACES_GROUP_DAILY_BUDGET"), 10, 64))
+	PlacesUserDailyBudget = lo.Must(strconv.ParseInt(os.Getenv("PLACES_USER_DAILY_BUDGET"), 10, 64))
+	PlacesFallbackProvider = os.Getenv("PLACES_FALLBACK_PROVIDER")
+
+	Geocoder = os.Getenv("GEOCODER")
+	GazetteerFile = os.Getenv("GAZETTEER_FILE")
+	GazetteerMaxDistance = lo.Must(strconv.Atoi(os.Getenv("GAZETTEER_MAX_DISTANCE")))
+
+	AdminEmails = lo.Compact(lo.Map(strings.Split(os.Getenv("ADMIN_EMAILS"), ","), func(email string, _ int) string {
+		return strings.TrimSpace(email)
+	}))
 
 	GroupsQueryLimit = lo.Must(strconv.Atoi(os.Getenv("GROUPS_QUERY_LIMIT")))
+	GroupRefreshDelay = lo.Must(time.ParseDuration(os.Getenv("GROUP_REFRESH_DELAY")))
+	GroupRefreshMaxWait = lo.Must(time.ParseDuration(os.Getenv("GROUP_REFRESH_MAX_WAIT")))
+
+	JobWorkers = lo.Must(strconv.Atoi(os.Getenv("JOB_WORKERS")))
+	JobMaxAttempts = lo.Must(strconv.Atoi(os.Getenv("JOB_MAX_ATTEMPTS")))
+	JobRetryBackoff = lo.Must(time.ParseDuration(os.Getenv("JOB_RETRY_BACKOFF")))
+	JobPollInterval = lo.Must(time.ParseDuration(os.Getenv("JOB_POLL_INTERVAL")))
+
+	TravelTimeProvider = os.Getenv("TRAVEL_TIME_PROVIDER")
+	RoadGraphFile = os.Getenv("ROAD_GRAPH_FILE")
+	OSRMUrl = os.Getenv("OSRM_URL")
+	OSRMProfiles = lo.Must(ParseOSRMProfiles(os.Getenv("OSRM_PROFILES")))
+	RoutedTravelModes = lo.Map(strings.Split(os.Getenv("ROUTED_TRAVEL_MODES"), ","), func(mode string, _ int) TravelMode {
+		return TravelMode(strings.TrimSpace(mode))
+	})
+
+	MidpointOutlierThreshold = lo.Must(strconv.ParseFloat(os.Getenv("MIDPOINT_OUTLIER_THRESHOLD"), 64))
 }
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"sort"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+)
+
+// AddScoresToPlaces fills in the ranking score of each place, and sorts them by score if asked to
+// Places are scored against the midpoint and members of the cluster they are listed for (0 for the whole group),
+// with the weights in config.PlaceScoreWeights. Distances in the breakdown are masked like the members' locations. Their votes must be filled in already.
+// Places with the same score stay in their current order
+func (c *GroupPlacesController) AddScoresToPlaces(groupID string, cluster int, places []dto.GroupPlaceResponse, placesSort config.PlacesSort) ([]dto.GroupPlaceResponse, error) {
+	var group models.Group
+	if err := c.db.First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+	midpoint := dto.Location{Latitude: group.MidpointLatitude, Longitude: group.MidpointLongitude}
+	membersQuery := c.db.Where("group_id = ?", groupID)
+	if cluster != 0 {
+		var groupCluster models.GroupCluster
+		if err := c.db.Where("group_id = ? AND number = ?", groupID, cluster).First(&groupCluster).Error; err != nil {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Cluster not found")
+		}
+		midpoint = dto.Location{Latitude: groupCluster.MidpointLatitude, Longitude: groupCluster.MidpointLongitude}
+		membersQuery = membersQuery.Where("cluster = ?", cluster)
+	}
+	var members []models.GroupUser
+	if err := membersQuery.Find(&members).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group members")
+	}
+
+	candidates := lo.Map(places, func(place dto.GroupPlaceResponse, _ int) services.PlaceScoreCandidate {
+		candidate := services.PlaceScoreCandidate{
+			Location:    dto.Location{Latitude: place.Latitude, Longitude: place.Longitude},
+			Rating:      place.Rating,
+			ReviewCount: place.ReviewCount,
+		}
+		if place.Votes != nil {
+			candidate.VoteScore = place.Votes.Score
+		}
+		return candidate
+	})
+	memberLocations := lo.Map(members, func(member models.GroupUser, _ int) dto.Location {
+		return dto.Location{Latitude: member.Latitude, Longitude: member.Longitude}
+	})
+	scores := services.ScorePlaces(candidates, midpoint, memberLocations, membersLocationPrivacy(members), config.PlaceScoreWeights)
+	for i := range places {
+		places[i].Score = &scores[i]
+	}
+
+	if placesSort == config.PlacesSortScore {
+		sort.SliceStable(places, func(a, b int) bool {
+			return places[a].Score.Score > places[b].Score.Score
+		})
+	}
+	return places, nil
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"sort"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// VoteForGroupPlace sets the member's upvote or downvote on one of the group's places, none takes it back
+// Returns the place's tally after the vote
+func (c *GroupPlacesController) VoteForGroupPlace(groupID string, userID uint, placeID string, vote config.PlaceVote) (*dto.GroupPlaceVotes, error) {
+	var placeCount int64
+	if err := c.db.Model(&models.GroupPlace{}).Where("group_id = ? AND place_id = ?", groupID, placeID).Count(&placeCount).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group places")
+	}
+	if placeCount == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Place not found in group")
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var placeVote models.GroupPlaceVote
+		if err := tx.Where("group_id = ? AND place_id = ? AND user_id = ?", groupID, placeID, userID).
+			Attrs(models.GroupPlaceVote{GroupID: groupID, PlaceID: placeID, UserID: userID, Vote: config.PlaceVoteNone}).
+			FirstOrCreate(&placeVote).Error; err != nil {
+			applogger.Error("Failed to save vote", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to save vote")
+		}
+		placeVote.Vote = vote
+		return savePlaceVote(tx, &placeVote)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tallies, err := c.GetGroupPlaceVotes(groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return tallies[placeID], nil
+}
+
+// RankGroupPlaces replaces the member's ranked ballot with the places in order of preference
+// Every place must be one of the group's places right now
+func (c *GroupPlacesController) RankGroupPlaces(groupID string, userID uint, placeIDs []string) error {
+	placeIDs = lo.Uniq(placeIDs)
+	if len(placeIDs) > 0 {
+		var placeCount int64
+		if err := c.db.Model(&models.GroupPlace{}).Where("group_id = ? AND place_id IN ?", groupID, placeIDs).Count(&placeCount).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group places")
+		}
+		if int(placeCount) != len(placeIDs) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Only places of the group can be ranked")
+		}
+	}
+
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		var placeVotes []models.GroupPlaceVote
+		if err := tx.Where("group_id = ? AND user_id = ?", groupID, userID).Find(&placeVotes).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch votes")
+		}
+		votesByPlace := lo.SliceToMap(placeVotes, func(placeVote models.GroupPlaceVote) (string, *models.GroupPlaceVote) {
+			return placeVote.PlaceID, &placeVote
+		})
+
+		// places dropped from the ballot lose their rank
+		for _, placeVote := range votesByPlace {
+			if placeVote.Rank != 0 && !lo.Contains(placeIDs, placeVote.PlaceID) {
+				placeVote.Rank = 0
+				if err := savePlaceVote(tx, placeVote); err != nil {
+					return err
+				}
+			}
+		}
+		for i, placeID := range placeIDs {
+			placeVote, ok := votesByPlace[placeID]
+			if !ok {
+				placeVote = &models.GroupPlaceVote{GroupID: groupID, PlaceID: placeID, UserID: userID, Vote: config.PlaceVoteNone}
+			}
+			placeVote.Rank = i + 1
+			if err := savePlaceVote(tx, placeVote); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// savePlaceVote saves the vote, or deletes it if it has neither a vote nor a rank any more
+func savePlaceVote(tx *gorm.DB, placeVote *models.GroupPlaceVote) error {
+	if placeVote.Vote == config.PlaceVoteNone && placeVote.Rank == 0 {
+		if placeVote.ID == 0 {
+			return nil
+		}
+		if err := tx.Unscoped().Delete(placeVote).Error; err != nil {
+			applogger.Error("Failed to remove vote", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to remove vote")
+		}
+		return nil
+	}
+	if err := tx.Save(placeVote).Error; err != nil {
+		applogger.Error("Failed to save vote", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save vote")
+	}
+	return nil
+}
+
+// GetGroupPlaceVotes tallies the votes of current members on each of the group's places, by PlaceID
+// MyVote and MyRank are those of the given user
+func (c *GroupPlacesController) GetGroupPlaceVotes(groupID string, userID uint) (map[string]*dto.GroupPlaceVotes, error) {
+	var placeIDs []string
+	if err := c.db.Model(&models.GroupPlace{}).Where("group_id = ?", groupID).Pluck("place_id", &placeIDs).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group places")
+	}
+
+	var placeVotes []models.GroupPlaceVote
+	if err := c.db.Where("group_id = ? AND place_id IN ?", groupID, placeIDs).
+		Where("user_id IN (?)", c.db.Model(&models.GroupUser{}).Select("user_id").Where("group_id = ?", groupID)).
+		Order("user_id, rank").
+		Find(&placeVotes).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch votes")
+	}
+
+	tallies := make(map[string]*dto.GroupPlaceVotes, len(placeIDs))
+	for _, placeID := range placeIDs {
+		tallies[placeID] = &dto.GroupPlaceVotes{}
+	}
+	ballots := map[uint][]string{}
+	for _, placeVote := range placeVotes {
+		tally := tallies[placeVote.PlaceID]
+		switch placeVote.Vote {
+		case config.PlaceVoteUp:
+			tally.Upvotes++
+		case config.PlaceVoteDown:
+			tally.Downvotes++
+		}
+		if placeVote.Rank > 0 {
+			ballots[placeVote.UserID] = append(ballots[placeVote.UserID], placeVote.PlaceID)
+		}
+		if placeVote.UserID == userID {
+			tally.MyVote = placeVote.Vote
+			tally.MyRank = placeVote.Rank
+		}
+	}
+
+	for placeID, points := range services.BordaCount(lo.Values(ballots), placeIDs) {
+		tallies[placeID].BordaPoints = points
+		tallies[placeID].Score = points + tallies[placeID].Upvotes - tallies[placeID].Downvotes
+	}
+	return tallies, nil
+}
+
+// AddVotesToPlaces fills in the tally of each place, and sorts them by score if asked to
+// Places with the same score stay in their current order
+func (c *GroupPlacesController) AddVotesToPlaces(groupID string, userID uint, places []dto.GroupPlaceResponse, placesSort config.PlacesSort) ([]dto.GroupPlaceResponse, error) {
+	tallies, err := c.GetGroupPlaceVotes(groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range places {
+		places[i].Votes = tallies[places[i].PlaceID]
+	}
+	if placesSort == config.PlacesSortVotes {
+		sort.SliceStable(places, func(a, b int) bool {
+			return places[a].Votes.Score > places[b].Votes.Score
+		})
+	}
+	return places, nil
+}
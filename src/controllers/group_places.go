@@ -7,6 +7,7 @@ import (
 	"github.com/championswimmer/api.midpoint.place/src/dto"
 	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -21,50 +22,99 @@ func CreateGroupPlacesController() *GroupPlacesController {
 	}
 }
 
-// AddPlacesToGroup adds an array of places to a group
-// If a place with the same PlaceID already exists for this group, it will be ignored
-func (c *GroupPlacesController) AddPlacesToGroup(groupID string, req *dto.GroupPlacesAddRequest) ([]dto.GroupPlaceResponse, error) {
+// ReplaceGroupPlaces swaps all places of a group for new ones (of each cluster) in a single transaction,
+// so the group is never seen without places while they are being refreshed
+// Custom places added by members are kept, and the radius the new places were found in is saved along with them
+// The new places are also saved in a snapshot, with the group's midpoint, as its places history
+func (c *GroupPlacesController) ReplaceGroupPlaces(groupID string, reqs []dto.GroupPlacesAddRequest) error {
 	// Check if group exists
 	var group models.Group
 	if err := c.db.First(&group, "id = ?", groupID).Error; err != nil {
-		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
+		return fiber.NewError(fiber.StatusNotFound, "Group not found")
 	}
 
-	// Create transaction to ensure atomicity
-	var responses []dto.GroupPlaceResponse
-	err := c.db.Transaction(func(tx *gorm.DB) error {
-		// First, check for existing places (even if they are deleted) to avoid duplicates
-		var existingPlaceIDs []string
-		if err := tx.Unscoped().Model(&models.GroupPlace{}).
-			Where("group_id = ?", groupID).
-			Pluck("place_id", &existingPlaceIDs).Error; err != nil {
-			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch existing places")
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ? AND source = ?", groupID, config.PlaceSourceProvider).Delete(&models.GroupPlace{}).Error; err != nil {
+			applogger.Error("Failed to remove places from group", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to remove places from group")
+		}
+		if err := addPlacesToGroup(tx, groupID, reqs); err != nil {
+			return err
+		}
+		if err := savePlacesRadius(tx, groupID, reqs); err != nil {
+			return err
 		}
+		return saveGroupPlacesSnapshot(tx, groupID, reqs, 0)
+	})
+}
 
-		// Create a map for O(1) lookups
-		existingPlaceMap := make(map[string]bool)
-		for _, id := range existingPlaceIDs {
-			existingPlaceMap[id] = true
+// savePlacesRadius records the widest radius the places of the group (cluster 0) and of each cluster were found in
+func savePlacesRadius(tx *gorm.DB, groupID string, reqs []dto.GroupPlacesAddRequest) error {
+	radiusByCluster := map[int]int{0: 0}
+	for _, req := range reqs {
+		radiusByCluster[req.Cluster] = max(radiusByCluster[req.Cluster], req.Radius)
+	}
+	for cluster, radius := range radiusByCluster {
+		var err error
+		if cluster == 0 {
+			err = tx.Model(&models.Group{}).Where("id = ?", groupID).Update("places_radius", radius).Error
+		} else {
+			err = tx.Model(&models.GroupCluster{}).Where("group_id = ? AND number = ?", groupID, cluster).Update("places_radius", radius).Error
+		}
+		if err != nil {
+			applogger.Error("Failed to save places radius of group", groupID, "cluster", cluster, err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to save places radius")
 		}
+	}
+	return nil
+}
+
+// addPlacesToGroup creates the places, or undeletes them if the group had them before
+// A place in more than one request is only added for the first one
+func addPlacesToGroup(tx *gorm.DB, groupID string, reqs []dto.GroupPlacesAddRequest) error {
+	// First, check for existing places (even if they are deleted) to avoid duplicates
+	var existingPlaceIDs []string
+	if err := tx.Unscoped().Model(&models.GroupPlace{}).
+		Where("group_id = ?", groupID).
+		Pluck("place_id", &existingPlaceIDs).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch existing places")
+	}
+
+	// Create a map for O(1) lookups
+	existingPlaceMap := make(map[string]bool)
+	for _, id := range existingPlaceIDs {
+		existingPlaceMap[id] = true
+	}
+	seenPlaceMap := make(map[string]bool)
+
+	for _, req := range reqs {
 		var newPlaces []models.GroupPlace
-		var updatedPlaceIds []string
+		var updatedPlaces []dto.Place
 
 		for _, place := range req.Places {
+			if seenPlaceMap[place.Id] {
+				continue
+			}
+			seenPlaceMap[place.Id] = true
+
 			if existingPlaceMap[place.Id] {
 				applogger.Warn("Place", place.Id, "already exists for group", groupID, "- will try to undelete")
-				updatedPlaceIds = append(updatedPlaceIds, place.Id)
+				updatedPlaces = append(updatedPlaces, place)
 			} else {
 				applogger.Info("Place", place.Id, "does not exist for group", groupID, "- creating")
 				newPlaces = append(newPlaces, models.GroupPlace{
-					GroupID:   groupID,
-					PlaceID:   place.Id,
-					Name:      place.Name,
-					Address:   place.Address,
-					Type:      config.PlaceType(place.Type),
-					Rating:    place.Rating,
-					MapURI:    place.MapURI,
-					Latitude:  place.Latitude,
-					Longitude: place.Longitude,
+					GroupID:     groupID,
+					PlaceID:     place.Id,
+					Name:        place.Name,
+					Address:     place.Address,
+					Type:        config.PlaceType(place.Type),
+					Rating:      place.Rating,
+					MapURI:      place.MapURI,
+					Latitude:    place.Latitude,
+					Longitude:   place.Longitude,
+					Cluster:     req.Cluster,
+					Source:      config.PlaceSourceProvider,
+					ReviewCount: place.ReviewCount,
 				})
 			}
 		}
@@ -77,44 +127,35 @@ func (c *GroupPlacesController) AddPlacesToGroup(groupID string, req *dto.GroupP
 			}
 		}
 
-		// For existing places, do a batch update to undelete them
-		if len(updatedPlaceIds) > 0 {
+		// For existing places, undelete them with what the provider says about them now, as their ratings
+		// and review counts (which places are scored on) may have changed since
+		// places which are already there (e.g. found around another cluster's midpoint) are left alone
+		for _, place := range updatedPlaces {
 			if err := tx.Unscoped().Model(&models.GroupPlace{}).
-				Where("group_id = ? AND place_id IN (?)", groupID, updatedPlaceIds).
-				Update("deleted_at", nil).Error; err != nil {
+				Where("group_id = ? AND place_id = ? AND deleted_at IS NOT NULL", groupID, place.Id).
+				Updates(map[string]any{
+					"deleted_at":   nil,
+					"cluster":      req.Cluster,
+					"name":         place.Name,
+					"address":      place.Address,
+					"type":         config.PlaceType(place.Type),
+					"rating":       place.Rating,
+					"review_count": place.ReviewCount,
+					"map_uri":      place.MapURI,
+					"latitude":     place.Latitude,
+					"longitude":    place.Longitude,
+				}).Error; err != nil {
 				applogger.Error("Failed to undelete places", err)
 				return fiber.NewError(fiber.StatusInternalServerError, "Failed to undelete places")
 			}
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return responses, nil
-}
-
-// RemoveAllPlacesFromGroup removes all places from a group
-func (c *GroupPlacesController) RemoveAllPlacesFromGroup(groupID string) error {
-	// Check if group exists
-	var group models.Group
-	if err := c.db.First(&group, "id = ?", groupID).Error; err != nil {
-		return fiber.NewError(fiber.StatusNotFound, "Group not found")
-	}
-
-	// Delete all places associated with the group
-	if err := c.db.Where("group_id = ?", groupID).Delete(&models.GroupPlace{}).Error; err != nil {
-		applogger.Error("Failed to remove places from group", err)
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to remove places from group")
 	}
 
 	return nil
 }
 
-// GetGroupPlaces retrieves all places for a group
+// GetGroupPlaces retrieves all places for a group, with their votes and scores, the best scored first
+// They are all scored against the group midpoint and all its members, whichever cluster they were found for
 func (c *GroupPlacesController) GetGroupPlaces(groupID string) ([]dto.GroupPlaceResponse, error) {
 	// Check if group exists
 	var group models.Group
@@ -130,18 +171,96 @@ func (c *GroupPlacesController) GetGroupPlaces(groupID string) ([]dto.GroupPlace
 	responses := make([]dto.GroupPlaceResponse, len(groupPlaces))
 	for i, place := range groupPlaces {
 		responses[i] = dto.GroupPlaceResponse{
-			ID:        place.ID.String(),
-			GroupID:   place.GroupID,
-			PlaceID:   place.PlaceID,
-			Name:      place.Name,
-			Address:   place.Address,
-			Type:      config.PlaceType(place.Type),
-			Rating:    place.Rating,
-			MapURI:    place.MapURI,
-			Latitude:  place.Latitude,
-			Longitude: place.Longitude,
+			ID:          place.ID.String(),
+			GroupID:     place.GroupID,
+			PlaceID:     place.PlaceID,
+			Name:        place.Name,
+			Address:     place.Address,
+			Type:        config.PlaceType(place.Type),
+			Rating:      place.Rating,
+			MapURI:      place.MapURI,
+			Latitude:    place.Latitude,
+			Longitude:   place.Longitude,
+			Source:      place.Source,
+			AddedBy:     place.AddedByID,
+			ReviewCount: place.ReviewCount,
 		}
 	}
 
-	return responses, nil
+	tallies, err := c.GetGroupPlaceVotes(groupID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range responses {
+		responses[i].Votes = tallies[responses[i].PlaceID]
+	}
+	return c.AddScoresToPlaces(groupID, 0, responses, config.PlacesSortScore)
+}
+
+// GetGroupPlace retrieves one place of a group by its PlaceID
+func (c *GroupPlacesController) GetGroupPlace(groupID string, placeID string) (*dto.GroupPlaceResponse, error) {
+	var place models.GroupPlace
+	if err := c.db.Where("group_id = ? AND place_id = ?", groupID, placeID).First(&place).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Place not found in group")
+	}
+
+	response := toGroupPlaceResponses([]models.GroupPlace{place})[0]
+	response.ID = place.ID.String()
+	return &response, nil
+}
+
+// AddCustomPlace adds a member's own place to the group, next to the ones found by the places provider
+// It gets a PlaceID of its own, and is kept when the group is refreshed
+func (c *GroupPlacesController) AddCustomPlace(groupID string, userID uint, req *dto.CustomPlaceAddRequest) (*dto.GroupPlaceResponse, error) {
+	var group models.Group
+	if err := c.db.First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+
+	place := models.GroupPlace{
+		GroupID:   groupID,
+		PlaceID:   config.CustomPlaceIDPrefix + uuid.NewString(),
+		Name:      req.Name,
+		Address:   req.Address,
+		Type:      req.Type,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Source:    config.PlaceSourceCustom,
+		AddedByID: userID,
+	}
+	if err := c.db.Create(&place).Error; err != nil {
+		applogger.Error("Failed to add custom place to group", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to add custom place to group")
+	}
+
+	return &dto.GroupPlaceResponse{
+		ID:        place.ID.String(),
+		GroupID:   place.GroupID,
+		PlaceID:   place.PlaceID,
+		Name:      place.Name,
+		Address:   place.Address,
+		Type:      place.Type,
+		Latitude:  place.Latitude,
+		Longitude: place.Longitude,
+		Source:    place.Source,
+		AddedBy:   place.AddedByID,
+	}, nil
+}
+
+// RemoveCustomPlace removes a custom place from the group
+// Only the member who added it can remove it, unless canRemoveAny is set (for group admins)
+func (c *GroupPlacesController) RemoveCustomPlace(groupID string, userID uint, placeID string, canRemoveAny bool) error {
+	var place models.GroupPlace
+	if err := c.db.Where("group_id = ? AND place_id = ? AND source = ?", groupID, placeID, config.PlaceSourceCustom).First(&place).Error; err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Custom place not found in group")
+	}
+	if place.AddedByID != userID && !canRemoveAny {
+		return fiber.NewError(fiber.StatusForbidden, "Only the member who added a place or group admins can remove it")
+	}
+
+	if err := c.db.Delete(&place).Error; err != nil {
+		applogger.Error("Failed to remove custom place from group", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to remove custom place from group")
+	}
+	return nil
 }
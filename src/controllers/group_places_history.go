@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// ListGroupPlacesSnapshots returns the snapshots of a group's places, newest first, without their places
+func (c *GroupPlacesController) ListGroupPlacesSnapshots(groupID string) ([]dto.GroupPlacesSnapshotResponse, error) {
+	var snapshots []models.GroupPlacesSnapshot
+	if err := c.db.Where("group_id = ?", groupID).Order("id DESC").Find(&snapshots).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch places history")
+	}
+	return lo.Map(snapshots, func(snapshot models.GroupPlacesSnapshot, _ int) dto.GroupPlacesSnapshotResponse {
+		return toGroupPlacesSnapshotResponse(&snapshot, false)
+	}), nil
+}
+
+// GetGroupPlacesSnapshot returns one snapshot of a group's places, with its places
+func (c *GroupPlacesController) GetGroupPlacesSnapshot(groupID string, snapshotID uint) (*dto.GroupPlacesSnapshotResponse, error) {
+	var snapshot models.GroupPlacesSnapshot
+	if err := c.db.Where("group_id = ? AND id = ?", groupID, snapshotID).First(&snapshot).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Snapshot not found")
+	}
+	response := toGroupPlacesSnapshotResponse(&snapshot, true)
+	return &response, nil
+}
+
+// RestoreGroupPlacesSnapshot brings back the midpoint and places of a snapshot, in place of the group's current ones
+// Clusters which still exist get their midpoints back along with their places, places found around a cluster which
+// no longer exists are restored around the group midpoint. Custom places are kept.
+// The restore is saved as a snapshot of its own, and lasts until the group is refreshed again.
+func (c *GroupPlacesController) RestoreGroupPlacesSnapshot(groupID string, snapshotID uint) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if err := tx.First(&group, "id = ?", groupID).Error; err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "Group not found")
+		}
+		if group.Venue != nil {
+			return fiber.NewError(fiber.StatusConflict, "Places can't be restored while a venue is chosen")
+		}
+		var snapshot models.GroupPlacesSnapshot
+		if err := tx.Where("group_id = ? AND id = ?", groupID, snapshotID).First(&snapshot).Error; err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "Snapshot not found")
+		}
+		var clusterNumbers []int
+		if err := tx.Model(&models.GroupCluster{}).Where("group_id = ?", groupID).Pluck("number", &clusterNumbers).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group clusters")
+		}
+
+		// places of missing clusters fall back to the request of the group midpoint, at index 0
+		reqs := []dto.GroupPlacesAddRequest{{Cluster: 0, Radius: snapshot.PlacesRadius}}
+		reqIndexes := map[int]int{}
+		for _, cluster := range snapshot.Clusters {
+			if lo.Contains(clusterNumbers, cluster.Number) {
+				reqIndexes[cluster.Number] = len(reqs)
+				reqs = append(reqs, dto.GroupPlacesAddRequest{Cluster: cluster.Number, Radius: cluster.PlacesRadius})
+			}
+		}
+		for _, place := range snapshot.Places {
+			i := reqIndexes[place.Cluster]
+			reqs[i].Places = append(reqs[i].Places, dto.Place{
+				Location:    dto.Location{Latitude: place.Latitude, Longitude: place.Longitude},
+				Id:          place.PlaceID,
+				Name:        place.Name,
+				Address:     place.Address,
+				Type:        place.Type,
+				Rating:      place.Rating,
+				MapURI:      place.MapURI,
+				ReviewCount: place.ReviewCount,
+			})
+		}
+
+		applogger.Info("Restoring places of group", groupID, "from snapshot", snapshotID)
+		if err := tx.Where("group_id = ? AND source = ?", groupID, config.PlaceSourceProvider).Delete(&models.GroupPlace{}).Error; err != nil {
+			applogger.Error("Failed to remove places from group", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to remove places from group")
+		}
+		if err := addPlacesToGroup(tx, groupID, reqs); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Group{}).Where("id = ?", groupID).Updates(map[string]any{
+			"midpoint_latitude":  snapshot.MidpointLatitude,
+			"midpoint_longitude": snapshot.MidpointLongitude,
+			"midpoint_label":     snapshot.MidpointLabel,
+		}).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to restore group midpoint")
+		}
+		for _, cluster := range snapshot.Clusters {
+			if !lo.Contains(clusterNumbers, cluster.Number) {
+				continue
+			}
+			if err := tx.Model(&models.GroupCluster{}).Where("group_id = ? AND number = ?", groupID, cluster.Number).Updates(map[string]any{
+				"midpoint_latitude":  cluster.MidpointLatitude,
+				"midpoint_longitude": cluster.MidpointLongitude,
+			}).Error; err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to restore cluster midpoints")
+			}
+		}
+		if err := savePlacesRadius(tx, groupID, reqs); err != nil {
+			return err
+		}
+		return saveGroupPlacesSnapshot(tx, groupID, reqs, snapshot.ID)
+	})
+}
+
+// saveGroupPlacesSnapshot saves the group's midpoint and clusters as they are now, along with the places,
+// and drops the group's oldest snapshots beyond config.GroupPlacesHistoryLimit
+func saveGroupPlacesSnapshot(tx *gorm.DB, groupID string, reqs []dto.GroupPlacesAddRequest, restoredFromID uint) error {
+	var group models.Group
+	if err := tx.Preload("Clusters").Preload("Members").First(&group, "id = ?", groupID).Error; err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+
+	snapshot := models.GroupPlacesSnapshot{
+		GroupID:           groupID,
+		MidpointLatitude:  group.MidpointLatitude,
+		MidpointLongitude: group.MidpointLongitude,
+		MidpointLabel:     group.MidpointLabel,
+		MidpointStrategy:  group.MidpointStrategy,
+		MidpointPrivacy:   membersLocationPrivacy(group.Members),
+		Radius:            group.Radius,
+		PlacesRadius:      group.PlacesRadius,
+		RestoredFromID:    restoredFromID,
+		Clusters: lo.Map(group.Clusters, func(cluster models.GroupCluster, _ int) models.GroupPlacesSnapshotCluster {
+			return models.GroupPlacesSnapshotCluster{
+				Number:            cluster.Number,
+				MidpointLatitude:  cluster.MidpointLatitude,
+				MidpointLongitude: cluster.MidpointLongitude,
+				PlacesRadius:      cluster.PlacesRadius,
+				LocationPrivacy: membersLocationPrivacy(lo.Filter(group.Members, func(member models.GroupUser, _ int) bool {
+					return member.Cluster == cluster.Number
+				})),
+			}
+		}),
+		Places: []models.GroupPlacesSnapshotPlace{},
+	}
+	// like addPlacesToGroup, a place in more than one request only counts for the first one
+	seenPlaceMap := make(map[string]bool)
+	for _, req := range reqs {
+		for _, place := range req.Places {
+			if seenPlaceMap[place.Id] {
+				continue
+			}
+			seenPlaceMap[place.Id] = true
+			snapshot.Places = append(snapshot.Places, models.GroupPlacesSnapshotPlace{
+				PlaceID:     place.Id,
+				Name:        place.Name,
+				Address:     place.Address,
+				Type:        place.Type,
+				Rating:      place.Rating,
+				ReviewCount: place.ReviewCount,
+				MapURI:      place.MapURI,
+				Latitude:    place.Latitude,
+				Longitude:   place.Longitude,
+				Cluster:     req.Cluster,
+			})
+		}
+	}
+	if err := tx.Create(&snapshot).Error; err != nil {
+		applogger.Error("Failed to save places snapshot of group", groupID, err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save places history")
+	}
+
+	latest := tx.Model(&models.GroupPlacesSnapshot{}).Select("id").Where("group_id = ?", groupID).Order("id DESC").Limit(config.GroupPlacesHistoryLimit)
+	if err := tx.Unscoped().Where("group_id = ? AND id NOT IN (?)", groupID, latest).Delete(&models.GroupPlacesSnapshot{}).Error; err != nil {
+		applogger.Error("Failed to drop old places snapshots of group", groupID, err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save places history")
+	}
+	return nil
+}
+
+func toGroupPlacesSnapshotResponse(snapshot *models.GroupPlacesSnapshot, includePlaces bool) dto.GroupPlacesSnapshotResponse {
+	// like the clusters' midpoints below, the group's is kept exactly but shown like it was
+	midpoint := services.MaskLocation(
+		dto.Location{Latitude: snapshot.MidpointLatitude, Longitude: snapshot.MidpointLongitude},
+		snapshot.MidpointPrivacy,
+	)
+	response := dto.GroupPlacesSnapshotResponse{
+		ID:                snapshot.ID,
+		CreatedAt:         snapshot.CreatedAt,
+		MidpointLatitude:  midpoint.Latitude,
+		MidpointLongitude: midpoint.Longitude,
+		MidpointLabel:     lo.Ternary(snapshot.MidpointPrivacy == config.LocationPrivacyHidden, "", snapshot.MidpointLabel),
+		MidpointStrategy:  snapshot.MidpointStrategy,
+		Radius:            snapshot.Radius,
+		PlacesRadius:      snapshot.PlacesRadius,
+		RestoredFrom:      snapshot.RestoredFromID,
+		PlaceCount:        len(snapshot.Places),
+		Clusters: lo.Map(snapshot.Clusters, func(cluster models.GroupPlacesSnapshotCluster, _ int) dto.GroupPlacesSnapshotCluster {
+			// the midpoint is kept exactly, to be restored, but shown like the cluster's midpoint was
+			midpoint := services.MaskLocation(
+				dto.Location{Latitude: cluster.MidpointLatitude, Longitude: cluster.MidpointLongitude},
+				cluster.LocationPrivacy,
+			)
+			return dto.GroupPlacesSnapshotCluster{
+				Number:            cluster.Number,
+				MidpointLatitude:  midpoint.Latitude,
+				MidpointLongitude: midpoint.Longitude,
+				PlacesRadius:      cluster.PlacesRadius,
+			}
+		}),
+	}
+	if includePlaces {
+		response.Places = lo.Map(snapshot.Places, func(place models.GroupPlacesSnapshotPlace, _ int) dto.GroupPlacesSnapshotPlace {
+			return dto.GroupPlacesSnapshotPlace(place)
+		})
+	}
+	return response
+}
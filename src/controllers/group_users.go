@@ -1,9 +1,15 @@
 package controllers
 
 import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
 	"github.com/championswimmer/api.midpoint.place/src/db"
 	"github.com/championswimmer/api.midpoint.place/src/db/models"
 	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/services"
 	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/samber/lo"
@@ -11,13 +17,15 @@ import (
 )
 
 type GroupUsersController struct {
-	db *gorm.DB
+	db                  *gorm.DB
+	travelTimeEstimator *services.TravelTimeEstimator
 }
 
 func CreateGroupUsersController() *GroupUsersController {
 	appDb := db.GetAppDB()
 	return &GroupUsersController{
-		db: appDb,
+		db:                  appDb,
+		travelTimeEstimator: services.GetTravelTimeEstimator(),
 	}
 }
 
@@ -37,28 +45,59 @@ func (c *GroupUsersController) JoinGroup(groupID string, userID uint, req *dto.G
 		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
 	}
 
+	travelMode := req.TravelMode
+	if travelMode == "" {
+		travelMode = config.DefaultTravelMode
+	}
+	locationPrivacy := req.LocationPrivacy
+	if locationPrivacy == "" {
+		locationPrivacy = config.DefaultLocationPrivacy
+	}
+
 	// Check if the user is already in the group and update their location if necessary
 	var groupUser models.GroupUser
 	err := c.db.Transaction(func(tx *gorm.DB) error {
 		applogger.Info("Joining group", groupID, "for user", userID, "transaction started")
-		if err := tx.Where("user_id = ? AND group_id = ?", userID, groupID).FirstOrCreate(&groupUser, models.GroupUser{
-			UserID:    userID,
-			GroupID:   groupID,
-			Latitude:  req.Latitude,
-			Longitude: req.Longitude,
-		}).Error; err != nil {
+		// Attrs are only used when creating, so an existing membership is found whatever its location
+		if err := tx.Where("user_id = ? AND group_id = ?", userID, groupID).Attrs(models.GroupUser{
+			UserID:          userID,
+			GroupID:         groupID,
+			Latitude:        req.Latitude,
+			Longitude:       req.Longitude,
+			TravelMode:      travelMode,
+			SavedLocation:   req.SavedLocation,
+			LocationPrivacy: locationPrivacy,
+		}).FirstOrCreate(&groupUser).Error; err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to add/update user in group")
 		}
 		groupUser.DeletedAt = gorm.DeletedAt{} // un-delete the user if it was deleted
 
 		// If the user is already in the group and the location has changed, update the location
+		changed := false
 		if groupUser.Latitude != req.Latitude || groupUser.Longitude != req.Longitude {
 			groupUser.Latitude = req.Latitude
 			groupUser.Longitude = req.Longitude
+			changed = true
+			applogger.Warn("User", userID, "is already in group", groupID, "- updating location")
+		}
+		// Joining from a location stops following a saved location
+		if groupUser.SavedLocation != req.SavedLocation {
+			groupUser.SavedLocation = req.SavedLocation
+			changed = true
+		}
+		// Only change the travel mode of an existing member if they asked for a new one
+		if req.TravelMode != "" && groupUser.TravelMode != req.TravelMode {
+			groupUser.TravelMode = req.TravelMode
+			changed = true
+		}
+		if req.LocationPrivacy != "" && groupUser.LocationPrivacy != req.LocationPrivacy {
+			groupUser.LocationPrivacy = req.LocationPrivacy
+			changed = true
+		}
+		if changed {
 			if err := tx.Save(&groupUser).Error; err != nil {
 				return fiber.NewError(fiber.StatusInternalServerError, "Failed to update user location in group")
 			}
-			applogger.Warn("User", userID, "is already in group", groupID, "- updating location")
 		}
 		applogger.Info("Joining group", groupID, "for user", userID, "transaction completed")
 		return nil
@@ -69,10 +108,13 @@ func (c *GroupUsersController) JoinGroup(groupID string, userID uint, req *dto.G
 	}
 
 	return &dto.GroupUserResponse{
-		UserID:    groupUser.UserID,
-		GroupID:   groupUser.GroupID,
-		Latitude:  groupUser.Latitude,
-		Longitude: groupUser.Longitude,
+		UserID:          groupUser.UserID,
+		GroupID:         groupUser.GroupID,
+		Latitude:        groupUser.Latitude,
+		Longitude:       groupUser.Longitude,
+		TravelMode:      groupUser.TravelMode,
+		SavedLocation:   groupUser.SavedLocation,
+		LocationPrivacy: groupUser.LocationPrivacy,
 	}, nil
 }
 
@@ -104,19 +146,232 @@ func (c *GroupUsersController) LeaveGroup(groupID string, userID uint) error {
 	return nil
 }
 
-func (c *GroupUsersController) CalculateGroupCentroid(groupID string) (latitude float64, longitude float64, err error) {
-	var result struct {
-		Latitude  float64
-		Longitude float64
+// CalculateGroupMidpoint calculates the midpoint of the group members' locations using the group's strategy
+// Members excluded by an admin, or detected as distance outliers, are left out of the calculation
+// and the reason is saved on their membership
+func (c *GroupUsersController) CalculateGroupMidpoint(ctx context.Context, groupID string) (latitude float64, longitude float64, err error) {
+	var group models.Group
+	if err := c.db.First(&group, "id = ?", groupID).Error; err != nil {
+		return 0, 0, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+
+	var groupUsers []models.GroupUser
+	if err := c.db.Where("group_id = ?", groupID).Find(&groupUsers).Error; err != nil {
+		return 0, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group members")
+	}
+
+	exclusions := midpointExclusions(groupUsers)
+	var travellers []services.Traveller
+	for i, groupUser := range groupUsers {
+		if exclusions[i] == config.MidpointExclusionNone {
+			travellers = append(travellers, toTraveller(groupUser))
+		}
+	}
+	if len(travellers) == 0 && len(groupUsers) > 0 {
+		applogger.Warn("All members of group", groupID, "are excluded from midpoint - using everyone instead")
+		for i, groupUser := range groupUsers {
+			exclusions[i] = config.MidpointExclusionNone
+			travellers = append(travellers, toTraveller(groupUser))
+		}
+	}
+
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		for i, groupUser := range groupUsers {
+			if groupUser.MidpointExclusion == exclusions[i] {
+				continue
+			}
+			if err := tx.Model(&models.GroupUser{}).
+				Where("user_id = ? AND group_id = ?", groupUser.UserID, groupID).
+				Update("midpoint_exclusion", exclusions[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		applogger.Error("Failed to save midpoint exclusions for group", groupID, err)
+		return 0, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to calculate group midpoint")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	midpoint, err := services.SelectMidpoint(ctx, c.travelTimeEstimator, travellers, group.MidpointStrategy)
+	if err != nil {
+		// SelectMidpoint still returns the centroid, which is better than nothing
+		applogger.Error("Failed to select", group.MidpointStrategy, "midpoint for group", groupID, "- using centroid", err)
+	}
+	return midpoint.Latitude, midpoint.Longitude, nil
+}
+
+// midpointExclusions works out which members should be left out of the midpoint, and why
+func midpointExclusions(groupUsers []models.GroupUser) []config.MidpointExclusionReason {
+	exclusions := make([]config.MidpointExclusionReason, len(groupUsers))
+
+	var candidates []int
+	for i, groupUser := range groupUsers {
+		if groupUser.MidpointInclusion == config.MidpointInclusionExcluded {
+			exclusions[i] = config.MidpointExclusionByAdmin
+		} else {
+			candidates = append(candidates, i)
+		}
+	}
+
+	locations := lo.Map(candidates, func(i int, _ int) dto.Location {
+		return dto.Location{Latitude: groupUsers[i].Latitude, Longitude: groupUsers[i].Longitude}
+	})
+	outliers := services.DetectLocationOutliers(locations, config.MidpointOutlierThreshold)
+	for j, i := range candidates {
+		if outliers[j] && groupUsers[i].MidpointInclusion != config.MidpointInclusionIncluded {
+			exclusions[i] = config.MidpointExclusionDistanceOutlier
+		}
+	}
+	return exclusions
+}
+
+// CalculateGroupClusters splits the group members into sub-clusters using the group's cluster method,
+// and picks a midpoint for each cluster with the group's midpoint strategy.
+// Members excluded by an admin are left out. The clusters replace any previous ones, and are returned
+// in order of their number. Groups which aren't clustered get no clusters.
+func (c *GroupUsersController) CalculateGroupClusters(ctx context.Context, groupID string) ([]models.GroupCluster, error) {
+	var group models.Group
+	if err := c.db.First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+
+	var groupUsers []models.GroupUser
+	if err := c.db.Where("group_id = ?", groupID).Find(&groupUsers).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group members")
 	}
+
+	candidates := lo.Filter(lo.Range(len(groupUsers)), func(i int, _ int) bool {
+		return groupUsers[i].MidpointInclusion != config.MidpointInclusionExcluded
+	})
+	locations := lo.Map(candidates, func(i int, _ int) dto.Location {
+		return dto.Location{Latitude: groupUsers[i].Latitude, Longitude: groupUsers[i].Longitude}
+	})
+
+	var labels []int
+	switch group.ClusterMethod {
+	case config.ClusterMethodKMeans:
+		labels = services.KMeansClusters(locations, group.ClusterCount)
+	case config.ClusterMethodDBSCAN:
+		labels = services.DBSCANClusters(locations, float64(group.ClusterRadius)/1000)
+	}
+
+	// cluster numbers start from 1, 0 means not in any cluster (like DBSCAN noise)
+	memberClusters := make([]int, len(groupUsers))
+	clusterTravellers := map[int][]services.Traveller{}
+	for j, label := range labels {
+		if label < 0 {
+			continue
+		}
+		memberClusters[candidates[j]] = label + 1
+		clusterTravellers[label+1] = append(clusterTravellers[label+1], toTraveller(groupUsers[candidates[j]]))
+	}
+	if len(clusterTravellers) > config.MaxGroupClusters {
+		// DBSCAN can find lots of small clusters, only the biggest ones get their own midpoint
+		numbers := lo.Keys(clusterTravellers)
+		sort.Slice(numbers, func(a, b int) bool {
+			if len(clusterTravellers[numbers[a]]) != len(clusterTravellers[numbers[b]]) {
+				return len(clusterTravellers[numbers[a]]) > len(clusterTravellers[numbers[b]])
+			}
+			return numbers[a] < numbers[b]
+		})
+		applogger.Warn("Group", groupID, "has", len(numbers), "clusters - keeping the biggest", config.MaxGroupClusters)
+		for _, number := range numbers[config.MaxGroupClusters:] {
+			delete(clusterTravellers, number)
+		}
+		for i, number := range memberClusters {
+			if _, ok := clusterTravellers[number]; !ok {
+				memberClusters[i] = 0
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	clusters := make([]models.GroupCluster, 0, len(clusterTravellers))
+	for number := 1; number <= len(labels); number++ {
+		travellers, ok := clusterTravellers[number]
+		if !ok {
+			continue
+		}
+		midpoint, err := services.SelectMidpoint(ctx, c.travelTimeEstimator, travellers, group.MidpointStrategy)
+		if err != nil {
+			applogger.Error("Failed to select", group.MidpointStrategy, "midpoint for cluster", number, "of group", groupID, "- using centroid", err)
+		}
+		clusters = append(clusters, models.GroupCluster{
+			GroupID:           groupID,
+			Number:            number,
+			MidpointLatitude:  midpoint.Latitude,
+			MidpointLongitude: midpoint.Longitude,
+		})
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("group_id = ?", groupID).Delete(&models.GroupCluster{}).Error; err != nil {
+			return err
+		}
+		if len(clusters) > 0 {
+			if err := tx.Create(&clusters).Error; err != nil {
+				return err
+			}
+		}
+		for i, groupUser := range groupUsers {
+			if groupUser.Cluster == memberClusters[i] {
+				continue
+			}
+			if err := tx.Model(&models.GroupUser{}).
+				Where("user_id = ? AND group_id = ?", groupUser.UserID, groupID).
+				Update("cluster", memberClusters[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		applogger.Error("Failed to save clusters for group", groupID, err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to calculate group clusters")
+	}
+	return clusters, nil
+}
+
+// UpdateGroupMember applies an admin's changes to a member of the group
+func (c *GroupUsersController) UpdateGroupMember(groupID string, userID uint, req *dto.GroupMemberUpdateRequest) (*dto.GroupUserResponse, error) {
+	var groupUser models.GroupUser
+	if err := c.db.Preload("User").Where("user_id = ? AND group_id = ?", userID, groupID).First(&groupUser).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "User is not a member of this group")
+	}
+
 	if err := c.db.Model(&models.GroupUser{}).
-		Select("AVG(latitude) as Latitude, AVG(longitude) as Longitude").
-		Where("group_id = ?", groupID).
-		Scan(&result).Error; err != nil {
-		return 0, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to calculate group centroid")
+		Where("user_id = ? AND group_id = ?", userID, groupID).
+		Update("midpoint_inclusion", req.MidpointInclusion).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update group member")
 	}
+	groupUser.MidpointInclusion = req.MidpointInclusion
 
-	return result.Latitude, result.Longitude, nil
+	response := toGroupUserResponse(groupUser)
+	return &response, nil
+}
+
+// IsGroupAdmin checks if a user is allowed to administer a group
+// The creator of the group is always an admin, other members are admins if they have the admin role
+func (c *GroupUsersController) IsGroupAdmin(groupID string, userID uint) (bool, error) {
+	var group models.Group
+	if err := c.db.First(&group, "id = ?", groupID).Error; err != nil {
+		return false, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+	if group.CreatorID == userID {
+		return true, nil
+	}
+
+	var adminCount int64
+	if err := c.db.Model(&models.GroupUser{}).
+		Where("user_id = ? AND group_id = ? AND role = ?", userID, groupID, config.GroupUserAdmin).
+		Count(&adminCount).Error; err != nil {
+		return false, fiber.NewError(fiber.StatusInternalServerError, "Failed to check group admin")
+	}
+	return adminCount > 0, nil
 }
 
 // GroupMembershipCheck checks if a user belongs to a specified group
@@ -146,15 +401,9 @@ func (c *GroupUsersController) GetGroupMembers(groupID string) ([]dto.GroupUserR
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group members")
 	}
 
-	response := make([]dto.GroupUserResponse, len(groupUsers))
-	for i, groupUser := range groupUsers {
-		response[i] = dto.GroupUserResponse{
-			UserID:    groupUser.UserID,
-			GroupID:   groupUser.GroupID,
-			Latitude:  groupUser.Latitude,
-			Longitude: groupUser.Longitude,
-		}
-	}
+	response := lo.Map(groupUsers, func(groupUser models.GroupUser, _ int) dto.GroupUserResponse {
+		return toGroupUserResponse(groupUser)
+	})
 
 	return response, nil
 }
@@ -174,27 +423,39 @@ func (c *GroupUsersController) GetGroupsContainingMember(userID uint) ([]dto.Gro
 	response := make([]dto.GroupResponse, len(groups))
 	for i, group := range groups {
 		members := lo.Map(group.Members, func(member models.GroupUser, _ int) dto.GroupUserResponse {
-			return dto.GroupUserResponse{
-				UserID:    member.UserID,
-				GroupID:   member.GroupID,
-				Latitude:  member.Latitude,
-				Longitude: member.Longitude,
-				Role:      member.Role,
-			}
+			return toGroupUserResponse(member)
 		})
 
-		response[i] = dto.GroupResponse{
-			ID:                group.ID,
-			Name:              group.Name,
-			Type:              group.Type,
-			Code:              group.Code,
-			Creator:           dto.GroupCreator{ID: group.Creator.ID, DisplayName: group.Creator.DisplayName},
-			MidpointLatitude:  group.MidpointLatitude,
-			MidpointLongitude: group.MidpointLongitude,
-			Radius:            group.Radius,
-			Members:           members,
-		}
+		response[i] = *toGroupResponse(&group, membersLocationPrivacy(group.Members))
+		response[i].Members = members
 	}
 
 	return response, nil
 }
+
+// toGroupUserResponse converts a membership into its response
+// DisplayName is only filled in if the User has been preloaded
+func toGroupUserResponse(member models.GroupUser) dto.GroupUserResponse {
+	return dto.GroupUserResponse{
+		UserID:               member.UserID,
+		GroupID:              member.GroupID,
+		DisplayName:          member.User.DisplayName,
+		Latitude:             member.Latitude,
+		Longitude:            member.Longitude,
+		Role:                 member.Role,
+		TravelMode:           member.TravelMode,
+		MidpointInclusion:    member.MidpointInclusion,
+		ExcludedFromMidpoint: member.MidpointExclusion != config.MidpointExclusionNone,
+		ExclusionReason:      member.MidpointExclusion,
+		Cluster:              member.Cluster,
+		SavedLocation:        member.SavedLocation,
+		LocationPrivacy:      member.LocationPrivacy,
+	}
+}
+
+func toTraveller(member models.GroupUser) services.Traveller {
+	return services.Traveller{
+		Location: dto.Location{Latitude: member.Latitude, Longitude: member.Longitude},
+		Mode:     member.TravelMode,
+	}
+}
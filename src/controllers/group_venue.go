@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetGroupVenue chooses the group's meeting place, either one of its places or a custom place
+// A chosen group place is copied, so the venue stays the same even if that place is dropped later
+func (c *GroupsController) SetGroupVenue(groupID string, userID uint, req *dto.GroupVenueRequest) (*dto.GroupResponse, error) {
+	var group models.Group
+	if err := c.db.Preload("Creator").Where("id = ?", groupID).First(&group).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+
+	venue := &models.GroupVenue{
+		ChosenBy: userID,
+		ChosenAt: time.Now(),
+	}
+	if req.Custom != nil {
+		venue.Name = req.Custom.Name
+		venue.Address = req.Custom.Address
+		venue.MapURI = req.Custom.MapURI
+		venue.Latitude = req.Custom.Latitude
+		venue.Longitude = req.Custom.Longitude
+	} else {
+		var place models.GroupPlace
+		if err := c.db.Where("group_id = ? AND place_id = ?", groupID, req.PlaceID).First(&place).Error; err != nil {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Place not found in group")
+		}
+		venue.PlaceID = place.PlaceID
+		venue.Name = place.Name
+		venue.Address = place.Address
+		venue.Type = place.Type
+		venue.MapURI = place.MapURI
+		venue.Latitude = place.Latitude
+		venue.Longitude = place.Longitude
+	}
+
+	applogger.Info("Choosing venue", venue.Name, "for group", groupID)
+	group.Venue = venue
+	// only the venue, so a midpoint the refresh job saved meanwhile isn't overwritten
+	if err := c.db.Model(&group).Select("Venue").Updates(&models.Group{Venue: venue}).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to choose venue")
+	}
+	midpointPrivacy, err := groupMidpointPrivacy(c.db, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return toGroupResponse(&group, midpointPrivacy), nil
+}
+
+// ClearGroupVenue undoes the choice of venue, so places are suggested again
+func (c *GroupsController) ClearGroupVenue(groupID string) (*dto.GroupResponse, error) {
+	var group models.Group
+	if err := c.db.Preload("Creator").Where("id = ?", groupID).First(&group).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+
+	group.Venue = nil
+	if err := c.db.Model(&group).Select("Venue").Updates(&models.Group{Venue: nil}).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to clear venue")
+	}
+	midpointPrivacy, err := groupMidpointPrivacy(c.db, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return toGroupResponse(&group, midpointPrivacy), nil
+}
+
+func toGroupVenueResponse(venue *models.GroupVenue) *dto.GroupVenueResponse {
+	if venue == nil {
+		return nil
+	}
+	return &dto.GroupVenueResponse{
+		PlaceID:   venue.PlaceID,
+		Name:      venue.Name,
+		Address:   venue.Address,
+		Type:      venue.Type,
+		MapURI:    venue.MapURI,
+		Latitude:  venue.Latitude,
+		Longitude: venue.Longitude,
+		Custom:    venue.PlaceID == "",
+		ChosenBy:  venue.ChosenBy,
+		ChosenAt:  venue.ChosenAt,
+	}
+}
@@ -1,16 +1,21 @@
 package controllers
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/championswimmer/api.midpoint.place/src/config"
 	"github.com/championswimmer/api.midpoint.place/src/db"
 	"github.com/championswimmer/api.midpoint.place/src/db/models"
 	"github.com/championswimmer/api.midpoint.place/src/dto"
 	"github.com/championswimmer/api.midpoint.place/src/server/validators"
+	"github.com/championswimmer/api.midpoint.place/src/services"
 	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -19,7 +24,8 @@ import (
 )
 
 type GroupsController struct {
-	db *gorm.DB
+	db                  *gorm.DB
+	travelTimeEstimator *services.TravelTimeEstimator
 }
 
 func getGroupPlaceTypesOrDefault(placeTypes []config.PlaceType) []config.PlaceType {
@@ -32,7 +38,8 @@ func getGroupPlaceTypesOrDefault(placeTypes []config.PlaceType) []config.PlaceTy
 func CreateGroupsController() *GroupsController {
 	appDb := db.GetAppDB()
 	return &GroupsController{
-		db: appDb,
+		db:                  appDb,
+		travelTimeEstimator: services.GetTravelTimeEstimator(),
 	}
 }
 
@@ -71,7 +78,10 @@ func groupTypePrivacyLevel(groupType config.GroupType) int {
 	}
 }
 
-func (c *GroupsController) GetGroupByIDorCode(groupIDorCode string, includeUsers bool, includePlaces bool) (*dto.GroupResponse, error) {
+// GetGroupByIDorCode returns the group with the given ID or code, optionally with its members and places
+// Places are ranked by the members' travel times, which are estimated within ctx, and shown to the viewer
+// with the other members' ETAs masked like their locations.
+func (c *GroupsController) GetGroupByIDorCode(ctx context.Context, groupIDorCode string, viewerID uint, includeUsers bool, includePlaces bool) (*dto.GroupResponse, error) {
 	var group models.Group
 
 	// Check if input is valid UUID or 10-char alphanumeric code
@@ -89,8 +99,9 @@ func (c *GroupsController) GetGroupByIDorCode(groupIDorCode string, includeUsers
 		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Invalid group ID or code")
 	}
 
-	// Always preload creator
-	query := c.db.Preload("Creator").Joins("LEFT JOIN users ON groups.creator_id = users.id")
+	// Always preload creator and clusters
+	query := c.db.Preload("Creator").Joins("LEFT JOIN users ON groups.creator_id = users.id").
+		Preload("Clusters", func(db *gorm.DB) *gorm.DB { return db.Order("number") })
 
 	if includeUsers {
 		query = query.Preload("Members", "group_users.deleted_at IS NULL").
@@ -99,6 +110,10 @@ func (c *GroupsController) GetGroupByIDorCode(groupIDorCode string, includeUsers
 	}
 	if includePlaces {
 		query = query.Preload("Places").Joins("LEFT JOIN group_places ON groups.id = group_places.group_id")
+		if !includeUsers {
+			// members are needed for their ETAs to the places
+			query = query.Preload("Members", "group_users.deleted_at IS NULL")
+		}
 	}
 
 	if isValidUUID {
@@ -111,7 +126,157 @@ func (c *GroupsController) GetGroupByIDorCode(groupIDorCode string, includeUsers
 		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
 	}
 
-	groupResponse := &dto.GroupResponse{
+	midpointPrivacy, err := groupMidpointPrivacy(c.db, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	groupResponse := toGroupResponse(&group, midpointPrivacy)
+
+	if includeUsers {
+		groupResponse.Members = lo.Map(group.Members, func(member models.GroupUser, _ int) dto.GroupUserResponse {
+			return toGroupUserResponse(member)
+		})
+	}
+	if len(group.Clusters) > 0 && !includeUsers && !includePlaces {
+		// members are needed to list who is in each cluster
+		if err := c.db.Where("group_id = ?", group.ID).Find(&group.Members).Error; err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group members")
+		}
+	}
+	groupResponse.Clusters = lo.Map(group.Clusters, func(cluster models.GroupCluster, _ int) dto.GroupClusterResponse {
+		members := lo.Filter(group.Members, func(member models.GroupUser, _ int) bool { return member.Cluster == cluster.Number })
+		// a cluster's midpoint is shown no more exactly than its members' locations
+		midpoint := services.MaskLocation(
+			dto.Location{Latitude: cluster.MidpointLatitude, Longitude: cluster.MidpointLongitude},
+			membersLocationPrivacy(members),
+		)
+		return dto.GroupClusterResponse{
+			Number:            cluster.Number,
+			MidpointLatitude:  midpoint.Latitude,
+			MidpointLongitude: midpoint.Longitude,
+			PlacesRadius:      cluster.PlacesRadius,
+			MemberIDs: lo.Map(members, func(member models.GroupUser, _ int) uint {
+				return member.UserID
+			}),
+		}
+	})
+
+	if includePlaces {
+		// places found around a cluster's midpoint are listed under that cluster,
+		// ranked by the travel times of its own members
+		placesByCluster := lo.GroupBy(group.Places, func(place models.GroupPlace) int { return place.Cluster })
+		groupResponse.Places = c.rankPlacesByTravelTime(ctx, viewerID, toGroupPlaceResponses(placesByCluster[0]), group.Members)
+		for i, cluster := range groupResponse.Clusters {
+			members := lo.Filter(group.Members, func(member models.GroupUser, _ int) bool { return member.Cluster == cluster.Number })
+			groupResponse.Clusters[i].Places = c.rankPlacesByTravelTime(ctx, viewerID, toGroupPlaceResponses(placesByCluster[cluster.Number]), members)
+		}
+	}
+	return groupResponse, nil
+}
+
+func toGroupPlaceResponses(places []models.GroupPlace) []dto.GroupPlaceResponse {
+	return lo.Map(places, func(place models.GroupPlace, _ int) dto.GroupPlaceResponse {
+		return dto.GroupPlaceResponse{
+			PlaceID:     place.PlaceID,
+			GroupID:     place.GroupID,
+			Name:        place.Name,
+			Address:     place.Address,
+			Type:        place.Type,
+			Rating:      place.Rating,
+			MapURI:      place.MapURI,
+			Latitude:    place.Latitude,
+			Longitude:   place.Longitude,
+			Cluster:     place.Cluster,
+			Source:      place.Source,
+			AddedBy:     place.AddedByID,
+			ReviewCount: place.ReviewCount,
+		}
+	})
+}
+
+// rankPlacesByTravelTime fills in every member's ETA to each place, and sorts the places
+// so that the ones where the last member arrives soonest come first
+// Like their locations, other members' ETAs are shown to the viewer rounded if their location is approximate,
+// and not at all if it is hidden, so they can't be worked out from the ETAs to a few places.
+func (c *GroupsController) rankPlacesByTravelTime(ctx context.Context, viewerID uint, places []dto.GroupPlaceResponse, members []models.GroupUser) []dto.GroupPlaceResponse {
+	if len(places) == 0 || len(members) == 0 {
+		return places
+	}
+
+	destinations := lo.Map(places, func(place dto.GroupPlaceResponse, _ int) dto.Location {
+		return dto.Location{Latitude: place.Latitude, Longitude: place.Longitude}
+	})
+	travellers := lo.Map(members, func(member models.GroupUser, _ int) services.Traveller {
+		return toTraveller(member)
+	})
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	times, err := c.travelTimeEstimator.TravelTimes(ctx, travellers, destinations)
+	if err != nil {
+		applogger.Error("Failed to estimate travel times to group places", err)
+		return places
+	}
+
+	maxMinutes := make(map[string]float64, len(places))
+	totalMinutes := make(map[string]float64, len(places))
+	for j := range places {
+		places[j].MemberETAs = make([]dto.MemberETA, 0, len(members))
+		for i, member := range members {
+			privacy := lo.Ternary(member.UserID == viewerID, config.LocationPrivacyExact, member.LocationPrivacy)
+			if privacy != config.LocationPrivacyHidden {
+				eta := dto.MemberETA{UserID: member.UserID, TravelMode: member.TravelMode}
+				if minutes := times[i][j]; !math.IsInf(minutes, 1) {
+					eta.Minutes = lo.ToPtr(math.Round(services.MaskMinutes(minutes, privacy)*10) / 10)
+				}
+				places[j].MemberETAs = append(places[j].MemberETAs, eta)
+			}
+			maxMinutes[places[j].PlaceID] = math.Max(maxMinutes[places[j].PlaceID], times[i][j])
+			totalMinutes[places[j].PlaceID] += times[i][j]
+		}
+	}
+
+	sort.SliceStable(places, func(a, b int) bool {
+		if maxMinutes[places[a].PlaceID] != maxMinutes[places[b].PlaceID] {
+			return maxMinutes[places[a].PlaceID] < maxMinutes[places[b].PlaceID]
+		}
+		return totalMinutes[places[a].PlaceID] < totalMinutes[places[b].PlaceID]
+	})
+	return places
+}
+
+// membersLocationPrivacy is the privacy of a midpoint of the members, see services.LeastExactLocationPrivacy
+func membersLocationPrivacy(members []models.GroupUser) config.LocationPrivacy {
+	return services.LeastExactLocationPrivacy(lo.Map(members, func(member models.GroupUser, _ int) config.LocationPrivacy {
+		return member.LocationPrivacy
+	}))
+}
+
+// groupMidpointPrivacies finds the privacy of each group's midpoint, from the location privacies of its members
+func groupMidpointPrivacies(db *gorm.DB, groupIDs ...string) (map[string]config.LocationPrivacy, error) {
+	var members []models.GroupUser
+	if err := db.Select("group_id", "location_privacy").Where("group_id IN ?", groupIDs).Find(&members).Error; err != nil {
+		applogger.Error("Failed to fetch location privacies of groups", groupIDs, err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group members")
+	}
+	membersByGroup := lo.GroupBy(members, func(member models.GroupUser) string { return member.GroupID })
+	return lo.SliceToMap(groupIDs, func(groupID string) (string, config.LocationPrivacy) {
+		return groupID, membersLocationPrivacy(membersByGroup[groupID])
+	}), nil
+}
+
+// groupMidpointPrivacy is groupMidpointPrivacies for a single group
+func groupMidpointPrivacy(db *gorm.DB, groupID string) (config.LocationPrivacy, error) {
+	privacies, err := groupMidpointPrivacies(db, groupID)
+	return privacies[groupID], err
+}
+
+// toGroupResponse converts a group into its response, without members or places
+// Creator is only filled in if it has been preloaded
+// The midpoint is shown no more exactly than midpointPrivacy (that of the members' locations, as in a group of one
+// it is that member's location), without its label if it is hidden.
+func toGroupResponse(group *models.Group, midpointPrivacy config.LocationPrivacy) *dto.GroupResponse {
+	midpoint := services.MaskLocation(dto.Location{Latitude: group.MidpointLatitude, Longitude: group.MidpointLongitude}, midpointPrivacy)
+	return &dto.GroupResponse{
 		ID:   group.ID,
 		Name: group.Name,
 		Type: group.Type,
@@ -120,40 +285,19 @@ func (c *GroupsController) GetGroupByIDorCode(groupIDorCode string, includeUsers
 			ID:          group.Creator.ID,
 			DisplayName: group.Creator.DisplayName,
 		},
-		MidpointLatitude:  group.MidpointLatitude,
-		MidpointLongitude: group.MidpointLongitude,
+		MidpointLatitude:  midpoint.Latitude,
+		MidpointLongitude: midpoint.Longitude,
+		MidpointLabel:     lo.Ternary(midpointPrivacy == config.LocationPrivacyHidden, "", group.MidpointLabel),
 		Radius:            group.Radius,
+		PlacesRadius:      group.PlacesRadius,
 		PlaceTypes:        getGroupPlaceTypesOrDefault(group.PlaceTypes),
+		MidpointStrategy:  group.MidpointStrategy,
+		ClusterMethod:     group.ClusterMethod,
+		ClusterCount:      group.ClusterCount,
+		ClusterRadius:     group.ClusterRadius,
+		SearchPreferences: dto.PlaceSearchPreferences(group.SearchPreferences),
+		Venue:             toGroupVenueResponse(group.Venue),
 	}
-
-	if includeUsers {
-		groupResponse.Members = lo.Map(group.Members, func(member models.GroupUser, _ int) dto.GroupUserResponse {
-			return dto.GroupUserResponse{
-				UserID:      member.UserID,
-				GroupID:     member.GroupID,
-				DisplayName: member.User.DisplayName,
-				Latitude:    member.Latitude,
-				Longitude:   member.Longitude,
-				Role:        member.Role,
-			}
-		})
-	}
-	if includePlaces {
-		groupResponse.Places = lo.Map(group.Places, func(place models.GroupPlace, _ int) dto.GroupPlaceResponse {
-			return dto.GroupPlaceResponse{
-				PlaceID:   place.PlaceID,
-				GroupID:   place.GroupID,
-				Name:      place.Name,
-				Address:   place.Address,
-				Type:      place.Type,
-				Rating:    place.Rating,
-				MapURI:    place.MapURI,
-				Latitude:  place.Latitude,
-				Longitude: place.Longitude,
-			}
-		})
-	}
-	return groupResponse, nil
 }
 
 func (c *GroupsController) CreateGroup(creatorID uint, req *dto.CreateGroupRequest) (*dto.GroupResponse, error) {
@@ -176,17 +320,34 @@ func (c *GroupsController) CreateGroup(creatorID uint, req *dto.CreateGroupReque
 		groupType = config.GroupTypePublic
 	}
 
+	midpointStrategy := req.MidpointStrategy
+	if midpointStrategy == "" {
+		midpointStrategy = config.MidpointStrategyCentroid
+	}
+
+	clusterMethod := req.ClusterMethod
+	if clusterMethod == "" {
+		clusterMethod = config.ClusterMethodNone
+	}
+
 	// Create new group
 	placeTypes := getGroupPlaceTypesOrDefault(req.PlaceTypes)
 	group := models.Group{
-		ID:         uuid.New().String(),
-		CreatorID:  creatorID,
-		Name:       req.Name,
-		Type:       groupType,
-		Code:       code,
-		Secret:     secret,
-		Radius:     req.Radius,
-		PlaceTypes: placeTypes,
+		ID:               uuid.New().String(),
+		CreatorID:        creatorID,
+		Name:             req.Name,
+		Type:             groupType,
+		Code:             code,
+		Secret:           secret,
+		Radius:           req.Radius,
+		PlaceTypes:       placeTypes,
+		MidpointStrategy: midpointStrategy,
+		ClusterMethod:    clusterMethod,
+		ClusterCount:     req.ClusterCount,
+		ClusterRadius:    req.ClusterRadius,
+	}
+	if req.SearchPreferences != nil {
+		group.SearchPreferences = models.PlaceSearchPreferences(*req.SearchPreferences)
 	}
 
 	if err := c.db.Create(&group).Error; err != nil {
@@ -199,20 +360,9 @@ func (c *GroupsController) CreateGroup(creatorID uint, req *dto.CreateGroupReque
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch creator information")
 	}
 
-	return &dto.GroupResponse{
-		ID:   group.ID,
-		Name: group.Name,
-		Type: group.Type,
-		Code: group.Code,
-		Creator: dto.GroupCreator{
-			ID:          creator.ID,
-			DisplayName: creator.DisplayName,
-		},
-		MidpointLatitude:  group.MidpointLatitude,
-		MidpointLongitude: group.MidpointLongitude,
-		Radius:            group.Radius,
-		PlaceTypes:        getGroupPlaceTypesOrDefault(group.PlaceTypes),
-	}, nil
+	group.Creator = creator
+	// a new group has no members, whose locations the midpoint could give away
+	return toGroupResponse(&group, config.LocationPrivacyExact), nil
 }
 
 func (c *GroupsController) UpdateGroup(groupID string, req *dto.UpdateGroupRequest) (*dto.GroupResponse, error) {
@@ -254,25 +404,45 @@ func (c *GroupsController) UpdateGroup(groupID string, req *dto.UpdateGroupReque
 	if req.PlaceTypes != nil {
 		group.PlaceTypes = *req.PlaceTypes
 	}
+	if req.MidpointStrategy != "" {
+		group.MidpointStrategy = req.MidpointStrategy
+	}
+	if req.HasClusterSettings() {
+		if req.ClusterMethod != "" {
+			group.ClusterMethod = req.ClusterMethod
+		}
+		if req.ClusterCount != 0 {
+			group.ClusterCount = req.ClusterCount
+		}
+		if req.ClusterRadius != 0 {
+			group.ClusterRadius = req.ClusterRadius
+		}
+		if err := validators.ValidateClusterSettings(group.ClusterMethod, group.ClusterCount, group.ClusterRadius); err != nil {
+			return nil, fiber.NewError(err.ErrorDetails())
+		}
+	}
+	if req.SearchPreferences != nil {
+		group.SearchPreferences = models.PlaceSearchPreferences(*req.SearchPreferences)
+	}
 
 	if err := c.db.Save(&group).Error; err != nil {
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update group")
 	}
 
-	return &dto.GroupResponse{
-		ID:   group.ID,
-		Name: group.Name,
-		Type: group.Type,
-		Code: group.Code,
-		Creator: dto.GroupCreator{
-			ID:          group.Creator.ID,
-			DisplayName: group.Creator.DisplayName,
-		},
-		MidpointLatitude:  group.MidpointLatitude,
-		MidpointLongitude: group.MidpointLongitude,
-		Radius:            group.Radius,
-		PlaceTypes:        getGroupPlaceTypesOrDefault(group.PlaceTypes),
-	}, nil
+	midpointPrivacy, err := groupMidpointPrivacy(c.db, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toGroupResponse(&group, midpointPrivacy), nil
+}
+
+// GetGroupMidpoint returns the group's exact midpoint, for checks which the group response's masked one won't do
+func (c *GroupsController) GetGroupMidpoint(groupID string) (dto.Location, error) {
+	var group models.Group
+	if err := c.db.Select("midpoint_latitude", "midpoint_longitude").Where("id = ?", groupID).First(&group).Error; err != nil {
+		return dto.Location{}, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+	return dto.Location{Latitude: group.MidpointLatitude, Longitude: group.MidpointLongitude}, nil
 }
 
 func (c *GroupsController) UpdateGroupMidpoint(groupID string, req *dto.UpdateGroupMidpointRequest) (*dto.GroupResponse, error) {
@@ -285,24 +455,13 @@ func (c *GroupsController) UpdateGroupMidpoint(groupID string, req *dto.UpdateGr
 
 	group.MidpointLatitude = req.Latitude
 	group.MidpointLongitude = req.Longitude
+	group.MidpointLabel = req.Label
 
 	if err := c.db.Save(&group).Error; err != nil {
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update group location")
 	}
-	return &dto.GroupResponse{
-		ID:   group.ID,
-		Name: group.Name,
-		Type: group.Type,
-		Code: group.Code,
-		Creator: dto.GroupCreator{
-			ID:          group.Creator.ID,
-			DisplayName: group.Creator.DisplayName,
-		},
-		MidpointLatitude:  group.MidpointLatitude,
-		MidpointLongitude: group.MidpointLongitude,
-		Radius:            group.Radius,
-		PlaceTypes:        getGroupPlaceTypesOrDefault(group.PlaceTypes),
-	}, nil
+	// only the refresh job sees the response, and it searches for places around the exact midpoint
+	return toGroupResponse(&group, config.LocationPrivacyExact), nil
 }
 
 func (c *GroupsController) GetGroupsByCreator(creatorID uint) ([]dto.GroupResponse, error) {
@@ -323,23 +482,15 @@ func (c *GroupsController) GetGroupsByCreator(creatorID uint) ([]dto.GroupRespon
 		Find(&groupsWithCount).Error; err != nil {
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch groups by creator")
 	}
+	midpointPrivacies, err := groupMidpointPrivacies(c.db, lo.Map(groupsWithCount, func(gwc GroupWithMemberCount, _ int) string { return gwc.ID })...)
+	if err != nil {
+		return nil, err
+	}
 
 	groupResponses := lo.Map(groupsWithCount, func(gwc GroupWithMemberCount, _ int) dto.GroupResponse {
-		return dto.GroupResponse{
-			ID:   gwc.Group.ID,
-			Name: gwc.Group.Name,
-			Type: gwc.Group.Type,
-			Code: gwc.Group.Code,
-			Creator: dto.GroupCreator{
-				ID:          gwc.Group.Creator.ID,
-				DisplayName: gwc.Group.Creator.DisplayName,
-			},
-			MidpointLatitude:  gwc.Group.MidpointLatitude,
-			MidpointLongitude: gwc.Group.MidpointLongitude,
-			Radius:            gwc.Group.Radius,
-			PlaceTypes:        getGroupPlaceTypesOrDefault(gwc.Group.PlaceTypes),
-			MemberCount:       gwc.MemberCount,
-		}
+		groupResponse := toGroupResponse(&gwc.Group, midpointPrivacies[gwc.ID])
+		groupResponse.MemberCount = gwc.MemberCount
+		return *groupResponse
 	})
 
 	return groupResponses, nil
@@ -365,24 +516,16 @@ func (c *GroupsController) GetPublicGroups(limit int) ([]dto.GroupResponse, erro
 		Find(&groupsWithCount).Error; err != nil {
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch public groups")
 	}
+	midpointPrivacies, err := groupMidpointPrivacies(c.db, lo.Map(groupsWithCount, func(gwc GroupWithMemberCount, _ int) string { return gwc.ID })...)
+	if err != nil {
+		return nil, err
+	}
 
 	// Convert to response DTOs
 	groupResponses := lo.Map(groupsWithCount, func(gwc GroupWithMemberCount, _ int) dto.GroupResponse {
-		return dto.GroupResponse{
-			ID:   gwc.Group.ID,
-			Name: gwc.Group.Name,
-			Type: gwc.Group.Type,
-			Code: gwc.Group.Code,
-			Creator: dto.GroupCreator{
-				ID:          gwc.Group.Creator.ID,
-				DisplayName: gwc.Group.Creator.DisplayName,
-			},
-			MidpointLatitude:  gwc.Group.MidpointLatitude,
-			MidpointLongitude: gwc.Group.MidpointLongitude,
-			Radius:            gwc.Group.Radius,
-			PlaceTypes:        getGroupPlaceTypesOrDefault(gwc.Group.PlaceTypes),
-			MemberCount:       gwc.MemberCount,
-		}
+		groupResponse := toGroupResponse(&gwc.Group, midpointPrivacies[gwc.ID])
+		groupResponse.MemberCount = gwc.MemberCount
+		return *groupResponse
 	})
 
 	return groupResponses, nil
@@ -109,3 +109,76 @@ func TestUpdateGroup_AllowsClosingPrivacy_WhenMemberCountIsMoreThanOne(t *testin
 	require.NoError(t, err)
 	assert.Equal(t, config.GroupTypePrivate, resp.Type)
 }
+
+func TestReplaceGroupPlaces_ReplacedPlacesAreUpdated(t *testing.T) {
+	db := setupGroupsControllerTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.GroupCluster{}, &models.GroupPlace{}, &models.GroupPlacesSnapshot{}))
+	controller := &GroupPlacesController{db: db}
+	group := createGroupFixture(t, db, config.GroupTypePublic)
+	custom := models.GroupPlace{GroupID: group.ID, PlaceID: config.CustomPlaceIDPrefix + "1", Name: "Custom", Type: config.PlaceTypeCafe, Source: config.PlaceSourceCustom}
+	require.NoError(t, db.Create(&custom).Error)
+
+	place := dto.Place{
+		Location: dto.Location{Latitude: 12.9716, Longitude: 77.5946},
+		Id:       "place-1",
+		Name:     "Old Name",
+		Type:     config.PlaceTypeCafe,
+		Rating:   3.1,
+	}
+	require.NoError(t, controller.ReplaceGroupPlaces(group.ID, []dto.GroupPlacesAddRequest{{Places: []dto.Place{place}}}))
+
+	place.Name = "New Name"
+	place.Rating = 4.6
+	place.ReviewCount = 120
+	place.Latitude = 12.9720
+	require.NoError(t, controller.ReplaceGroupPlaces(group.ID, []dto.GroupPlacesAddRequest{{Cluster: 2, Places: []dto.Place{place}}}))
+
+	var saved models.GroupPlace
+	require.NoError(t, db.Where("group_id = ? AND place_id = ?", group.ID, place.Id).First(&saved).Error)
+	assert.Equal(t, "New Name", saved.Name)
+	assert.Equal(t, 4.6, saved.Rating)
+	assert.Equal(t, 120, saved.ReviewCount)
+	assert.Equal(t, 12.9720, saved.Latitude)
+	assert.Equal(t, 2, saved.Cluster)
+
+	// custom places added by members are kept
+	var count int64
+	require.NoError(t, db.Model(&models.GroupPlace{}).Where("group_id = ?", group.ID).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestRestoreGroupPlacesSnapshot_RestoresClusterMidpoints(t *testing.T) {
+	db := setupGroupsControllerTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.GroupCluster{}, &models.GroupPlace{}, &models.GroupPlacesSnapshot{}))
+	controller := &GroupPlacesController{db: db}
+	group := createGroupFixture(t, db, config.GroupTypePublic)
+	require.NoError(t, db.Create(&models.GroupCluster{GroupID: group.ID, Number: 1, MidpointLatitude: 12.90, MidpointLongitude: 77.50}).Error)
+
+	snapshot := models.GroupPlacesSnapshot{
+		GroupID:           group.ID,
+		MidpointLatitude:  12.95,
+		MidpointLongitude: 77.55,
+		Clusters: []models.GroupPlacesSnapshotCluster{
+			{Number: 1, MidpointLatitude: 12.97, MidpointLongitude: 77.59},
+			// no longer exists
+			{Number: 2, MidpointLatitude: 13.10, MidpointLongitude: 77.70},
+		},
+		Places: []models.GroupPlacesSnapshotPlace{
+			{PlaceID: "place-1", Name: "Cafe", Type: config.PlaceTypeCafe, Latitude: 12.971, Longitude: 77.591, Cluster: 1},
+		},
+	}
+	require.NoError(t, db.Create(&snapshot).Error)
+
+	require.NoError(t, controller.RestoreGroupPlacesSnapshot(group.ID, snapshot.ID))
+
+	var clusters []models.GroupCluster
+	require.NoError(t, db.Where("group_id = ?", group.ID).Find(&clusters).Error)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, 12.97, clusters[0].MidpointLatitude)
+	assert.Equal(t, 77.59, clusters[0].MidpointLongitude)
+
+	var restored models.GroupPlacesSnapshot
+	require.NoError(t, db.Where("group_id = ? AND restored_from_id = ?", group.ID, snapshot.ID).First(&restored).Error)
+	require.Len(t, restored.Clusters, 1)
+	assert.Equal(t, 12.97, restored.Clusters[0].MidpointLatitude)
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// admins see at most this many jobs at a time
+const jobsQueryLimit = 100
+
+// a job waits while another one of the same kind and key is running, so that they don't run side by side
+const noRunningJobWithSameKey = "NOT EXISTS (SELECT 1 FROM jobs AS running WHERE running.kind = jobs.kind AND running.key = jobs.key " +
+	"AND running.key <> '' AND running.status = ? AND running.deleted_at IS NULL)"
+
+type JobsController struct {
+	db *gorm.DB
+}
+
+func CreateJobsController() *JobsController {
+	appDb := db.GetAppDB()
+	return &JobsController{
+		db: appDb,
+	}
+}
+
+// EnqueueJob queues a job to run now
+// If a job of the same kind and key is already queued, that job is returned instead, as it hasn't started yet
+func (c *JobsController) EnqueueJob(kind string, key string, payload string) (*models.Job, error) {
+	var job models.Job
+	if key != "" {
+		// Find rather than First, as not finding one is expected and First would log it as an error
+		result := c.db.Where("kind = ? AND key = ? AND status = ?", kind, key, config.JobStatusQueued).Order("id").Limit(1).Find(&job)
+		if result.Error != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch jobs")
+		}
+		if result.RowsAffected == 1 {
+			return &job, nil
+		}
+	}
+
+	job = models.Job{
+		Kind:        kind,
+		Key:         key,
+		Payload:     payload,
+		Status:      config.JobStatusQueued,
+		RunAt:       time.Now(),
+		MaxAttempts: config.JobMaxAttempts,
+	}
+	if err := c.db.Create(&job).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to queue job")
+	}
+	return &job, nil
+}
+
+// ClaimNextJob marks the oldest due job as running and returns it, or nil if there is nothing to do
+// Workers may race for the same job, only the one whose update goes through gets it.
+// Matching on attempts as well stops a worker from claiming a job which another one has already run and failed.
+// Jobs of the same kind and key as a running job are skipped until it is done.
+func (c *JobsController) ClaimNextJob() (*models.Job, error) {
+	for {
+		var job models.Job
+		// most polls find nothing, which First would log as an error every time
+		result := c.db.Where("status = ? AND run_at <= ?", config.JobStatusQueued, time.Now()).
+			Where(noRunningJobWithSameKey, config.JobStatusRunning).
+			Order("run_at, id").
+			Limit(1).
+			Find(&job)
+		if result.Error != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch jobs")
+		}
+		if result.RowsAffected == 0 {
+			return nil, nil
+		}
+
+		result = c.db.Model(&models.Job{}).
+			Where("id = ? AND status = ? AND attempts = ?", job.ID, config.JobStatusQueued, job.Attempts).
+			Where(noRunningJobWithSameKey, config.JobStatusRunning).
+			Updates(map[string]any{"status": config.JobStatusRunning, "attempts": job.Attempts + 1})
+		if result.Error != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to claim job")
+		}
+		if result.RowsAffected == 1 {
+			job.Status = config.JobStatusRunning
+			job.Attempts++
+			return &job, nil
+		}
+	}
+}
+
+// CompleteJob removes a job which has succeeded
+func (c *JobsController) CompleteJob(job *models.Job) error {
+	if err := c.db.Unscoped().Delete(&models.Job{}, job.ID).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to complete job")
+	}
+	return nil
+}
+
+// FailJob queues the job again after backoff, or marks it dead if it has no attempts left
+func (c *JobsController) FailJob(job *models.Job, jobErr error, backoff time.Duration) error {
+	job.LastError = jobErr.Error()
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = config.JobStatusDead
+		applogger.Error("Job", job.ID, job.Kind, job.Key, "is dead after", job.Attempts, "attempts:", jobErr)
+	} else {
+		job.Status = config.JobStatusQueued
+		job.RunAt = time.Now().Add(backoff)
+		applogger.Warn("Job", job.ID, job.Kind, job.Key, "failed, retrying in", backoff, ":", jobErr)
+	}
+
+	if err := c.db.Model(&models.Job{}).Where("id = ?", job.ID).Updates(map[string]any{
+		"status":     job.Status,
+		"run_at":     job.RunAt,
+		"last_error": job.LastError,
+	}).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update job")
+	}
+	return nil
+}
+
+// RequeueRunningJobs puts jobs which were running when the app last stopped back in the queue
+// Only call this before any worker has started, as it can't tell those jobs apart from ones running now
+func (c *JobsController) RequeueRunningJobs() error {
+	result := c.db.Model(&models.Job{}).
+		Where("status = ?", config.JobStatusRunning).
+		Updates(map[string]any{"status": config.JobStatusQueued, "run_at": time.Now()})
+	if result.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to requeue jobs")
+	}
+	if result.RowsAffected > 0 {
+		applogger.Warn("Requeued", result.RowsAffected, "interrupted jobs")
+	}
+	return nil
+}
+
+// HasPendingJob is true if a job of the kind and key is queued or running
+func (c *JobsController) HasPendingJob(kind string, key string) (bool, error) {
+	var count int64
+	if err := c.db.Model(&models.Job{}).
+		Where("kind = ? AND key = ? AND status IN ?", kind, key, []config.JobStatus{config.JobStatusQueued, config.JobStatusRunning}).
+		Count(&count).Error; err != nil {
+		return false, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch jobs")
+	}
+	return count > 0, nil
+}
+
+// ListJobs returns the jobs with the status, oldest first
+func (c *JobsController) ListJobs(status config.JobStatus) ([]dto.JobResponse, error) {
+	var jobs []models.Job
+	if err := c.db.Where("status = ?", status).Order("id").Limit(jobsQueryLimit).Find(&jobs).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch jobs")
+	}
+
+	responses := make([]dto.JobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = *toJobResponse(&job)
+	}
+	return responses, nil
+}
+
+// RetryDeadJob queues a dead job again, with a fresh set of attempts
+func (c *JobsController) RetryDeadJob(jobID uint) (*dto.JobResponse, error) {
+	var job models.Job
+	if err := c.db.Where("id = ? AND status = ?", jobID, config.JobStatusDead).First(&job).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Dead job not found")
+	}
+
+	job.Status = config.JobStatusQueued
+	job.RunAt = time.Now()
+	job.Attempts = 0
+	if err := c.db.Save(&job).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retry job")
+	}
+	return toJobResponse(&job), nil
+}
+
+func toJobResponse(job *models.Job) *dto.JobResponse {
+	return &dto.JobResponse{
+		ID:          job.ID,
+		Kind:        job.Kind,
+		Key:         job.Key,
+		Status:      job.Status,
+		RunAt:       job.RunAt,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt,
+	}
+}
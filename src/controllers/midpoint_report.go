@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+)
+
+// GetMidpointReport reports every member's distance and travel time to the group midpoint and places,
+// along with how fair the midpoint is, and how fair it would be under the other midpoint strategies.
+// Fairness is measured over the members the midpoint was calculated for.
+// The distances and travel times of members other than the viewer are rounded, unless their location is exact,
+// and left out if it is hidden, as a few of them would give the location away. Fairness is measured over the
+// rounded distances too, and the midpoints are shown like the group midpoint, by the least exact of the members' locations.
+func (c *GroupsController) GetMidpointReport(ctx context.Context, groupID string, viewerID uint) (*dto.MidpointReportResponse, error) {
+	var group models.Group
+	if err := c.db.Preload("Members.User").Preload("Places").First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+
+	midpoint := dto.Location{Latitude: group.MidpointLatitude, Longitude: group.MidpointLongitude}
+	travellers := lo.Map(group.Members, func(member models.GroupUser, _ int) services.Traveller {
+		return toTraveller(member)
+	})
+	privacies := lo.Map(group.Members, func(member models.GroupUser, _ int) config.LocationPrivacy {
+		if member.UserID == viewerID {
+			return config.LocationPrivacyExact
+		}
+		return member.LocationPrivacy
+	})
+	midpointPrivacy := membersLocationPrivacy(group.Members)
+	included := lo.Filter(lo.Range(len(group.Members)), func(i int, _ int) bool {
+		return group.Members[i].MidpointExclusion == config.MidpointExclusionNone
+	})
+
+	destinations := []dto.Location{midpoint}
+	for _, place := range group.Places {
+		destinations = append(destinations, dto.Location{Latitude: place.Latitude, Longitude: place.Longitude})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	times, err := c.travelTimeEstimator.TravelTimes(ctx, travellers, destinations)
+	if err != nil {
+		applogger.Error("Failed to estimate travel times for midpoint report of group", groupID, err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to estimate travel times")
+	}
+
+	// destination is the index into destinations
+	reportFor := func(destination int) ([]dto.MemberMidpointReport, dto.FairnessMetrics) {
+		reports := make([]dto.MemberMidpointReport, len(group.Members))
+		for i, member := range group.Members {
+			privacy := privacies[i]
+			reports[i] = dto.MemberMidpointReport{
+				UserID:               member.UserID,
+				DisplayName:          member.User.DisplayName,
+				TravelMode:           member.TravelMode,
+				ExcludedFromMidpoint: member.MidpointExclusion != config.MidpointExclusionNone,
+			}
+			if privacy == config.LocationPrivacyHidden {
+				continue
+			}
+			reports[i].DistanceKm = lo.ToPtr(services.MaskDistanceKm(geo.DistanceKm(travellers[i].Location, destinations[destination]), privacy))
+			if minutes := times[i][destination]; !math.IsInf(minutes, 1) {
+				reports[i].Minutes = lo.ToPtr(math.Round(services.MaskMinutes(minutes, privacy)*10) / 10)
+			}
+		}
+		return reports, memberDistanceFairness(travellers, privacies, included, destinations[destination])
+	}
+
+	report := &dto.MidpointReportResponse{
+		GroupID:  group.ID,
+		Strategy: group.MidpointStrategy,
+		Midpoint: services.MaskLocation(midpoint, midpointPrivacy),
+	}
+	report.Members, report.Fairness = reportFor(0)
+
+	report.Places = make([]dto.PlaceFairnessReport, len(group.Places))
+	for j, place := range group.Places {
+		report.Places[j] = dto.PlaceFairnessReport{
+			PlaceID:  place.PlaceID,
+			Name:     place.Name,
+			Type:     place.Type,
+			Location: destinations[j+1],
+		}
+		report.Places[j].Members, report.Places[j].Fairness = reportFor(j + 1)
+	}
+
+	includedTravellers := lo.Map(included, func(i int, _ int) services.Traveller { return travellers[i] })
+	for _, strategy := range config.SupportedMidpointStrategies() {
+		strategyReport := dto.StrategyFairnessReport{
+			Strategy: strategy,
+			Midpoint: midpoint,
+			Current:  strategy == group.MidpointStrategy,
+		}
+		if !strategyReport.Current {
+			strategyReport.Midpoint, err = services.SelectMidpoint(ctx, c.travelTimeEstimator, includedTravellers, strategy)
+			if err != nil {
+				applogger.Error("Failed to select", strategy, "midpoint for report of group", groupID, err)
+				return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to compare midpoint strategies")
+			}
+		}
+		strategyReport.Fairness = memberDistanceFairness(travellers, privacies, included, strategyReport.Midpoint)
+		strategyReport.Midpoint = services.MaskLocation(strategyReport.Midpoint, midpointPrivacy)
+		report.Strategies = append(report.Strategies, strategyReport)
+	}
+
+	return report, nil
+}
+
+// memberDistanceFairness measures fairness over the distances of the included members, each masked by their privacy
+func memberDistanceFairness(travellers []services.Traveller, privacies []config.LocationPrivacy, included []int, destination dto.Location) dto.FairnessMetrics {
+	return services.DistanceFairness(lo.Map(included, func(i int, _ int) float64 {
+		return services.MaskDistanceKm(geo.DistanceKm(travellers[i].Location, destination), privacies[i])
+	}))
+}
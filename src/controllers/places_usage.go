@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// admins see at most this many groups and users in the places usage
+const placesUsageQueryLimit = 100
+
+// PlacesUsageController counts the calls made to the places provider, it is the services.PlacesUsageStore of the app
+type PlacesUsageController struct {
+	db *gorm.DB
+}
+
+func CreatePlacesUsageController() *PlacesUsageController {
+	appDb := db.GetAppDB()
+	return &PlacesUsageController{
+		db: appDb,
+	}
+}
+
+// RecordPlacesCall adds a call to the day's count of the operation, for the caller
+func (c *PlacesUsageController) RecordPlacesCall(day string, operation config.PlacesOperation, caller services.PlacesCaller) error {
+	usage := models.PlacesUsage{
+		Day:       day,
+		Operation: operation,
+		GroupID:   caller.GroupID,
+		UserID:    caller.UserID,
+		Calls:     1,
+	}
+	return c.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}, {Name: "operation"}, {Name: "group_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"calls":      gorm.Expr("places_usage.calls + 1"),
+			"updated_at": time.Now(),
+		}),
+	}).Create(&usage).Error
+}
+
+// CountPlacesCalls counts the calls of a day, for a group and user, an empty groupID or 0 userID counting all of them
+func (c *PlacesUsageController) CountPlacesCalls(day string, groupID string, userID uint) (int64, error) {
+	query := c.db.Model(&models.PlacesUsage{}).Where("day = ?", day)
+	if groupID != "" {
+		query = query.Where("group_id = ?", groupID)
+	}
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var calls int64
+	if err := query.Select("COALESCE(SUM(calls), 0)").Scan(&calls).Error; err != nil {
+		return 0, err
+	}
+	return calls, nil
+}
+
+// GetPlacesUsage sums up the calls of a day, by operation and for the groups and users which made the most
+func (c *PlacesUsageController) GetPlacesUsage(day string) (*dto.PlacesUsage, error) {
+	var operations []struct {
+		Operation config.PlacesOperation
+		Calls     int64
+	}
+	if err := c.db.Model(&models.PlacesUsage{}).
+		Select("operation, SUM(calls) AS calls").
+		Where("day = ?", day).
+		Group("operation").
+		Scan(&operations).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch places usage")
+	}
+
+	var groups []dto.PlacesUsageCount
+	if err := c.db.Model(&models.PlacesUsage{}).
+		Select("group_id, SUM(calls) AS calls").
+		Where("day = ? AND group_id <> ''", day).
+		Group("group_id").
+		Order("calls DESC, group_id").
+		Limit(placesUsageQueryLimit).
+		Scan(&groups).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch places usage")
+	}
+
+	var users []dto.PlacesUsageCount
+	if err := c.db.Model(&models.PlacesUsage{}).
+		Select("user_id, SUM(calls) AS calls").
+		Where("day = ? AND user_id <> 0", day).
+		Group("user_id").
+		Order("calls DESC, user_id").
+		Limit(placesUsageQueryLimit).
+		Scan(&users).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch places usage")
+	}
+
+	usage := &dto.PlacesUsage{
+		Day:        day,
+		Operations: map[config.PlacesOperation]int64{},
+		Groups:     lo.Ternary(groups == nil, []dto.PlacesUsageCount{}, groups),
+		Users:      lo.Ternary(users == nil, []dto.PlacesUsageCount{}, users),
+	}
+	for _, operation := range operations {
+		usage.Operations[operation.Operation] = operation.Calls
+		usage.Calls += operation.Calls
+	}
+	return usage, nil
+}
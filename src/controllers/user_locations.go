@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/server/validators"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// ListSavedLocations returns the user's default location, if it has been set, followed by their named locations
+func (c *UsersController) ListSavedLocations(userID uint) ([]dto.SavedLocationResponse, error) {
+	var user models.User
+	if err := c.db.First(&user, userID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+	var userLocations []models.UserLocation
+	if err := c.db.Where("user_id = ?", userID).Order("name").Find(&userLocations).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch saved locations")
+	}
+
+	locations := []dto.SavedLocationResponse{}
+	if user.Latitude != 0 || user.Longitude != 0 {
+		locations = append(locations, dto.SavedLocationResponse{
+			Name:     config.DefaultSavedLocation,
+			Location: dto.Location{Latitude: user.Latitude, Longitude: user.Longitude},
+		})
+	}
+	return append(locations, lo.Map(userLocations, func(userLocation models.UserLocation, _ int) dto.SavedLocationResponse {
+		return dto.SavedLocationResponse{
+			Name:     userLocation.Name,
+			Location: dto.Location{Latitude: userLocation.Latitude, Longitude: userLocation.Longitude},
+		}
+	})...), nil
+}
+
+// GetSavedLocation returns the user's saved location with the name, the default one being the user's own location
+func (c *UsersController) GetSavedLocation(userID uint, name string) (*dto.Location, error) {
+	if name == config.DefaultSavedLocation {
+		var user models.User
+		if err := c.db.First(&user, userID).Error; err != nil {
+			return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+		}
+		// users start without a location
+		if user.Latitude == 0 && user.Longitude == 0 {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Saved location not found")
+		}
+		return &dto.Location{Latitude: user.Latitude, Longitude: user.Longitude}, nil
+	}
+
+	var userLocation models.UserLocation
+	if err := c.db.Where("user_id = ? AND name = ?", userID, name).First(&userLocation).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Saved location not found")
+	}
+	return &dto.Location{Latitude: userLocation.Latitude, Longitude: userLocation.Longitude}, nil
+}
+
+// SaveLocation saves the user's location under the name, replacing the one saved before
+// Saving the default location sets the user's own location, like UpdateUserLocation
+func (c *UsersController) SaveLocation(userID uint, name string, location dto.Location) (*dto.SavedLocationResponse, error) {
+	if name == config.DefaultSavedLocation {
+		if _, err := c.UpdateUserLocation(userID, &dto.UserUpdateRequest{Location: location}); err != nil {
+			return nil, err
+		}
+		return &dto.SavedLocationResponse{Name: name, Location: location}, nil
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var userLocation models.UserLocation
+		if err := tx.Where("user_id = ? AND name = ?", userID, name).First(&userLocation).Error; err == nil {
+			userLocation.Latitude = location.Latitude
+			userLocation.Longitude = location.Longitude
+			if err := tx.Save(&userLocation).Error; err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to save location")
+			}
+			return nil
+		}
+
+		var savedCount int64
+		if err := tx.Model(&models.UserLocation{}).Where("user_id = ?", userID).Count(&savedCount).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch saved locations")
+		}
+		if savedCount >= config.MaxSavedLocations {
+			return fiber.NewError(fiber.StatusConflict, "At most "+strconv.Itoa(config.MaxSavedLocations)+" locations can be saved")
+		}
+		userLocation = models.UserLocation{
+			UserID:    userID,
+			Name:      name,
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+		}
+		if err := tx.Create(&userLocation).Error; err != nil {
+			applogger.Error("Failed to save location", name, "of user", userID, err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to save location")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SavedLocationResponse{Name: name, Location: location}, nil
+}
+
+// DeleteSavedLocation deletes one of the user's named locations
+// Memberships which followed it stay where they are, but stop following it
+func (c *UsersController) DeleteSavedLocation(userID uint, name string) error {
+	if name == config.DefaultSavedLocation {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "The default location can't be deleted")
+	}
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		// deleted for good, so the name can be saved again
+		result := tx.Unscoped().Where("user_id = ? AND name = ?", userID, name).Delete(&models.UserLocation{})
+		if result.Error != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete saved location")
+		}
+		if result.RowsAffected == 0 {
+			return fiber.NewError(fiber.StatusNotFound, "Saved location not found")
+		}
+		if err := tx.Model(&models.GroupUser{}).Where("user_id = ? AND saved_location = ?", userID, name).
+			Update("saved_location", "").Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete saved location")
+		}
+		return nil
+	})
+}
+
+// MoveSavedLocationMemberships moves the user's memberships which follow the saved location to where it is now
+// Memberships of groups whose midpoint is too far away to join from there stay where they are, but stop following it.
+// Returns the IDs of the groups of the moved memberships, whose midpoints need recalculating
+func (c *UsersController) MoveSavedLocationMemberships(userID uint, name string, location dto.Location) ([]string, error) {
+	var groupIDs []string
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var memberships []models.GroupUser
+		if err := tx.Preload("Group").Where("user_id = ? AND saved_location = ?", userID, name).Find(&memberships).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch group memberships")
+		}
+		for _, membership := range memberships {
+			updates := map[string]any{"latitude": location.Latitude, "longitude": location.Longitude}
+			// like joining the group from the new location
+			midpoint := dto.Location{Latitude: membership.Group.MidpointLatitude, Longitude: membership.Group.MidpointLongitude}
+			if validators.ValidateLocationProximity(midpoint, location) != nil {
+				applogger.Info("Membership of user", userID, "in group", membership.GroupID, "stops following", name, "as it moved too far away")
+				updates = map[string]any{"saved_location": ""}
+			} else {
+				groupIDs = append(groupIDs, membership.GroupID)
+			}
+			if err := tx.Model(&models.GroupUser{}).Where("user_id = ? AND group_id = ?", userID, membership.GroupID).
+				Updates(updates).Error; err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to move group memberships")
+			}
+		}
+		if len(groupIDs) > 0 {
+			applogger.Info("Moved memberships of user", userID, "following", name, "in groups", groupIDs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groupIDs, nil
+}
@@ -58,7 +58,13 @@ func GetAppDB() *gorm.DB {
 		lo.Must0(appDB.AutoMigrate(&models.Group{}))
 		lo.Must0(appDB.AutoMigrate(&models.GroupUser{}))
 		lo.Must0(appDB.AutoMigrate(&models.GroupPlace{}))
+		lo.Must0(appDB.AutoMigrate(&models.GroupCluster{}))
+		lo.Must0(appDB.AutoMigrate(&models.GroupPlaceVote{}))
 		lo.Must0(appDB.AutoMigrate(&models.WaitlistSignup{}))
+		lo.Must0(appDB.AutoMigrate(&models.Job{}))
+		lo.Must0(appDB.AutoMigrate(&models.PlacesUsage{}))
+		lo.Must0(appDB.AutoMigrate(&models.GroupPlacesSnapshot{}))
+		lo.Must0(appDB.AutoMigrate(&models.UserLocation{}))
 
 	})
 
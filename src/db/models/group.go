@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/championswimmer/api.midpoint.place/src/config"
 	"gorm.io/gorm"
 )
@@ -26,11 +28,53 @@ type Group struct {
 	Type              config.GroupType `gorm:"type:varchar(10);not null;check:type in ('public','protected','private');default:'public'"`
 	MidpointLatitude  float64          `gorm:"type:decimal(10,8);not null;default:0"`
 	MidpointLongitude float64          `gorm:"type:decimal(11,8);not null;default:0"`
+	// Neighbourhood of the midpoint, empty if the geocoder doesn't know it
+	MidpointLabel string `gorm:"type:varchar(255);not null;default:''"`
 	// Radius in meters
-	Radius     int                `gorm:"type:integer;not null;default:2000"`
-	PlaceTypes []config.PlaceType `gorm:"serializer:json;not null;default:'[]'"`
-	Places     []GroupPlace       `gorm:"foreignKey:GroupID"`
-	Members    []GroupUser        `gorm:"foreignKey:GroupID"`
+	Radius int `gorm:"type:integer;not null;default:2000"`
+	// Radius in meters the group's places were found in, wider than Radius when too few places were found nearer
+	PlacesRadius int                `gorm:"type:integer;not null;default:0"`
+	PlaceTypes   []config.PlaceType `gorm:"serializer:json;not null;default:'[]'"`
+	// How the midpoint is chosen from the members' locations
+	MidpointStrategy config.MidpointStrategy `gorm:"type:varchar(20);not null;default:'centroid'"`
+	// How the members are split into sub-clusters, each with its own midpoint
+	ClusterMethod config.ClusterMethod `gorm:"type:varchar(10);not null;default:'none'"`
+	// Number of clusters, for k-means
+	ClusterCount int `gorm:"type:integer;not null;default:0"`
+	// Maximum distance in meters between neighbouring members of a cluster, for DBSCAN
+	ClusterRadius int            `gorm:"type:integer;not null;default:0"`
+	Places        []GroupPlace   `gorm:"foreignKey:GroupID"`
+	Members       []GroupUser    `gorm:"foreignKey:GroupID"`
+	Clusters      []GroupCluster `gorm:"foreignKey:GroupID"`
+	// Narrow down the places searched for the group
+	SearchPreferences PlaceSearchPreferences `gorm:"serializer:json;not null;default:'{}'"`
+	// Meeting place chosen by an admin, nil while places are still being suggested
+	// Places are not refreshed while a venue is chosen
+	Venue *GroupVenue `gorm:"serializer:json"`
+}
+
+// PlaceSearchPreferences are left at zero for no preference
+type PlaceSearchPreferences struct {
+	ResultsPerType       int               `json:"results_per_type,omitempty"`
+	MinRating            float64           `json:"min_rating,omitempty"`
+	MinPriceLevel        config.PriceLevel `json:"min_price_level,omitempty"`
+	MaxPriceLevel        config.PriceLevel `json:"max_price_level,omitempty"`
+	OpenNow              bool              `json:"open_now,omitempty"`
+	WheelchairAccessible bool              `json:"wheelchair_accessible,omitempty"`
+}
+
+// GroupVenue is a copy of the chosen place, so it stays put even if the group's places change
+type GroupVenue struct {
+	// PlaceID of the group place chosen, empty for a custom place
+	PlaceID   string           `json:"place_id,omitempty"`
+	Name      string           `json:"name"`
+	Address   string           `json:"address"`
+	Type      config.PlaceType `json:"type,omitempty"`
+	MapURI    string           `json:"map_uri,omitempty"`
+	Latitude  float64          `json:"latitude"`
+	Longitude float64          `json:"longitude"`
+	ChosenBy  uint             `json:"chosen_by"`
+	ChosenAt  time.Time        `json:"chosen_at"`
 }
 
 func (Group) TableName() string {
@@ -0,0 +1,20 @@
+package models
+
+import "gorm.io/gorm"
+
+// GroupCluster is a sub-cluster of a group's members, which gets its own midpoint and places
+type GroupCluster struct {
+	gorm.Model
+	GroupID string `gorm:"type:uuid;not null;uniqueIndex:idx_group_cluster"`
+	Group   Group  `gorm:"foreignKey:GroupID"`
+	// Clusters are numbered from 1, members and places with cluster 0 belong to the whole group
+	Number            int     `gorm:"not null;uniqueIndex:idx_group_cluster"`
+	MidpointLatitude  float64 `gorm:"type:decimal(10,8);not null;default:0"`
+	MidpointLongitude float64 `gorm:"type:decimal(11,8);not null;default:0"`
+	// Radius in meters the cluster's places were found in
+	PlacesRadius int `gorm:"type:integer;not null;default:0"`
+}
+
+func (GroupCluster) TableName() string {
+	return "group_clusters"
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"gorm.io/gorm"
+)
+
+// GroupPlaceVote is a member's say on one of the group's places: an upvote or downvote, and/or its rank in their ballot
+// Votes are keyed by the provider's PlaceID instead of the GroupPlace row, so they are kept
+// when a refresh finds the same place again
+type GroupPlaceVote struct {
+	gorm.Model
+	GroupID string `gorm:"type:uuid;not null;uniqueIndex:idx_group_place_vote"`
+	Group   Group  `gorm:"foreignKey:GroupID"`
+	PlaceID string `gorm:"not null;uniqueIndex:idx_group_place_vote"`
+	UserID  uint   `gorm:"not null;uniqueIndex:idx_group_place_vote"`
+	User    User   `gorm:"foreignKey:UserID"`
+	// Vote is up or down, or none if the place is only ranked
+	Vote config.PlaceVote `gorm:"type:varchar(8);not null;default:'none'"`
+	// Rank in the member's ranked ballot starting from 1, or 0 if the place isn't ranked
+	Rank int `gorm:"not null;default:0"`
+}
+
+func (GroupPlaceVote) TableName() string {
+	return "group_place_votes"
+}
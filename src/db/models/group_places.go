@@ -20,6 +20,14 @@ type GroupPlace struct {
 	MapURI    string           `gorm:"not null"`
 	Latitude  float64          `gorm:"not null"`
 	Longitude float64          `gorm:"not null"`
+	// Number of the sub-cluster whose midpoint this place was found around (0 for the group midpoint)
+	Cluster int `gorm:"type:integer;not null;default:0"`
+	// Where the place came from, custom places are kept when the group is refreshed
+	Source config.PlaceSource `gorm:"type:varchar(10);not null;default:'provider'"`
+	// Member who added a custom place
+	AddedByID uint `gorm:"not null;default:0"`
+	// How many ratings Rating is the average of
+	ReviewCount int `gorm:"not null;default:0"`
 }
 
 func (gp *GroupPlace) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"gorm.io/gorm"
+)
+
+// GroupPlacesSnapshot is the midpoint of a group and the places found around it by one refresh,
+// so earlier suggestions can be looked at and restored after the midpoint moves
+type GroupPlacesSnapshot struct {
+	gorm.Model
+	GroupID           string                  `gorm:"type:uuid;not null;index"`
+	Group             Group                   `gorm:"foreignKey:GroupID"`
+	MidpointLatitude  float64                 `gorm:"type:decimal(10,8);not null;default:0"`
+	MidpointLongitude float64                 `gorm:"type:decimal(11,8);not null;default:0"`
+	MidpointLabel     string                  `gorm:"type:varchar(255);not null;default:''"`
+	MidpointStrategy  config.MidpointStrategy `gorm:"type:varchar(20);not null;default:'centroid'"`
+	// MidpointPrivacy is that of the members' locations when the snapshot was taken, which the midpoint is shown at
+	MidpointPrivacy config.LocationPrivacy `gorm:"type:varchar(20);not null;default:'approximate'"`
+	// Radius the group searched in, and the radius its places were found in
+	Radius       int `gorm:"type:integer;not null;default:0"`
+	PlacesRadius int `gorm:"type:integer;not null;default:0"`
+	// RestoredFromID is the snapshot this one restored, 0 for snapshots of refreshes
+	RestoredFromID uint                         `gorm:"not null;default:0"`
+	Clusters       []GroupPlacesSnapshotCluster `gorm:"serializer:json;not null;default:'[]'"`
+	Places         []GroupPlacesSnapshotPlace   `gorm:"serializer:json;not null;default:'[]'"`
+}
+
+// GroupPlacesSnapshotCluster is a copy of a sub-cluster's midpoint
+type GroupPlacesSnapshotCluster struct {
+	Number            int     `json:"number"`
+	MidpointLatitude  float64 `json:"midpoint_latitude"`
+	MidpointLongitude float64 `json:"midpoint_longitude"`
+	PlacesRadius      int     `json:"places_radius"`
+	// LocationPrivacy of the cluster's members when the snapshot was taken, which the midpoint is shown at
+	LocationPrivacy config.LocationPrivacy `json:"location_privacy"`
+}
+
+// GroupPlacesSnapshotPlace is a copy of a place found by the places provider
+type GroupPlacesSnapshotPlace struct {
+	PlaceID     string           `json:"place_id"`
+	Name        string           `json:"name"`
+	Address     string           `json:"address"`
+	Type        config.PlaceType `json:"type"`
+	Rating      float64          `json:"rating"`
+	ReviewCount int              `json:"review_count"`
+	MapURI      string           `json:"map_uri"`
+	Latitude    float64          `json:"latitude"`
+	Longitude   float64          `json:"longitude"`
+	Cluster     int              `json:"cluster"`
+}
+
+func (GroupPlacesSnapshot) TableName() string {
+	return "group_places_snapshots"
+}
@@ -16,6 +16,18 @@ type GroupUser struct {
 	Latitude  float64              `gorm:"type:decimal(10,8);not null"`
 	Longitude float64              `gorm:"type:decimal(11,8);not null"`
 	Role      config.GroupUserRole `gorm:"type:varchar(50);not null;default:'member'"`
+	// How this member travels to the meeting place
+	TravelMode config.TravelMode `gorm:"type:varchar(20);not null;default:'drive'"`
+	// Admin's choice of whether this member counts towards the midpoint
+	MidpointInclusion config.MidpointInclusion `gorm:"type:varchar(20);not null;default:'auto'"`
+	// Why this member was left out of the last midpoint calculation (empty if they were included)
+	MidpointExclusion config.MidpointExclusionReason `gorm:"type:varchar(30);not null;default:''"`
+	// Number of the sub-cluster this member is in (0 if the group isn't clustered)
+	Cluster int `gorm:"type:integer;not null;default:0"`
+	// How precisely the other members see this member's location
+	LocationPrivacy config.LocationPrivacy `gorm:"type:varchar(20);not null;default:'approximate'"`
+	// Name of the user's saved location this membership follows, empty if the member joined from a location
+	SavedLocation string `gorm:"type:varchar(30);not null;default:''"`
 }
 
 func (GroupUser) TableName() string {
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"gorm.io/gorm"
+)
+
+// Job is a unit of background work, which is retried until it succeeds or runs out of attempts
+type Job struct {
+	gorm.Model
+	Kind string `gorm:"type:varchar(50);not null;index:idx_job_kind_key"`
+	// Key is what the job works on (e.g. a group ID), a queued job is not queued again for the same key
+	Key     string           `gorm:"type:varchar(100);not null;default:'';index:idx_job_kind_key"`
+	Payload string           `gorm:"type:text;not null;default:''"`
+	Status  config.JobStatus `gorm:"type:varchar(16);not null;default:'queued';index:idx_job_status_run_at"`
+	// RunAt is when the job is due, later than its creation for retries
+	RunAt       time.Time `gorm:"not null;index:idx_job_status_run_at"`
+	Attempts    int       `gorm:"not null;default:0"`
+	MaxAttempts int       `gorm:"not null"`
+	LastError   string    `gorm:"type:text;not null;default:''"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"gorm.io/gorm"
+)
+
+// PlacesUsage counts the calls made to the places provider in a (UTC) day, of one operation, for a group and user
+// Calls made outside a group or for no user in particular have an empty GroupID or a UserID of 0
+type PlacesUsage struct {
+	gorm.Model
+	Day       string                 `gorm:"type:varchar(10);not null;uniqueIndex:idx_places_usage"`
+	Operation config.PlacesOperation `gorm:"type:varchar(20);not null;uniqueIndex:idx_places_usage"`
+	GroupID   string                 `gorm:"type:varchar(36);not null;default:'';uniqueIndex:idx_places_usage"`
+	UserID    uint                   `gorm:"not null;default:0;uniqueIndex:idx_places_usage"`
+	Calls     int64                  `gorm:"not null;default:0"`
+}
+
+func (PlacesUsage) TableName() string {
+	return "places_usage"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "gorm.io/gorm"
+
+// UserLocation is a named location a user saved, like home or work, to join groups from
+// The user's own location (see User) is their default saved location, it isn't stored here
+type UserLocation struct {
+	gorm.Model
+	UserID    uint    `gorm:"not null;uniqueIndex:idx_user_location_name"`
+	User      User    `gorm:"foreignKey:UserID"`
+	Name      string  `gorm:"type:varchar(30);not null;uniqueIndex:idx_user_location_name"`
+	Latitude  float64 `gorm:"type:decimal(10,8);not null"`
+	Longitude float64 `gorm:"type:decimal(11,8);not null"`
+}
+
+func (UserLocation) TableName() string {
+	return "user_locations"
+}
@@ -40,5 +40,8 @@ func ProvidePostgresDB(dbUrl string, config *gorm.Config) *gorm.DB {
 
 func ProvideSqliteDB(dbUrl string, config *gorm.Config) *gorm.DB {
 	applogger.Warn("App: Using sqlite db")
-	return lo.Must(gorm.Open(sqlite.Open(dbUrl), config))
+	db := lo.Must(gorm.Open(sqlite.Open(dbUrl), config))
+	// sqlite fails writes which overlap (database table is locked) rather than waiting, so they go one at a time
+	lo.Must(db.DB()).SetMaxOpenConns(1)
+	return db
 }
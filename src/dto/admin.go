@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+)
+
+// PlacesCacheStats are the counters of the place search cache since the app started
+type PlacesCacheStats struct {
+	Enabled bool    `json:"enabled"`
+	Backend string  `json:"backend,omitempty"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	Errors  int64   `json:"errors"`
+	HitRate float64 `json:"hit_rate"`
+	// Entries is only known for the in-process cache
+	Entries int `json:"entries,omitempty"`
+}
+
+// JobResponse is a background job, as shown to admins
+type JobResponse struct {
+	ID          uint             `json:"id"`
+	Kind        string           `json:"kind"`
+	Key         string           `json:"key,omitempty"`
+	Status      config.JobStatus `json:"status"`
+	RunAt       time.Time        `json:"run_at"`
+	Attempts    int              `json:"attempts"`
+	MaxAttempts int              `json:"max_attempts"`
+	LastError   string           `json:"last_error,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// PlacesUsage is how many calls were made to the places provider in a (UTC) day, against the daily budgets
+type PlacesUsage struct {
+	Day   string `json:"day"`
+	Calls int64  `json:"calls"`
+	// Metered is false if calls to the places provider are not counted, then there are no calls or budgets
+	Metered    bool                             `json:"metered"`
+	Operations map[config.PlacesOperation]int64 `json:"operations"`
+	// Budgets are 0 for no limit
+	Budget      int64 `json:"budget"`
+	GroupBudget int64 `json:"group_budget"`
+	UserBudget  int64 `json:"user_budget"`
+	// BreakerOpen is true while the app's budget for today is used up, and places are searched for without the provider
+	BreakerOpen bool `json:"breaker_open"`
+	// Groups and Users are the ones which made the most calls, most first
+	Groups []PlacesUsageCount `json:"groups"`
+	Users  []PlacesUsageCount `json:"users"`
+}
+
+// PlacesUsageCount is how many calls a group or user made to the places provider
+type PlacesUsageCount struct {
+	GroupID string `json:"group_id,omitempty"`
+	UserID  uint   `json:"user_id,omitempty"`
+	Calls   int64  `json:"calls"`
+}
@@ -1,22 +1,143 @@
 package dto
 
-import "github.com/championswimmer/api.midpoint.place/src/config"
+import (
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+)
 
 // GroupPlacesAddRequest represents the request to add places to a group
 type GroupPlacesAddRequest struct {
 	Places []Place `json:"places" validate:"required,min=1"`
+	// Cluster the places were found around, 0 for the group midpoint
+	Cluster int `json:"cluster,omitempty"`
+	// Radius in meters the places were found in, 0 if not known
+	Radius int `json:"radius,omitempty"`
 }
 
 // GroupPlaceResponse represents a place in a group
 type GroupPlaceResponse struct {
-	ID        string           `json:"id"`
-	GroupID   string           `json:"group_id"`
-	PlaceID   string           `json:"place_id"`
-	Name      string           `json:"name"`
-	Address   string           `json:"address"`
-	Type      config.PlaceType `json:"type"`
-	Rating    float64          `json:"rating"`
-	MapURI    string           `json:"map_uri"`
-	Latitude  float64          `json:"latitude"`
-	Longitude float64          `json:"longitude"`
+	ID         string           `json:"id"`
+	GroupID    string           `json:"group_id"`
+	PlaceID    string           `json:"place_id"`
+	Name       string           `json:"name"`
+	Address    string           `json:"address"`
+	Type       config.PlaceType `json:"type"`
+	Rating     float64          `json:"rating"`
+	MapURI     string           `json:"map_uri"`
+	Latitude   float64          `json:"latitude"`
+	Longitude  float64          `json:"longitude"`
+	Cluster    int              `json:"cluster,omitempty"`
+	MemberETAs []MemberETA      `json:"member_etas,omitempty"`
+	Votes      *GroupPlaceVotes `json:"votes,omitempty"`
+	// Source is custom for places added by members, AddedBy is the member who added them
+	Source  config.PlaceSource `json:"source"`
+	AddedBy uint               `json:"added_by,omitempty"`
+	// ReviewCount is how many ratings Rating is the average of, 0 if the provider doesn't say
+	ReviewCount int `json:"review_count,omitempty"`
+	// Score ranks the place among the group's places, with a breakdown of how it was scored
+	Score *GroupPlaceScore `json:"score,omitempty"`
+}
+
+// GroupPlaceScore is how a place ranks among the places it is listed with, from 0 to 1
+// Score is the sum of the Points in the breakdown
+type GroupPlaceScore struct {
+	Score     float64               `json:"score"`
+	Breakdown []PlaceScoreComponent `json:"breakdown"`
+}
+
+// PlaceScoreComponent is one of the inputs of a place's score
+// Value is the input as it is (stars, reviews, meters or vote score), Normalized is between 0 for the worst and 1 for the best,
+// and Points is its share of the score, by its Weight among all the weights
+type PlaceScoreComponent struct {
+	Input      config.PlaceScoreInput `json:"input"`
+	Value      float64                `json:"value"`
+	Normalized float64                `json:"normalized"`
+	Weight     float64                `json:"weight"`
+	Points     float64                `json:"points"`
+}
+
+// MemberETA is the estimated time for a member to reach a place
+type MemberETA struct {
+	UserID     uint              `json:"user_id"`
+	TravelMode config.TravelMode `json:"travel_mode"`
+	// Minutes is null if the member cannot reach the place
+	Minutes *float64 `json:"minutes"`
+}
+
+// GroupPlaceVotes is the tally of the members' votes on a place
+type GroupPlaceVotes struct {
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+	// BordaPoints from the members' ranked ballots, the place ranked r-th of n gets n-r+1 points
+	BordaPoints int `json:"borda_points"`
+	// Score is BordaPoints + Upvotes - Downvotes, which places sorted by votes are ordered by
+	Score  int              `json:"score"`
+	MyVote config.PlaceVote `json:"my_vote,omitempty"`
+	MyRank int              `json:"my_rank,omitempty"`
+}
+
+// GroupPlaceVoteRequest is a member's upvote or downvote on a place, none takes it back
+type GroupPlaceVoteRequest struct {
+	Vote config.PlaceVote `json:"vote"`
+}
+
+// GroupPlaceRankingRequest is a member's ranked ballot, most preferred place first
+// It replaces their previous ballot, an empty list takes it back
+type GroupPlaceRankingRequest struct {
+	PlaceIDs []string `json:"place_ids"`
+}
+
+// CustomPlaceAddRequest adds a place of a member's own to the group, such as a friend's apartment
+type CustomPlaceAddRequest struct {
+	Location
+	Name    string           `json:"name"`
+	Address string           `json:"address"`
+	Type    config.PlaceType `json:"type"`
+}
+
+// GroupPlaceDetailsResponse is a place of the group, along with the details the places provider knows about it
+type GroupPlaceDetailsResponse struct {
+	GroupPlaceResponse
+	PlaceDetails
+}
+
+// GroupPlacesSnapshotResponse is the group's midpoint and the places found around it by one of its refreshes
+// Places are only included when getting a single snapshot
+type GroupPlacesSnapshotResponse struct {
+	ID                uint                    `json:"id"`
+	CreatedAt         time.Time               `json:"created_at"`
+	MidpointLatitude  float64                 `json:"midpoint_latitude"`
+	MidpointLongitude float64                 `json:"midpoint_longitude"`
+	MidpointLabel     string                  `json:"midpoint_label,omitempty"`
+	MidpointStrategy  config.MidpointStrategy `json:"midpoint_strategy"`
+	Radius            int                     `json:"radius"`
+	PlacesRadius      int                     `json:"places_radius"`
+	// RestoredFrom is the snapshot this one restored, it is left out for snapshots of refreshes
+	RestoredFrom uint                         `json:"restored_from,omitempty"`
+	PlaceCount   int                          `json:"place_count"`
+	Clusters     []GroupPlacesSnapshotCluster `json:"clusters,omitempty"`
+	Places       []GroupPlacesSnapshotPlace   `json:"places,omitempty"`
+}
+
+// GroupPlacesSnapshotCluster is a sub-cluster's midpoint when the snapshot was taken
+type GroupPlacesSnapshotCluster struct {
+	Number            int     `json:"number"`
+	MidpointLatitude  float64 `json:"midpoint_latitude"`
+	MidpointLongitude float64 `json:"midpoint_longitude"`
+	PlacesRadius      int     `json:"places_radius"`
+}
+
+// GroupPlacesSnapshotPlace is a place of a snapshot, with the cluster it was found around (0 for the group midpoint)
+type GroupPlacesSnapshotPlace struct {
+	PlaceID     string           `json:"place_id"`
+	Name        string           `json:"name"`
+	Address     string           `json:"address"`
+	Type        config.PlaceType `json:"type"`
+	Rating      float64          `json:"rating"`
+	ReviewCount int              `json:"review_count,omitempty"`
+	MapURI      string           `json:"map_uri"`
+	Latitude    float64          `json:"latitude"`
+	Longitude   float64          `json:"longitude"`
+	Cluster     int              `json:"cluster,omitempty"`
 }
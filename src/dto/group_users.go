@@ -3,16 +3,36 @@ package dto
 import "github.com/championswimmer/api.midpoint.place/src/config"
 
 // GroupUserJoinRequest represents the request to add a user to a group
+// Instead of the location, members can send an address or place name to geocode, or the name of one of their
+// saved locations, which the membership then follows whenever that saved location moves
 type GroupUserJoinRequest struct {
 	Location
+	Address       string            `json:"address,omitempty"`
+	SavedLocation string            `json:"saved_location,omitempty"`
+	TravelMode    config.TravelMode `json:"travel_mode" validate:"omitempty,oneof=walk bike drive transit"`
+	// LocationPrivacy decides how precisely the other members see the location, it is kept when joining again without one
+	LocationPrivacy config.LocationPrivacy `json:"location_privacy,omitempty" validate:"omitempty,oneof=exact approximate hidden"`
+}
+
+// GroupMemberUpdateRequest represents an admin's changes to a member of the group
+type GroupMemberUpdateRequest struct {
+	MidpointInclusion config.MidpointInclusion `json:"midpoint_inclusion" validate:"required,oneof=auto included excluded"`
 }
 
 // GroupUserResponse represents the response for group user operations
+// Other members' locations are shown at their privacy level, a hidden location is 0, 0
 type GroupUserResponse struct {
-	UserID      uint                 `json:"user_id"`
-	GroupID     string               `json:"group_id"`
-	DisplayName string               `json:"display_name,omitempty"`
-	Latitude    float64              `json:"latitude"`
-	Longitude   float64              `json:"longitude"`
-	Role        config.GroupUserRole `json:"role"`
+	UserID               uint                           `json:"user_id"`
+	GroupID              string                         `json:"group_id"`
+	DisplayName          string                         `json:"display_name,omitempty"`
+	Latitude             float64                        `json:"latitude"`
+	Longitude            float64                        `json:"longitude"`
+	Role                 config.GroupUserRole           `json:"role"`
+	TravelMode           config.TravelMode              `json:"travel_mode,omitempty"`
+	MidpointInclusion    config.MidpointInclusion       `json:"midpoint_inclusion,omitempty"`
+	ExcludedFromMidpoint bool                           `json:"excluded_from_midpoint"`
+	ExclusionReason      config.MidpointExclusionReason `json:"exclusion_reason,omitempty"`
+	Cluster              int                            `json:"cluster,omitempty"`
+	SavedLocation        string                         `json:"saved_location,omitempty"`
+	LocationPrivacy      config.LocationPrivacy         `json:"location_privacy"`
 }
@@ -1,25 +1,60 @@
 package dto
 
-import "github.com/championswimmer/api.midpoint.place/src/config"
+import (
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+)
 
 type CreateGroupRequest struct {
-	Name       string             `json:"name" validate:"required"`
-	Type       config.GroupType   `json:"type" validate:"omitempty,oneof=public protected private"`
-	Secret     string             `json:"secret" validate:"omitempty"`
-	Radius     int                `json:"radius" validate:"omitempty,min=0"`
-	PlaceTypes []config.PlaceType `json:"place_types" validate:"omitempty"`
+	Name             string                  `json:"name" validate:"required"`
+	Type             config.GroupType        `json:"type" validate:"omitempty,oneof=public protected private"`
+	Secret           string                  `json:"secret" validate:"omitempty"`
+	Radius           int                     `json:"radius" validate:"omitempty,min=0"`
+	PlaceTypes       []config.PlaceType      `json:"place_types" validate:"omitempty"`
+	MidpointStrategy config.MidpointStrategy `json:"midpoint_strategy" validate:"omitempty,oneof=centroid min_total_time min_max_time"`
+	ClusterMethod    config.ClusterMethod    `json:"cluster_method" validate:"omitempty,oneof=none kmeans dbscan"`
+	ClusterCount     int                     `json:"cluster_count" validate:"omitempty,min=2,max=10"`
+	ClusterRadius    int                     `json:"cluster_radius" validate:"omitempty,min=0"`
+	// SearchPreferences default to no preference
+	SearchPreferences *PlaceSearchPreferences `json:"search_preferences"`
 }
 
 type UpdateGroupRequest struct {
-	Name       string              `json:"name" validate:"omitempty"`
-	Type       config.GroupType    `json:"type" validate:"omitempty,oneof=public protected private"`
-	Secret     string              `json:"secret" validate:"omitempty"`
-	Radius     int                 `json:"radius" validate:"omitempty,min=0"`
-	PlaceTypes *[]config.PlaceType `json:"place_types" validate:"omitempty"`
+	Name             string                  `json:"name" validate:"omitempty"`
+	Type             config.GroupType        `json:"type" validate:"omitempty,oneof=public protected private"`
+	Secret           string                  `json:"secret" validate:"omitempty"`
+	Radius           int                     `json:"radius" validate:"omitempty,min=0"`
+	PlaceTypes       *[]config.PlaceType     `json:"place_types" validate:"omitempty"`
+	MidpointStrategy config.MidpointStrategy `json:"midpoint_strategy" validate:"omitempty,oneof=centroid min_total_time min_max_time"`
+	ClusterMethod    config.ClusterMethod    `json:"cluster_method" validate:"omitempty,oneof=none kmeans dbscan"`
+	ClusterCount     int                     `json:"cluster_count" validate:"omitempty,min=2,max=10"`
+	ClusterRadius    int                     `json:"cluster_radius" validate:"omitempty,min=0"`
+	// SearchPreferences replace the group's preferences as a whole
+	SearchPreferences *PlaceSearchPreferences `json:"search_preferences"`
+}
+
+// HasClusterSettings tells if the request changes how the group is split into clusters
+func (r *UpdateGroupRequest) HasClusterSettings() bool {
+	return r.ClusterMethod != "" || r.ClusterCount != 0 || r.ClusterRadius != 0
+}
+
+// PlaceSearchPreferences narrow down the places searched for a group, zero values mean no preference
+// Price levels go from 1 (free) to 5 (very expensive). Places the provider knows too little about are not filtered out.
+type PlaceSearchPreferences struct {
+	// ResultsPerType defaults to 3, and can be up to 20
+	ResultsPerType       int               `json:"results_per_type,omitempty"`
+	MinRating            float64           `json:"min_rating,omitempty"`
+	MinPriceLevel        config.PriceLevel `json:"min_price_level,omitempty"`
+	MaxPriceLevel        config.PriceLevel `json:"max_price_level,omitempty"`
+	OpenNow              bool              `json:"open_now,omitempty"`
+	WheelchairAccessible bool              `json:"wheelchair_accessible,omitempty"`
 }
 
 type UpdateGroupMidpointRequest struct {
 	Location
+	// Label names the neighbourhood of the midpoint, empty if it isn't known
+	Label string `json:"label,omitempty"`
 }
 
 type GroupCreator struct {
@@ -28,16 +63,66 @@ type GroupCreator struct {
 }
 
 type GroupResponse struct {
-	ID                string               `json:"id"`
-	Name              string               `json:"name"`
-	Type              config.GroupType     `json:"type"`
-	Code              string               `json:"code"`
-	Creator           GroupCreator         `json:"creator"`
+	ID                string                  `json:"id"`
+	Name              string                  `json:"name"`
+	Type              config.GroupType        `json:"type"`
+	Code              string                  `json:"code"`
+	Creator           GroupCreator            `json:"creator"`
+	MidpointLatitude  float64                 `json:"midpoint_latitude"`
+	MidpointLongitude float64                 `json:"midpoint_longitude"`
+	MidpointLabel     string                  `json:"midpoint_label,omitempty"`
+	Radius            int                     `json:"radius"`
+	PlacesRadius      int                     `json:"places_radius"`
+	PlaceTypes        []config.PlaceType      `json:"place_types"`
+	MidpointStrategy  config.MidpointStrategy `json:"midpoint_strategy"`
+	ClusterMethod     config.ClusterMethod    `json:"cluster_method"`
+	ClusterCount      int                     `json:"cluster_count,omitempty"`
+	ClusterRadius     int                     `json:"cluster_radius,omitempty"`
+	SearchPreferences PlaceSearchPreferences  `json:"search_preferences"`
+	MemberCount       int                     `json:"member_count,omitempty"`
+	Members           []GroupUserResponse     `json:"members,omitempty"`
+	Places            []GroupPlaceResponse    `json:"places,omitempty"`
+	Clusters          []GroupClusterResponse  `json:"clusters,omitempty"`
+	// Venue is the meeting place chosen by an admin, places are not refreshed while it is set
+	Venue *GroupVenueResponse `json:"venue,omitempty"`
+	// RefreshPending is true while the midpoint and places are waiting to be recalculated after a change
+	RefreshPending bool `json:"refresh_pending"`
+}
+
+// GroupClusterResponse is a sub-cluster of the group's members, with its own midpoint
+// Places are only included along with the group's places
+type GroupClusterResponse struct {
+	Number            int                  `json:"number"`
 	MidpointLatitude  float64              `json:"midpoint_latitude"`
 	MidpointLongitude float64              `json:"midpoint_longitude"`
-	Radius            int                  `json:"radius"`
-	PlaceTypes        []config.PlaceType   `json:"place_types"`
-	MemberCount       int                  `json:"member_count,omitempty"`
-	Members           []GroupUserResponse  `json:"members,omitempty"`
+	MemberIDs         []uint               `json:"member_ids"`
+	PlacesRadius      int                  `json:"places_radius"`
 	Places            []GroupPlaceResponse `json:"places,omitempty"`
 }
+
+// GroupVenueRequest chooses the group's venue, either one of its places by PlaceID or a custom place
+type GroupVenueRequest struct {
+	PlaceID string              `json:"place_id"`
+	Custom  *CustomVenueRequest `json:"custom"`
+}
+
+type CustomVenueRequest struct {
+	Location
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	MapURI  string `json:"map_uri"`
+}
+
+type GroupVenueResponse struct {
+	// PlaceID is empty for a custom venue
+	PlaceID   string           `json:"place_id,omitempty"`
+	Name      string           `json:"name"`
+	Address   string           `json:"address"`
+	Type      config.PlaceType `json:"type,omitempty"`
+	MapURI    string           `json:"map_uri,omitempty"`
+	Latitude  float64          `json:"latitude"`
+	Longitude float64          `json:"longitude"`
+	Custom    bool             `json:"custom"`
+	ChosenBy  uint             `json:"chosen_by"`
+	ChosenAt  time.Time        `json:"chosen_at"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import "github.com/championswimmer/api.midpoint.place/src/config"
+
+// MidpointReportResponse explains how fair the group midpoint is to its members
+type MidpointReportResponse struct {
+	GroupID  string                  `json:"group_id"`
+	Strategy config.MidpointStrategy `json:"strategy"`
+	Midpoint Location                `json:"midpoint"`
+	// Members lists everyone's distance and travel time to the midpoint
+	Members []MemberMidpointReport `json:"members"`
+	// Fairness is measured over the members the midpoint was calculated for
+	Fairness FairnessMetrics       `json:"fairness"`
+	Places   []PlaceFairnessReport `json:"places"`
+	// Strategies shows where the midpoint would be, and how fair it would be, under each strategy
+	Strategies []StrategyFairnessReport `json:"strategies"`
+}
+
+// MemberMidpointReport is how far a member is from a destination
+type MemberMidpointReport struct {
+	UserID               uint              `json:"user_id"`
+	DisplayName          string            `json:"display_name,omitempty"`
+	TravelMode           config.TravelMode `json:"travel_mode"`
+	ExcludedFromMidpoint bool              `json:"excluded_from_midpoint"`
+	// DistanceKm and Minutes are left out for other members whose location is hidden
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+	// Minutes is null if the member cannot reach the destination
+	Minutes *float64 `json:"minutes"`
+}
+
+// FairnessMetrics summarise how evenly the distances are spread between members
+// Gini is 0 when everyone travels the same distance, and approaches 1 when one member does all the travelling
+type FairnessMetrics struct {
+	MaxKm    float64 `json:"max_km"`
+	MeanKm   float64 `json:"mean_km"`
+	StdDevKm float64 `json:"std_dev_km"`
+	Gini     float64 `json:"gini"`
+}
+
+// PlaceFairnessReport is how far each member is from one of the group's places
+type PlaceFairnessReport struct {
+	PlaceID  string                 `json:"place_id"`
+	Name     string                 `json:"name"`
+	Type     config.PlaceType       `json:"type"`
+	Location Location               `json:"location"`
+	Members  []MemberMidpointReport `json:"members"`
+	Fairness FairnessMetrics        `json:"fairness"`
+}
+
+// StrategyFairnessReport is the midpoint a strategy picks for the group, and how fair it is
+type StrategyFairnessReport struct {
+	Strategy config.MidpointStrategy `json:"strategy"`
+	Midpoint Location                `json:"midpoint"`
+	Fairness FairnessMetrics         `json:"fairness"`
+	// Current is true for the group's own strategy
+	Current bool `json:"current"`
+}
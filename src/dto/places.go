@@ -10,4 +10,60 @@ type Place struct {
 	Type    config.PlaceType `json:"type" validate:"required"`
 	Rating  float64          `json:"rating" validate:"required,min=1.0,max=5.0"`
 	MapURI  string           `json:"map_uri" validate:"required"`
+	// PriceLevel, OpenNow and WheelchairAccessible are left empty if the provider doesn't know them
+	PriceLevel           config.PriceLevel `json:"price_level,omitempty"`
+	OpenNow              *bool             `json:"open_now,omitempty"`
+	WheelchairAccessible *bool             `json:"wheelchair_accessible,omitempty"`
+	// ReviewCount is how many ratings Rating is the average of, 0 if the provider doesn't say
+	ReviewCount int `json:"review_count,omitempty"`
+}
+
+// PlaceTypeResponse is one of the place types groups can search for
+type PlaceTypeResponse struct {
+	ID    config.PlaceType `json:"id"`
+	Label string           `json:"label"`
+	Icon  string           `json:"icon"`
+	// Default types are searched for in groups which haven't chosen their own
+	Default bool `json:"default"`
+}
+
+// PlaceDetails is what the places provider knows about a place beyond its search result
+type PlaceDetails struct {
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Website     string `json:"website,omitempty"`
+	// OpeningHours has a line for each day of the week, as the provider writes them
+	OpeningHours []string          `json:"opening_hours,omitempty"`
+	OpenNow      *bool             `json:"open_now,omitempty"`
+	PriceLevel   config.PriceLevel `json:"price_level,omitempty"`
+	Photos       []PlacePhoto      `json:"photos,omitempty"`
+}
+
+// PlacePhoto is a photo of a place
+// Clients get it from URL, through the API, so the provider's reference (and API key) never reaches them
+type PlacePhoto struct {
+	Reference    string   `json:"-"`
+	URL          string   `json:"url"`
+	Width        int      `json:"width,omitempty"`
+	Height       int      `json:"height,omitempty"`
+	Attributions []string `json:"attributions,omitempty"`
+}
+
+// PlaceSearchRequest is an ad-hoc search for places around a point, by place type, text or both
+type PlaceSearchRequest struct {
+	Location
+	Radius    int              `json:"radius"`
+	PlaceType config.PlaceType `json:"type"`
+	Text      string           `json:"query"`
+	// Page is numbered from 1
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+// PlaceSearchResponse is a page of the places found by an ad-hoc search
+type PlaceSearchResponse struct {
+	Places   []Place `json:"places"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+	Total    int     `json:"total"`
+	HasMore  bool    `json:"has_more"`
 }
@@ -11,8 +11,10 @@ type LoginUserRequest struct {
 	Password string `json:"password"`
 }
 
+// UserUpdateRequest sets the user's location, or the address it is geocoded from
 type UserUpdateRequest struct {
 	Location Location `json:"location"`
+	Address  string   `json:"address,omitempty"`
 }
 
 type UserResponse struct {
@@ -22,3 +24,15 @@ type UserResponse struct {
 	Token       string   `json:"token"`
 	Location    Location `json:"location,omitempty"`
 }
+
+// SavedLocationRequest saves a location of the user under a name, or the address it is geocoded from
+type SavedLocationRequest struct {
+	Location
+	Address string `json:"address,omitempty"`
+}
+
+// SavedLocationResponse is a location the user saved, to join groups from
+type SavedLocationResponse struct {
+	Name string `json:"name"`
+	Location
+}
@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/controllers"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+)
+
+const (
+	// a job which takes longer than this is cancelled and counts as failed
+	jobTimeout = 5 * time.Minute
+	// retries are never put off for longer than this, however many attempts have failed
+	maxRetryBackoff = time.Hour
+)
+
+// Handler does the work of a job, returning an error makes the job retry later
+type Handler func(ctx context.Context, job *models.Job) error
+
+var handlers map[string]Handler = map[string]Handler{}
+var handlersLock sync.RWMutex
+
+// Register sets the handler for a kind of job, before the queue starts
+func Register(kind string, handler Handler) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+	handlers[kind] = handler
+}
+
+func getHandler(kind string) (Handler, bool) {
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+	handler, ok := handlers[kind]
+	return handler, ok
+}
+
+// Queue runs jobs stored in the database with a pool of worker goroutines
+// Queued jobs survive restarts, failed jobs are retried with exponential backoff and end up dead
+// once they run out of attempts.
+type Queue struct {
+	jobsController *controllers.JobsController
+	pollInterval   time.Duration
+	retryBackoff   time.Duration
+
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped sync.Once
+	workers sync.WaitGroup
+	// ctx is cancelled if shutdown runs out of time, to abort the jobs still running
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewQueue(jobsController *controllers.JobsController, pollInterval time.Duration, retryBackoff time.Duration) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		jobsController: jobsController,
+		pollInterval:   pollInterval,
+		retryBackoff:   retryBackoff,
+		wake:           make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+var queue *Queue
+var queueOnce sync.Once
+
+// GetQueue returns the app's job queue, starting its workers the first time
+func GetQueue() *Queue {
+	queueOnce.Do(func() {
+		applogger.Warn("App: Starting", config.JobWorkers, "job workers")
+		jobsController := controllers.CreateJobsController()
+		if err := jobsController.RequeueRunningJobs(); err != nil {
+			applogger.Error("Failed to requeue interrupted jobs", err)
+		}
+		queue = NewQueue(jobsController, config.JobPollInterval, config.JobRetryBackoff)
+		queue.Start(config.JobWorkers)
+	})
+	return queue
+}
+
+// Start runs the workers, which keep going until Shutdown
+func (q *Queue) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+}
+
+// Enqueue adds a job, unless one of the same kind and key is already waiting to run
+func (q *Queue) Enqueue(kind string, key string, payload string) error {
+	if _, err := q.jobsController.EnqueueJob(kind, key, payload); err != nil {
+		return err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// IsPending is true while a job of the kind and key is queued (including waiting for a retry) or running
+func (q *Queue) IsPending(kind string, key string) bool {
+	pending, err := q.jobsController.HasPendingJob(kind, key)
+	if err != nil {
+		applogger.Error("Failed to check for pending jobs", kind, key, err)
+	}
+	return pending
+}
+
+// Shutdown stops the workers from picking up new jobs, and waits for the running ones to finish
+// If ctx ends first, the running jobs are cancelled. Jobs still queued run when the app starts again.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.stopped.Do(func() { close(q.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work() {
+	defer q.workers.Done()
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		job, err := q.jobsController.ClaimNextJob()
+		if err != nil {
+			applogger.Error("Failed to claim job", err)
+		}
+		if job != nil {
+			q.run(job)
+			continue
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+func (q *Queue) run(job *models.Job) {
+	err := func() (err error) {
+		handler, ok := getHandler(job.Kind)
+		if !ok {
+			return fmt.Errorf("no handler for %s jobs", job.Kind)
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		ctx, cancel := context.WithTimeout(q.ctx, jobTimeout)
+		defer cancel()
+		return handler(ctx, job)
+	}()
+
+	if err == nil {
+		err = q.jobsController.CompleteJob(job)
+	} else {
+		err = q.jobsController.FailJob(job, err, RetryBackoff(q.retryBackoff, job.Attempts))
+	}
+	if err != nil {
+		applogger.Error("Failed to update job", job.ID, err)
+	}
+}
+
+// RetryBackoff doubles the wait for each failed attempt, starting from base
+func RetryBackoff(base time.Duration, attempts int) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/controllers"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func startTestQueue(t *testing.T) *Queue {
+	t.Helper()
+	queue := NewQueue(controllers.CreateJobsController(), 5*time.Millisecond, time.Millisecond)
+	queue.Start(2)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.NoError(t, queue.Shutdown(ctx))
+	})
+	return queue
+}
+
+func TestQueue_RetriesFailedJobs(t *testing.T) {
+	queue := startTestQueue(t)
+	var attempts atomic.Int32
+	Register("test_flaky", func(_ context.Context, job *models.Job) error {
+		assert.Equal(t, "payload", job.Payload)
+		if attempts.Add(1) < 3 {
+			return errors.New("flaky")
+		}
+		return nil
+	})
+
+	assert.NoError(t, queue.Enqueue("test_flaky", "key1", "payload"))
+	assert.True(t, queue.IsPending("test_flaky", "key1"))
+	assert.Eventually(t, func() bool { return !queue.IsPending("test_flaky", "key1") }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestQueue_DeadLettersJobs(t *testing.T) {
+	queue := startTestQueue(t)
+	var attempts atomic.Int32
+	Register("test_broken", func(context.Context, *models.Job) error {
+		attempts.Add(1)
+		panic("broken")
+	})
+
+	assert.NoError(t, queue.Enqueue("test_broken", "key1", ""))
+	assert.Eventually(t, func() bool { return !queue.IsPending("test_broken", "key1") }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(config.JobMaxAttempts), attempts.Load())
+
+	dead := lo.Must(controllers.CreateJobsController().ListJobs(config.JobStatusDead))
+	job, found := lo.Find(dead, func(job dto.JobResponse) bool { return job.Kind == "test_broken" })
+	assert.True(t, found)
+	assert.Equal(t, "panic: broken", job.LastError)
+	assert.Equal(t, config.JobMaxAttempts, job.Attempts)
+}
+
+func TestQueue_DeduplicatesQueuedJobs(t *testing.T) {
+	jobsController := controllers.CreateJobsController()
+	first := lo.Must(jobsController.EnqueueJob("test_dedupe", "key1", ""))
+	second := lo.Must(jobsController.EnqueueJob("test_dedupe", "key1", ""))
+	other := lo.Must(jobsController.EnqueueJob("test_dedupe", "key2", ""))
+	assert.Equal(t, first.ID, second.ID)
+	assert.NotEqual(t, first.ID, other.ID)
+}
+
+func TestQueue_RunsOneJobOfAKeyAtATime(t *testing.T) {
+	queue := startTestQueue(t)
+	var running, overlaps, runs atomic.Int32
+	release := make(chan struct{})
+	Register("test_serial", func(context.Context, *models.Job) error {
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer running.Add(-1)
+		if runs.Add(1) == 1 {
+			<-release
+		}
+		return nil
+	})
+
+	assert.NoError(t, queue.Enqueue("test_serial", "key1", ""))
+	assert.Eventually(t, func() bool { return running.Load() == 1 }, time.Second, time.Millisecond)
+	// queued again while the first one runs, the second one waits for it
+	assert.NoError(t, queue.Enqueue("test_serial", "key1", ""))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+
+	close(release)
+	assert.Eventually(t, func() bool { return !queue.IsPending("test_serial", "key1") }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), runs.Load())
+	assert.Zero(t, overlaps.Load())
+}
+
+func TestQueue_ShutdownWaitsForRunningJobs(t *testing.T) {
+	queue := NewQueue(controllers.CreateJobsController(), 5*time.Millisecond, time.Millisecond)
+	queue.Start(1)
+	started := make(chan struct{})
+	var finished atomic.Bool
+	Register("test_slow", func(context.Context, *models.Job) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+
+	assert.NoError(t, queue.Enqueue("test_slow", "key1", ""))
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, queue.Shutdown(ctx))
+	assert.True(t, finished.Load())
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, RetryBackoff(time.Second, 1))
+	assert.Equal(t, 2*time.Second, RetryBackoff(time.Second, 2))
+	assert.Equal(t, 8*time.Second, RetryBackoff(time.Second, 4))
+	assert.Equal(t, maxRetryBackoff, RetryBackoff(time.Second, 100))
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/championswimmer/api.midpoint.place/src/config"
 	"github.com/championswimmer/api.midpoint.place/src/db"
+	"github.com/championswimmer/api.midpoint.place/src/jobs"
 	"github.com/championswimmer/api.midpoint.place/src/server"
 	"github.com/championswimmer/api.midpoint.place/src/services"
 	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
@@ -23,8 +25,8 @@ func main() {
 	// initialize server
 	server := server.CreateServer()
 
-	// initialize place search service
-	placesClient := services.GetGooglePlacesClient()
+	// initialize places provider
+	placesProvider := services.GetPlacesProvider()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM)
@@ -40,9 +42,23 @@ func main() {
 
 		server.ShutdownWithContext(ctx)
 
-		// close places client
-		applogger.Info("Closing places client...")
-		lo.Must0(placesClient.Close())
+		// finish group refreshes still waiting for their debounce delay
+		applogger.Info("Finishing pending group refreshes...")
+		if err := services.GetGroupRefreshScheduler().Shutdown(ctx); err != nil {
+			applogger.Error("Gave up on pending group refreshes", err)
+		}
+
+		// let running jobs finish, queued ones are picked up on the next start
+		applogger.Info("Draining job queue...")
+		if err := jobs.GetQueue().Shutdown(ctx); err != nil {
+			applogger.Error("Cancelled running jobs", err)
+		}
+
+		// close places provider, if it holds a client
+		if closer, ok := placesProvider.(io.Closer); ok {
+			applogger.Info("Closing places provider...")
+			lo.Must0(closer.Close())
+		}
 
 		// shutdown db
 		applogger.Info("Closing db connection...")
@@ -0,0 +1,147 @@
+package routes
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/controllers"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/security"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+)
+
+var adminUsersController *controllers.UsersController
+var adminJobsController *controllers.JobsController
+var adminPlacesUsageController *controllers.PlacesUsageController
+
+func AdminRoute() func(router fiber.Router) {
+	adminUsersController = controllers.CreateUsersController()
+	adminJobsController = controllers.CreateJobsController()
+	adminPlacesUsageController = controllers.CreatePlacesUsageController()
+
+	return func(router fiber.Router) {
+		router.Use(security.MandatoryJwtAuthMiddleware, adminOnlyMiddleware)
+		router.Get("/places-cache", getPlacesCacheStats)
+		router.Get("/places-usage", getPlacesUsage)
+		router.Get("/jobs", listJobs)
+		router.Post("/jobs/:jobId/retry", retryDeadJob)
+	}
+}
+
+// adminOnlyMiddleware only lets through users whose email is in config.AdminEmails
+func adminOnlyMiddleware(ctx *fiber.Ctx) error {
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
+	user, err := adminUsersController.GetUserByID(user.ID)
+	if err != nil || !lo.Contains(config.AdminEmails, user.Email) {
+		return ctx.Status(fiber.StatusForbidden).JSON(dto.CreateErrorResponse(fiber.StatusForbidden, "Only admins can do this"))
+	}
+	return ctx.Next()
+}
+
+// @Summary Get places cache stats
+// @Description Get the hit and miss counters of the place search cache since the app started. Only admins can do this.
+// @Tags admin
+// @ID get-places-cache-stats
+// @Produce json
+// @Success 200 {object} dto.PlacesCacheStats
+// @Failure 403 {object} dto.ErrorResponse "Only admins can do this"
+// @Router /admin/places-cache [get]
+// @Security BearerAuth
+func getPlacesCacheStats(ctx *fiber.Ctx) error {
+	cachedProvider, ok := services.GetPlacesProvider().(*services.CachedPlacesProvider)
+	if !ok {
+		return ctx.Status(fiber.StatusOK).JSON(dto.PlacesCacheStats{Enabled: false})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(cachedProvider.Stats())
+}
+
+// @Summary Get places usage
+// @Description Get how many calls were made to the places provider in a (UTC) day, by operation and for the groups and users which made the most (up to 100 of each), against the daily budgets. Cached results cost nothing and are not counted. Only admins can do this.
+// @Tags admin
+// @ID get-places-usage
+// @Produce json
+// @Param day query string false "Day as YYYY-MM-DD, defaults to today"
+// @Success 200 {object} dto.PlacesUsage
+// @Failure 400 {object} dto.ErrorResponse "Invalid day"
+// @Failure 403 {object} dto.ErrorResponse "Only admins can do this"
+// @Router /admin/places-usage [get]
+// @Security BearerAuth
+func getPlacesUsage(ctx *fiber.Ctx) error {
+	day := ctx.Query("day", time.Now().UTC().Format(time.DateOnly))
+	if _, err := time.Parse(time.DateOnly, day); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid day, it must be YYYY-MM-DD"))
+	}
+
+	metered := services.GetMeteredPlacesProvider()
+	if metered == nil {
+		return ctx.Status(fiber.StatusOK).JSON(dto.PlacesUsage{
+			Day:        day,
+			Operations: map[config.PlacesOperation]int64{},
+			Groups:     []dto.PlacesUsageCount{},
+			Users:      []dto.PlacesUsageCount{},
+		})
+	}
+	usage, err := adminPlacesUsageController.GetPlacesUsage(day)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	usage.Metered = true
+	usage.Budget = config.PlacesDailyBudget
+	usage.GroupBudget = config.PlacesGroupDailyBudget
+	usage.UserBudget = config.PlacesUserDailyBudget
+	usage.BreakerOpen = metered.BreakerOpen()
+	return ctx.Status(fiber.StatusOK).JSON(usage)
+}
+
+// @Summary List background jobs
+// @Description List background jobs with a status, oldest first, limited to 100 results. Dead jobs failed too many times and are not retried unless an admin asks for it. Only admins can do this.
+// @Tags admin
+// @ID list-jobs
+// @Produce json
+// @Param status query string false "Job status, defaults to dead" Enums(queued,running,dead)
+// @Success 200 {array} dto.JobResponse
+// @Failure 403 {object} dto.ErrorResponse "Only admins can do this"
+// @Failure 422 {object} dto.ErrorResponse "Invalid job status"
+// @Router /admin/jobs [get]
+// @Security BearerAuth
+func listJobs(ctx *fiber.Ctx) error {
+	status := config.JobStatus(ctx.Query("status", string(config.JobStatusDead)))
+	if !config.IsSupportedJobStatus(status) {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(dto.CreateErrorResponse(fiber.StatusUnprocessableEntity, "Invalid job status"))
+	}
+
+	jobs, err := adminJobsController.ListJobs(status)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	return ctx.Status(fiber.StatusOK).JSON(jobs)
+}
+
+// @Summary Retry a dead job
+// @Description Queue a dead job again, with a fresh set of attempts. Only admins can do this.
+// @Tags admin
+// @ID retry-dead-job
+// @Produce json
+// @Param jobId path int true "Job ID"
+// @Success 202 {object} dto.JobResponse
+// @Failure 403 {object} dto.ErrorResponse "Only admins can do this"
+// @Failure 404 {object} dto.ErrorResponse "Dead job not found"
+// @Failure 400 {object} dto.ErrorResponse "Invalid job ID"
+// @Router /admin/jobs/{jobId}/retry [post]
+// @Security BearerAuth
+func retryDeadJob(ctx *fiber.Ctx) error {
+	jobID, err := strconv.ParseUint(ctx.Params("jobId"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid job ID"))
+	}
+
+	job, err := adminJobsController.RetryDeadJob(uint(jobID))
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	return ctx.Status(fiber.StatusAccepted).JSON(job)
+}
@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"net/url"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/server/parsers"
+	"github.com/championswimmer/api.midpoint.place/src/server/validators"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+)
+
+// @Summary List group places
+// @Description List the places of the group (or of one of its clusters) with the members' votes on them, and their ranking score. Sorting by votes orders places by vote score, which is their Borda count from the members' ranked ballots plus upvotes minus downvotes. Sorting by score orders them by the ranking score, whose breakdown explains how rating, review count, distances to the midpoint and members, and votes counted towards it. Distances in the breakdown are rounded to the approximate location grid unless all the members share their exact location.
+// @Tags groups
+// @ID list-group-places
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param sort query string false "Sort order, defaults to score" Enums(score,travel_time,votes)
+// @Param cluster query int false "Cluster number, defaults to 0 for the whole group"
+// @Success 200 {array} dto.GroupPlaceResponse
+// @Failure 404 {object} dto.ErrorResponse "Group or cluster not found"
+// @Failure 422 {object} dto.ErrorResponse "Invalid sort order"
+// @Failure 500 {object} dto.ErrorResponse "Failed to fetch group places"
+// @Router /groups/{groupIdOrCode}/places [get]
+// @Security BearerAuth
+func getGroupPlaces(ctx *fiber.Ctx) error {
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
+	groupIDOrCode := ctx.Params("groupIdOrCode")
+	placesSort := config.PlacesSort(ctx.Query("sort", string(config.PlacesSortScore)))
+	if placesSort != config.PlacesSortScore && placesSort != config.PlacesSortTravelTime && placesSort != config.PlacesSortVotes {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(dto.CreateErrorResponse(fiber.StatusUnprocessableEntity, "Sort must be one of score, travel_time or votes"))
+	}
+	clusterNumber := ctx.QueryInt("cluster", 0)
+
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), groupIDOrCode, user.ID, false, true)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	places := group.Places
+	if clusterNumber != 0 {
+		cluster, ok := lo.Find(group.Clusters, func(cluster dto.GroupClusterResponse) bool { return cluster.Number == clusterNumber })
+		if !ok {
+			return ctx.Status(fiber.StatusNotFound).JSON(dto.CreateErrorResponse(fiber.StatusNotFound, "Cluster not found"))
+		}
+		places = cluster.Places
+	}
+
+	places, err = groupPlacesController.AddVotesToPlaces(group.ID, user.ID, lo.Ternary(places == nil, []dto.GroupPlaceResponse{}, places), placesSort)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	places, err = groupPlacesController.AddScoresToPlaces(group.ID, clusterNumber, places, placesSort)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(places)
+}
+
+// @Summary Vote on a group place
+// @Description Upvote or downvote one of the group's places, or take the vote back with none. Only group members can do this. Votes are kept when the group's places are refreshed and the same place comes back.
+// @Tags groups
+// @ID vote-group-place
+// @Accept json
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param placeId path string true "Place ID (URL encoded)"
+// @Param vote body dto.GroupPlaceVoteRequest true "Vote"
+// @Success 200 {object} dto.GroupPlaceVotes
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "Only group members can vote on places"
+// @Failure 404 {object} dto.ErrorResponse "Group or place not found"
+// @Failure 422 {object} dto.ErrorResponse "Vote validation failed"
+// @Failure 500 {object} dto.ErrorResponse "Failed to save vote"
+// @Router /groups/{groupIdOrCode}/places/{placeId}/vote [put]
+// @Security BearerAuth
+func voteGroupPlace(ctx *fiber.Ctx) error {
+	placeID, err := url.PathUnescape(ctx.Params("placeId"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid place ID"))
+	}
+
+	group, user, memberErr := _groupMemberFromCtx(ctx, "Only group members can vote on places")
+	if memberErr != nil {
+		return ctx.Status(memberErr.Code).JSON(dto.CreateErrorResponse(memberErr.Code, memberErr.Error()))
+	}
+
+	req, parseError := parsers.ParseBody[dto.GroupPlaceVoteRequest](ctx)
+	if parseError != nil {
+		return parsers.SendParsingError(ctx, parseError)
+	}
+	validateErr := validators.ValidateGroupPlaceVoteRequest(req)
+	if validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	votes, err := groupPlacesController.VoteForGroupPlace(group.ID, user.ID, placeID, req.Vote)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(votes)
+}
+
+// @Summary Rank group places
+// @Description Replace your ranked ballot with the given places, most preferred first. An empty list takes the ballot back. Only group members can do this.
+// @Tags groups
+// @ID rank-group-places
+// @Accept json
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param ranking body dto.GroupPlaceRankingRequest true "Ranked place IDs"
+// @Success 200 {array} dto.GroupPlaceResponse "Group places sorted by votes"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "Only group members can rank places"
+// @Failure 404 {object} dto.ErrorResponse "Group not found"
+// @Failure 422 {object} dto.ErrorResponse "Only places of the group can be ranked"
+// @Failure 500 {object} dto.ErrorResponse "Failed to save ranking"
+// @Router /groups/{groupIdOrCode}/places/ranking [put]
+// @Security BearerAuth
+func rankGroupPlaces(ctx *fiber.Ctx) error {
+	group, user, memberErr := _groupMemberFromCtx(ctx, "Only group members can rank places")
+	if memberErr != nil {
+		return ctx.Status(memberErr.Code).JSON(dto.CreateErrorResponse(memberErr.Code, memberErr.Error()))
+	}
+
+	req, parseError := parsers.ParseBody[dto.GroupPlaceRankingRequest](ctx)
+	if parseError != nil {
+		return parsers.SendParsingError(ctx, parseError)
+	}
+	validateErr := validators.ValidateGroupPlaceRankingRequest(req)
+	if validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	if err := groupPlacesController.RankGroupPlaces(group.ID, user.ID, req.PlaceIDs); err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), group.ID, user.ID, false, true)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	places, err := groupPlacesController.AddVotesToPlaces(group.ID, user.ID, lo.Ternary(group.Places == nil, []dto.GroupPlaceResponse{}, group.Places), config.PlacesSortVotes)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(places)
+}
+
+// _groupMemberFromCtx finds the group in the path, and checks that the user is one of its members
+func _groupMemberFromCtx(ctx *fiber.Ctx, forbiddenMessage string) (*dto.GroupResponse, *models.User, *fiber.Error) {
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), ctx.Params("groupIdOrCode"), user.ID, false, false)
+	if err != nil {
+		return nil, nil, err.(*fiber.Error)
+	}
+
+	isMember, err := groupUsersController.GroupMembershipCheck(group.ID, user.ID)
+	if err != nil {
+		return nil, nil, err.(*fiber.Error)
+	}
+	if !isMember {
+		return nil, nil, fiber.NewError(fiber.StatusForbidden, forbiddenMessage)
+	}
+	return group, user, nil
+}
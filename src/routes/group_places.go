@@ -0,0 +1,226 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/server/parsers"
+	"github.com/championswimmer/api.midpoint.place/src/server/validators"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+)
+
+// photos are scaled down to this width, unless the client asks for another one up to placePhotoMaxWidth
+const placePhotoDefaultWidth = 800
+const placePhotoMaxWidth = 4800
+
+// @Summary Add a custom place
+// @Description Add a place of a member's own to the group, such as a friend's apartment or a place the places provider does not know. Custom places are listed with source custom, and are kept when the group's places are refreshed.
+// @Tags groups
+// @ID add-group-custom-place
+// @Accept json
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param place body dto.CustomPlaceAddRequest true "Custom place"
+// @Success 201 {object} dto.GroupPlaceResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "Only group members can add places"
+// @Failure 404 {object} dto.ErrorResponse "Group not found"
+// @Failure 422 {object} dto.ErrorResponse "Place validation failed"
+// @Failure 500 {object} dto.ErrorResponse "Failed to add custom place to group"
+// @Router /groups/{groupIdOrCode}/places [post]
+// @Security BearerAuth
+func addGroupCustomPlace(ctx *fiber.Ctx) error {
+	group, user, memberErr := _groupMemberFromCtx(ctx, "Only group members can add places")
+	if memberErr != nil {
+		return ctx.Status(memberErr.Code).JSON(dto.CreateErrorResponse(memberErr.Code, memberErr.Error()))
+	}
+
+	req, parseError := parsers.ParseBody[dto.CustomPlaceAddRequest](ctx)
+	if parseError != nil {
+		return parsers.SendParsingError(ctx, parseError)
+	}
+	validateErr := validators.ValidateCustomPlaceAddRequest(req)
+	if validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	place, err := groupPlacesController.AddCustomPlace(group.ID, user.ID, req)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(place)
+}
+
+// @Summary Remove a custom place
+// @Description Remove a custom place from the group. Members can remove the places they added, group admins can remove any custom place.
+// @Tags groups
+// @ID remove-group-custom-place
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param placeId path string true "Place ID"
+// @Success 204
+// @Failure 403 {object} dto.ErrorResponse "Only the member who added a place or group admins can remove it"
+// @Failure 404 {object} dto.ErrorResponse "Group or custom place not found"
+// @Failure 500 {object} dto.ErrorResponse "Failed to remove custom place from group"
+// @Router /groups/{groupIdOrCode}/places/{placeId} [delete]
+// @Security BearerAuth
+func removeGroupCustomPlace(ctx *fiber.Ctx) error {
+	group, user, memberErr := _groupMemberFromCtx(ctx, "Only group members can remove places")
+	if memberErr != nil {
+		return ctx.Status(memberErr.Code).JSON(dto.CreateErrorResponse(memberErr.Code, memberErr.Error()))
+	}
+	placeID, err := url.PathUnescape(ctx.Params("placeId"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid place ID"))
+	}
+
+	isAdmin, err := groupUsersController.IsGroupAdmin(group.ID, user.ID)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	if err := groupPlacesController.RemoveCustomPlace(group.ID, user.ID, placeID, isAdmin); err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Get place details
+// @Description Get a place of the group, with its opening hours, phone number, website, price level and photos, as far as the places provider knows them. Details are cached for a while. Photo URLs point to this API, which fetches them from the provider. Custom places have no details.
+// @Tags groups
+// @ID get-group-place
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param placeId path string true "Place ID"
+// @Success 200 {object} dto.GroupPlaceDetailsResponse
+// @Failure 403 {object} dto.ErrorResponse "Only group members can see places"
+// @Failure 404 {object} dto.ErrorResponse "Group or place not found"
+// @Failure 502 {object} dto.ErrorResponse "Failed to fetch place details"
+// @Failure 503 {object} dto.ErrorResponse "Places budget used up"
+// @Router /groups/{groupIdOrCode}/places/{placeId} [get]
+// @Security BearerAuth
+func getGroupPlace(ctx *fiber.Ctx) error {
+	group, user, memberErr := _groupMemberFromCtx(ctx, "Only group members can see places")
+	if memberErr != nil {
+		return ctx.Status(memberErr.Code).JSON(dto.CreateErrorResponse(memberErr.Code, memberErr.Error()))
+	}
+	placeID, err := url.PathUnescape(ctx.Params("placeId"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid place ID"))
+	}
+
+	place, err := groupPlacesController.GetGroupPlace(group.ID, placeID)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	placesCtx := services.WithPlacesCaller(ctx.Context(), services.PlacesCaller{GroupID: group.ID, UserID: user.ID})
+	details, err := _groupPlaceDetails(placesCtx, place)
+	if errors.Is(err, services.ErrPlacesBudgetExceeded) {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(dto.CreateErrorResponse(fiber.StatusServiceUnavailable, "Places budget used up, try again tomorrow"))
+	}
+	if err != nil {
+		return ctx.Status(fiber.StatusBadGateway).JSON(dto.CreateErrorResponse(fiber.StatusBadGateway, "Failed to fetch place details"))
+	}
+
+	resp := dto.GroupPlaceDetailsResponse{GroupPlaceResponse: *place}
+	if details != nil {
+		resp.PlaceDetails = *details
+		// the details are shared through the cache, so photos get URLs on a copy
+		resp.Photos = lo.Map(details.Photos, func(photo dto.PlacePhoto, i int) dto.PlacePhoto {
+			photo.URL = fmt.Sprintf("/v1/groups/%s/places/%s/photos/%d", group.ID, url.PathEscape(place.PlaceID), i)
+			return photo
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+// @Summary Get place photo
+// @Description Get one of the photos of a place of the group, numbered from 0 in the order of its details. The image is fetched from the places provider, so clients never need its API key.
+// @Tags groups
+// @ID get-group-place-photo
+// @Produce image/jpeg,image/png,image/svg+xml
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param placeId path string true "Place ID"
+// @Param photoIndex path int true "Photo index"
+// @Param max_width query int false "Maximum width in pixels, defaults to 800"
+// @Success 200 {file} binary
+// @Failure 403 {object} dto.ErrorResponse "Only group members can see places"
+// @Failure 404 {object} dto.ErrorResponse "Group, place or photo not found"
+// @Failure 422 {object} dto.ErrorResponse "Invalid max_width"
+// @Failure 502 {object} dto.ErrorResponse "Failed to fetch place photo"
+// @Failure 503 {object} dto.ErrorResponse "Places budget used up"
+// @Router /groups/{groupIdOrCode}/places/{placeId}/photos/{photoIndex} [get]
+// @Security BearerAuth
+func getGroupPlacePhoto(ctx *fiber.Ctx) error {
+	group, user, memberErr := _groupMemberFromCtx(ctx, "Only group members can see places")
+	if memberErr != nil {
+		return ctx.Status(memberErr.Code).JSON(dto.CreateErrorResponse(memberErr.Code, memberErr.Error()))
+	}
+	placeID, err := url.PathUnescape(ctx.Params("placeId"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid place ID"))
+	}
+	photoIndex, err := ctx.ParamsInt("photoIndex")
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(dto.CreateErrorResponse(fiber.StatusNotFound, "Photo not found"))
+	}
+	maxWidth := ctx.QueryInt("max_width", placePhotoDefaultWidth)
+	if maxWidth < 1 || maxWidth > placePhotoMaxWidth {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(dto.CreateErrorResponse(fiber.StatusUnprocessableEntity, fmt.Sprintf("max_width must be between 1 and %d", placePhotoMaxWidth)))
+	}
+
+	place, err := groupPlacesController.GetGroupPlace(group.ID, placeID)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	placesCtx := services.WithPlacesCaller(ctx.Context(), services.PlacesCaller{GroupID: group.ID, UserID: user.ID})
+	details, err := _groupPlaceDetails(placesCtx, place)
+	if errors.Is(err, services.ErrPlacesBudgetExceeded) {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(dto.CreateErrorResponse(fiber.StatusServiceUnavailable, "Places budget used up, try again tomorrow"))
+	}
+	if err != nil {
+		return ctx.Status(fiber.StatusBadGateway).JSON(dto.CreateErrorResponse(fiber.StatusBadGateway, "Failed to fetch place details"))
+	}
+	if details == nil || photoIndex < 0 || photoIndex >= len(details.Photos) {
+		return ctx.Status(fiber.StatusNotFound).JSON(dto.CreateErrorResponse(fiber.StatusNotFound, "Photo not found"))
+	}
+
+	photoCtx, cancel := context.WithTimeout(placesCtx, config.PlacesSearchTimeout)
+	defer cancel()
+	photo, err := placeDetailsProvider.PlacePhoto(photoCtx, details.Photos[photoIndex], maxWidth)
+	if errors.Is(err, services.ErrPlaceNotFound) {
+		return ctx.Status(fiber.StatusNotFound).JSON(dto.CreateErrorResponse(fiber.StatusNotFound, "Photo not found"))
+	}
+	if errors.Is(err, services.ErrPlacesBudgetExceeded) {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(dto.CreateErrorResponse(fiber.StatusServiceUnavailable, "Places budget used up, try again tomorrow"))
+	}
+	if err != nil {
+		return ctx.Status(fiber.StatusBadGateway).JSON(dto.CreateErrorResponse(fiber.StatusBadGateway, "Failed to fetch place photo"))
+	}
+
+	ctx.Set(fiber.HeaderContentType, photo.ContentType)
+	ctx.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	return ctx.Status(fiber.StatusOK).Send(photo.Data)
+}
+
+// _groupPlaceDetails looks up the details of a group place through the places provider
+// They are nil for custom places, if the provider has no details, or no longer knows the place
+func _groupPlaceDetails(ctx context.Context, place *dto.GroupPlaceResponse) (*dto.PlaceDetails, error) {
+	if place.Source == config.PlaceSourceCustom || placeDetailsProvider == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.PlacesSearchTimeout)
+	defer cancel()
+	details, err := placeDetailsProvider.PlaceDetails(ctx, place.PlaceID)
+	if errors.Is(err, services.ErrPlaceNotFound) {
+		return nil, nil
+	}
+	return details, err
+}
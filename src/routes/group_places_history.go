@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary List group places history
+// @Description List the snapshots of the group's places, newest first. Each refresh of the group saves its midpoint and the places found around it, and restoring a snapshot saves a snapshot too. Only the latest snapshots are kept. Places are left out, get a snapshot to see them.
+// @Tags groups
+// @ID list-group-places-history
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Success 200 {array} dto.GroupPlacesSnapshotResponse
+// @Failure 403 {object} dto.ErrorResponse "Only group members can see places"
+// @Failure 404 {object} dto.ErrorResponse "Group not found"
+// @Router /groups/{groupIdOrCode}/places/history [get]
+// @Security BearerAuth
+func listGroupPlacesHistory(ctx *fiber.Ctx) error {
+	group, _, memberErr := _groupMemberFromCtx(ctx, "Only group members can see places")
+	if memberErr != nil {
+		return ctx.Status(memberErr.Code).JSON(dto.CreateErrorResponse(memberErr.Code, memberErr.Error()))
+	}
+
+	snapshots, err := groupPlacesController.ListGroupPlacesSnapshots(group.ID)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	return ctx.Status(fiber.StatusOK).JSON(snapshots)
+}
+
+// @Summary Get group places snapshot
+// @Description Get a snapshot of the group's places, with the midpoint they were found around
+// @Tags groups
+// @ID get-group-places-snapshot
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param snapshotId path int true "Snapshot ID"
+// @Success 200 {object} dto.GroupPlacesSnapshotResponse
+// @Failure 403 {object} dto.ErrorResponse "Only group members can see places"
+// @Failure 404 {object} dto.ErrorResponse "Group or snapshot not found"
+// @Router /groups/{groupIdOrCode}/places/history/{snapshotId} [get]
+// @Security BearerAuth
+func getGroupPlacesSnapshot(ctx *fiber.Ctx) error {
+	group, _, memberErr := _groupMemberFromCtx(ctx, "Only group members can see places")
+	if memberErr != nil {
+		return ctx.Status(memberErr.Code).JSON(dto.CreateErrorResponse(memberErr.Code, memberErr.Error()))
+	}
+	snapshotID, err := ctx.ParamsInt("snapshotId")
+	if err != nil || snapshotID < 1 {
+		return ctx.Status(fiber.StatusNotFound).JSON(dto.CreateErrorResponse(fiber.StatusNotFound, "Snapshot not found"))
+	}
+
+	snapshot, err := groupPlacesController.GetGroupPlacesSnapshot(group.ID, uint(snapshotID))
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	return ctx.Status(fiber.StatusOK).JSON(snapshot)
+}
+
+// @Summary Restore group places snapshot
+// @Description Bring back the midpoint and places of a snapshot, in place of the group's current ones. Custom places are kept. The restored places stay until the group is refreshed again, e.g. when members join, leave or move. Only group admins can do this, and not while a venue is chosen.
+// @Tags groups
+// @ID restore-group-places-snapshot
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param snapshotId path int true "Snapshot ID"
+// @Success 200 {object} dto.GroupResponse
+// @Failure 403 {object} dto.ErrorResponse "Only group admins can restore places"
+// @Failure 404 {object} dto.ErrorResponse "Group or snapshot not found"
+// @Failure 409 {object} dto.ErrorResponse "Places can't be restored while a venue is chosen"
+// @Failure 500 {object} dto.ErrorResponse "Failed to restore places"
+// @Router /groups/{groupIdOrCode}/places/history/{snapshotId}/restore [post]
+// @Security BearerAuth
+func restoreGroupPlacesSnapshot(ctx *fiber.Ctx) error {
+	group, user, adminErr := _groupAdminFromCtx(ctx, "Only group admins can restore places")
+	if adminErr != nil {
+		return ctx.Status(adminErr.Code).JSON(dto.CreateErrorResponse(adminErr.Code, adminErr.Error()))
+	}
+	snapshotID, err := ctx.ParamsInt("snapshotId")
+	if err != nil || snapshotID < 1 {
+		return ctx.Status(fiber.StatusNotFound).JSON(dto.CreateErrorResponse(fiber.StatusNotFound, "Snapshot not found"))
+	}
+
+	if err := groupPlacesController.RestoreGroupPlacesSnapshot(group.ID, uint(snapshotID)); err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	group, err = groupsController.GetGroupByIDorCode(ctx.Context(), group.ID, user.ID, false, true)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	group.RefreshPending = _isGroupRefreshPending(group.ID)
+
+	return ctx.Status(fiber.StatusOK).JSON(group)
+}
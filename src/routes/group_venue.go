@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/server/parsers"
+	"github.com/championswimmer/api.midpoint.place/src/server/validators"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Choose group venue
+// @Description Choose the meeting place of the group, either one of its places by place_id or a custom place. Places are no longer refreshed while a venue is chosen.
+// @Tags groups
+// @ID set-group-venue
+// @Accept json
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param venue body dto.GroupVenueRequest true "Venue"
+// @Success 200 {object} dto.GroupResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "Only group admins can choose the venue"
+// @Failure 404 {object} dto.ErrorResponse "Group or place not found"
+// @Failure 422 {object} dto.ErrorResponse "Venue validation failed"
+// @Failure 500 {object} dto.ErrorResponse "Failed to choose venue"
+// @Router /groups/{groupIdOrCode}/venue [put]
+// @Security BearerAuth
+func setGroupVenue(ctx *fiber.Ctx) error {
+	group, user, adminErr := _groupAdminFromCtx(ctx, "Only group admins can choose the venue")
+	if adminErr != nil {
+		return ctx.Status(adminErr.Code).JSON(dto.CreateErrorResponse(adminErr.Code, adminErr.Error()))
+	}
+
+	req, parseError := parsers.ParseBody[dto.GroupVenueRequest](ctx)
+	if parseError != nil {
+		return parsers.SendParsingError(ctx, parseError)
+	}
+	validateErr := validators.ValidateGroupVenueRequest(req)
+	if validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	group, err := groupsController.SetGroupVenue(group.ID, user.ID, req)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	group.RefreshPending = _isGroupRefreshPending(group.ID)
+
+	return ctx.Status(fiber.StatusOK).JSON(group)
+}
+
+// @Summary Clear group venue
+// @Description Undo the choice of venue, so places of the group are suggested and refreshed again
+// @Tags groups
+// @ID clear-group-venue
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Success 202 {object} dto.GroupResponse
+// @Failure 403 {object} dto.ErrorResponse "Only group admins can clear the venue"
+// @Failure 404 {object} dto.ErrorResponse "Group not found"
+// @Failure 500 {object} dto.ErrorResponse "Failed to clear venue"
+// @Router /groups/{groupIdOrCode}/venue [delete]
+// @Security BearerAuth
+func clearGroupVenue(ctx *fiber.Ctx) error {
+	group, _, adminErr := _groupAdminFromCtx(ctx, "Only group admins can clear the venue")
+	if adminErr != nil {
+		return ctx.Status(adminErr.Code).JSON(dto.CreateErrorResponse(adminErr.Code, adminErr.Error()))
+	}
+
+	group, err := groupsController.ClearGroupVenue(group.ID)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	// places may be stale after being kept while the venue was chosen
+	_scheduleGroupMidpointUpdate(group)
+	group.RefreshPending = _isGroupRefreshPending(group.ID)
+
+	return ctx.Status(fiber.StatusAccepted).JSON(group)
+}
+
+// _groupAdminFromCtx finds the group in the path, and checks that the user is one of its admins
+func _groupAdminFromCtx(ctx *fiber.Ctx, forbiddenMessage string) (*dto.GroupResponse, *models.User, *fiber.Error) {
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), ctx.Params("groupIdOrCode"), user.ID, false, false)
+	if err != nil {
+		return nil, nil, err.(*fiber.Error)
+	}
+
+	isAdmin, err := groupUsersController.IsGroupAdmin(group.ID, user.ID)
+	if err != nil {
+		return nil, nil, err.(*fiber.Error)
+	}
+	if !isAdmin {
+		return nil, nil, fiber.NewError(fiber.StatusForbidden, forbiddenMessage)
+	}
+	return group, user, nil
+}
@@ -1,10 +1,15 @@
 package routes
 
 import (
+	"context"
+	"errors"
+	"strconv"
+
 	"github.com/championswimmer/api.midpoint.place/src/config"
 	"github.com/championswimmer/api.midpoint.place/src/controllers"
 	"github.com/championswimmer/api.midpoint.place/src/db/models"
 	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/jobs"
 	"github.com/championswimmer/api.midpoint.place/src/security"
 	"github.com/championswimmer/api.midpoint.place/src/security/ratelimit"
 	"github.com/championswimmer/api.midpoint.place/src/server/parsers"
@@ -12,26 +17,52 @@ import (
 	"github.com/championswimmer/api.midpoint.place/src/services"
 	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
 	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
 )
 
 var groupsController *controllers.GroupsController
 var groupUsersController *controllers.GroupUsersController
 var groupPlacesController *controllers.GroupPlacesController
-var placesSearchService *services.PlaceSearchService
+var placesProvider services.PlacesProvider
+var placeDetailsProvider services.PlaceDetailsProvider
+var groupRefreshScheduler *services.GroupRefreshScheduler
+var jobQueue *jobs.Queue
+var placesSearchSemaphore *semaphore.Weighted
 
 func GroupsRoute() func(router fiber.Router) {
 	groupsController = controllers.CreateGroupsController()
 	groupUsersController = controllers.CreateGroupUsersController()
 	groupPlacesController = controllers.CreateGroupPlacesController()
-	placesSearchService = services.NewPlaceSearchService()
+	placesProvider = services.GetPlacesProvider()
+	placeDetailsProvider = services.GetPlaceDetailsProvider()
+	placesSearchSemaphore = semaphore.NewWeighted(int64(config.PlacesSearchConcurrency))
+	groupRefreshScheduler = services.GetGroupRefreshScheduler()
+	jobs.Register(config.JobKindGroupRefresh, _runGroupRefreshJob)
+	jobQueue = jobs.GetQueue()
 
 	return func(router fiber.Router) {
 		router.Get("/", security.MandatoryJwtAuthMiddleware, listPublicGroups)
 		router.Post("/", security.MandatoryJwtAuthMiddleware, ratelimit.GroupCreateRateLimiter(), createGroup)
 		router.Get("/:groupIdOrCode", security.MandatoryJwtAuthMiddleware, getGroup)
+		router.Get("/:groupIdOrCode/midpoint/report", security.MandatoryJwtAuthMiddleware, getGroupMidpointReport)
 		router.Patch("/:groupIdOrCode", security.MandatoryJwtAuthMiddleware, updateGroup)
-		router.Put("/:groupIdOrCode/join", security.MandatoryJwtAuthMiddleware, joinGroup)
+		router.Put("/:groupIdOrCode/join", security.MandatoryJwtAuthMiddleware, ratelimit.GeocodeRateLimiter(), joinGroup)
 		router.Delete("/:groupIdOrCode/join", security.MandatoryJwtAuthMiddleware, leaveGroup)
+		router.Patch("/:groupIdOrCode/members/:userId", security.MandatoryJwtAuthMiddleware, updateGroupMember)
+		router.Get("/:groupIdOrCode/places", security.MandatoryJwtAuthMiddleware, getGroupPlaces)
+		router.Post("/:groupIdOrCode/places", security.MandatoryJwtAuthMiddleware, addGroupCustomPlace)
+		router.Put("/:groupIdOrCode/places/ranking", security.MandatoryJwtAuthMiddleware, rankGroupPlaces)
+		router.Get("/:groupIdOrCode/places/history", security.MandatoryJwtAuthMiddleware, listGroupPlacesHistory)
+		router.Get("/:groupIdOrCode/places/history/:snapshotId", security.MandatoryJwtAuthMiddleware, getGroupPlacesSnapshot)
+		router.Post("/:groupIdOrCode/places/history/:snapshotId/restore", security.MandatoryJwtAuthMiddleware, restoreGroupPlacesSnapshot)
+		router.Put("/:groupIdOrCode/places/:placeId/vote", security.MandatoryJwtAuthMiddleware, voteGroupPlace)
+		router.Get("/:groupIdOrCode/places/:placeId", security.MandatoryJwtAuthMiddleware, getGroupPlace)
+		router.Get("/:groupIdOrCode/places/:placeId/photos/:photoIndex", security.MandatoryJwtAuthMiddleware, getGroupPlacePhoto)
+		router.Delete("/:groupIdOrCode/places/:placeId", security.MandatoryJwtAuthMiddleware, removeGroupCustomPlace)
+		router.Put("/:groupIdOrCode/venue", security.MandatoryJwtAuthMiddleware, setGroupVenue)
+		router.Delete("/:groupIdOrCode/venue", security.MandatoryJwtAuthMiddleware, clearGroupVenue)
 	}
 }
 
@@ -64,6 +95,9 @@ func listPublicGroups(ctx *fiber.Ctx) error {
 	if err != nil {
 		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
 	}
+	for i := range groups {
+		groups[i].Members = services.MaskMemberLocations(groups[i].Members, user.ID)
+	}
 
 	return ctx.Status(fiber.StatusOK).JSON(groups)
 }
@@ -78,15 +112,17 @@ func listPublicGroups(ctx *fiber.Ctx) error {
 // @Param group body dto.UpdateGroupRequest true "Group Update Data"
 // @Success 200 {object} dto.GroupResponse "Group updated successfully"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "Only group admins can change clustering"
 // @Failure 404 {object} dto.ErrorResponse "Group not found"
 // @Failure 422 {object} dto.ErrorResponse "Group info validation failed"
 // @Failure 500 {object} dto.ErrorResponse "Failed to update group"
 // @Router /groups/{groupIdOrCode} [patch]
 // @Security BearerAuth
 func updateGroup(ctx *fiber.Ctx) error {
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
 	groupID := ctx.Params("groupIdOrCode")
 
-	group, err := groupsController.GetGroupByIDorCode(groupID, false, false)
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), groupID, user.ID, false, false)
 	if err != nil {
 		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
 	}
@@ -101,19 +137,32 @@ func updateGroup(ctx *fiber.Ctx) error {
 		return validators.SendValidationError(ctx, validateErr)
 	}
 
+	if req.HasClusterSettings() {
+		isAdmin, err := groupUsersController.IsGroupAdmin(group.ID, user.ID)
+		if err != nil {
+			return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+		}
+		if !isAdmin {
+			return ctx.Status(fiber.StatusForbidden).JSON(dto.CreateErrorResponse(fiber.StatusForbidden, "Only group admins can change clustering"))
+		}
+	}
+
 	group, err = groupsController.UpdateGroup(group.ID, req)
 	if err != nil {
 		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
 	}
-	if req.PlaceTypes != nil {
-		_triggerGroupMidpointUpdate(group)
+	if req.PlaceTypes != nil || req.HasClusterSettings() || req.SearchPreferences != nil {
+		_scheduleGroupMidpointUpdate(group)
 	}
+	group.RefreshPending = _isGroupRefreshPending(group.ID)
 
 	return ctx.Status(fiber.StatusAccepted).JSON(group)
 }
 
 // @Summary Join a group
-// @Description Join an existing group
+// @Description Join an existing group, from a location, an address to geocode or one of the user's saved locations.
+// @Description Joining again updates the membership, e.g. to switch to another saved location.
+// @Description The location privacy decides how the member's location is shown to the other members: exact, approximate (the default, snapped to a 500 m grid) or hidden.
 // @Tags groups
 // @ID join-group
 // @Produce json
@@ -121,15 +170,19 @@ func updateGroup(ctx *fiber.Ctx) error {
 // @Param groupUser body dto.GroupUserJoinRequest true "Group User"
 // @Success 200 {object} dto.GroupUserResponse
 // @Failure 400 {object} dto.ErrorResponse "Invalid request"
-// @Failure 404 {object} dto.ErrorResponse "Group not found"
+// @Failure 404 {object} dto.ErrorResponse "Group or saved location not found"
+// @Failure 422 {object} dto.ErrorResponse "Address not found"
+// @Failure 429 {object} dto.ErrorResponse "Too many addresses to geocode"
 // @Failure 500 {object} dto.ErrorResponse "Failed to join group"
+// @Failure 501 {object} dto.ErrorResponse "Addresses are not supported"
+// @Failure 502 {object} dto.ErrorResponse "Failed to geocode address"
 // @Router /groups/{groupIdOrCode}/join [put]
 // @Security BearerAuth
 func joinGroup(ctx *fiber.Ctx) error {
 	user := ctx.Locals(config.LOCALS_USER).(*models.User)
 	groupIDOrCode := ctx.Params("groupIdOrCode")
 
-	group, err := groupsController.GetGroupByIDorCode(groupIDOrCode, false, false)
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), groupIDOrCode, user.ID, false, false)
 	if err != nil {
 		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
 	}
@@ -139,12 +192,33 @@ func joinGroup(ctx *fiber.Ctx) error {
 		return parsers.SendParsingError(ctx, parseError)
 	}
 
+	validateErr := validators.ValidateGroupUserJoinRequest(groupUserReq)
+	if validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	if groupUserReq.Address != "" {
+		location, err := _geocodeAddress(ctx.Context(), groupUserReq.Address)
+		if err != nil {
+			return ctx.Status(err.Code).JSON(dto.CreateErrorResponse(err.Code, err.Error()))
+		}
+		groupUserReq.Location = *location
+	}
+	if groupUserReq.SavedLocation != "" {
+		location, err := usersController.GetSavedLocation(user.ID, groupUserReq.SavedLocation)
+		if err != nil {
+			return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+		}
+		groupUserReq.Location = *location
+	}
+
 	if group.MemberCount > 0 {
+		midpoint, err := groupsController.GetGroupMidpoint(group.ID)
+		if err != nil {
+			return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+		}
 		// Validate if user is within max group join bounds
-		validateErr := validators.ValidateLocationProximity(dto.Location{
-			Latitude:  group.MidpointLatitude,
-			Longitude: group.MidpointLongitude,
-		}, dto.Location{
+		validateErr := validators.ValidateLocationProximity(midpoint, dto.Location{
 			Latitude:  groupUserReq.Latitude,
 			Longitude: groupUserReq.Longitude,
 		})
@@ -158,8 +232,7 @@ func joinGroup(ctx *fiber.Ctx) error {
 		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
 	}
 
-	// TODO: can be done in side effect (goroutine)
-	_triggerGroupMidpointUpdate(group)
+	_scheduleGroupMidpointUpdate(group)
 
 	return ctx.Status(fiber.StatusAccepted).JSON(groupUserResp)
 }
@@ -180,7 +253,7 @@ func leaveGroup(ctx *fiber.Ctx) error {
 	user := ctx.Locals(config.LOCALS_USER).(*models.User)
 	groupIDOrCode := ctx.Params("groupIdOrCode")
 
-	group, err := groupsController.GetGroupByIDorCode(groupIDOrCode, false, false)
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), groupIDOrCode, user.ID, false, false)
 	if err != nil {
 		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
 	}
@@ -190,13 +263,71 @@ func leaveGroup(ctx *fiber.Ctx) error {
 		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
 	}
 
-	// TODO: can be done in side effect (goroutine)
-	_triggerGroupMidpointUpdate(group)
+	_scheduleGroupMidpointUpdate(group)
 	return ctx.Status(fiber.StatusAccepted).JSON([]byte("{}"))
 }
 
+// @Summary Update a group member
+// @Description Update a member of the group, e.g. to exclude them from the midpoint calculation. Only group admins can do this.
+// @Tags groups
+// @ID update-group-member
+// @Accept json
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Param userId path int true "User ID of the member"
+// @Param member body dto.GroupMemberUpdateRequest true "Member Update Data"
+// @Success 202 {object} dto.GroupUserResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "Only group admins can update members"
+// @Failure 404 {object} dto.ErrorResponse "Group or member not found"
+// @Failure 422 {object} dto.ErrorResponse "Member info validation failed"
+// @Failure 500 {object} dto.ErrorResponse "Failed to update group member"
+// @Router /groups/{groupIdOrCode}/members/{userId} [patch]
+// @Security BearerAuth
+func updateGroupMember(ctx *fiber.Ctx) error {
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
+	groupIDOrCode := ctx.Params("groupIdOrCode")
+	memberID, err := strconv.ParseUint(ctx.Params("userId"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid user ID"))
+	}
+
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), groupIDOrCode, user.ID, false, false)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	isAdmin, err := groupUsersController.IsGroupAdmin(group.ID, user.ID)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	if !isAdmin {
+		return ctx.Status(fiber.StatusForbidden).JSON(dto.CreateErrorResponse(fiber.StatusForbidden, "Only group admins can update members"))
+	}
+
+	req, parseError := parsers.ParseBody[dto.GroupMemberUpdateRequest](ctx)
+	if parseError != nil {
+		return parsers.SendParsingError(ctx, parseError)
+	}
+
+	validateErr := validators.ValidateGroupMemberUpdateRequest(req)
+	if validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	member, err := groupUsersController.UpdateGroupMember(group.ID, uint(memberID), req)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+	member = &services.MaskMemberLocations([]dto.GroupUserResponse{*member}, user.ID)[0]
+
+	_scheduleGroupMidpointUpdate(group)
+
+	return ctx.Status(fiber.StatusAccepted).JSON(member)
+}
+
 // @Summary Get group information
-// @Description Get details of a group by ID or code
+// @Description Get details of a group by ID or code. Other members' locations are shown at their location privacy: approximate ones are snapped to a grid and hidden ones are 0, 0. Their ETAs to places are likewise rounded to 5 minutes, or left out. The midpoint (and cluster midpoints) are shown no more exactly than the least exact of the members' locations.
 // @Tags groups
 // @ID get-group
 // @Produce json
@@ -210,45 +341,114 @@ func leaveGroup(ctx *fiber.Ctx) error {
 // @Router /groups/{groupIdOrCode} [get]
 // @Security BearerAuth
 func getGroup(ctx *fiber.Ctx) error {
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
 	groupIDOrCode := ctx.Params("groupIdOrCode")
 	includeUsers := ctx.QueryBool("includeUsers", false)
 	includePlaces := ctx.QueryBool("includePlaces", false)
 
-	group, err := groupsController.GetGroupByIDorCode(groupIDOrCode, includeUsers, includePlaces)
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), groupIDOrCode, user.ID, includeUsers, includePlaces)
 	if err != nil {
 		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
 	}
+	group.RefreshPending = _isGroupRefreshPending(group.ID)
+	group.Members = services.MaskMemberLocations(group.Members, user.ID)
 
 	return ctx.Status(fiber.StatusOK).JSON(group)
 }
 
+// @Summary Get group midpoint fairness report
+// @Description Explain the group midpoint: every member's distance and travel time to the midpoint and to each place, fairness metrics (max, mean, standard deviation and Gini of distances), and the same metrics under the other midpoint strategies. Distances and travel times of other members whose location isn't exact are rounded, and left out if it is hidden.
+// @Tags groups
+// @ID get-group-midpoint-report
+// @Produce json
+// @Param groupIdOrCode path string true "Group ID or Code"
+// @Success 200 {object} dto.MidpointReportResponse
+// @Failure 404 {object} dto.ErrorResponse "Group not found"
+// @Failure 422 {object} dto.ErrorResponse "Invalid group ID or code"
+// @Failure 500 {object} dto.ErrorResponse "Failed to create midpoint report"
+// @Router /groups/{groupIdOrCode}/midpoint/report [get]
+// @Security BearerAuth
+func getGroupMidpointReport(ctx *fiber.Ctx) error {
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
+	groupIDOrCode := ctx.Params("groupIdOrCode")
+
+	group, err := groupsController.GetGroupByIDorCode(ctx.Context(), groupIDOrCode, user.ID, false, false)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	report, err := groupsController.GetMidpointReport(ctx.Context(), group.ID, user.ID)
+	if err != nil {
+		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
+
+// _scheduleGroupMidpointUpdate refreshes the group in the background, once changes to it settle down
+func _scheduleGroupMidpointUpdate(group *dto.GroupResponse) {
+	groupRefreshScheduler.Schedule(group.ID, _enqueueGroupMidpointUpdate)
+}
+
+func _enqueueGroupMidpointUpdate(groupID string) {
+	if err := jobQueue.Enqueue(config.JobKindGroupRefresh, groupID, ""); err != nil {
+		applogger.Error("Error queueing group refresh", groupID, err)
+	}
+}
+
+// _isGroupRefreshPending is true while the refresh waits for changes to settle down, or for its job to finish
+func _isGroupRefreshPending(groupID string) bool {
+	return groupRefreshScheduler.IsPending(groupID) || jobQueue.IsPending(config.JobKindGroupRefresh, groupID)
+}
+
+// _runGroupRefreshJob refreshes the group in the job's key, a group which no longer exists is skipped
+func _runGroupRefreshJob(ctx context.Context, job *models.Job) error {
+	err := _triggerGroupMidpointUpdate(ctx, job.Key)
+	if fiberErr, ok := err.(*fiber.Error); ok && fiberErr.Code == fiber.StatusNotFound {
+		applogger.Warn("Skipping refresh of missing group", job.Key)
+		return nil
+	}
+	if errors.Is(err, services.ErrPlacesBudgetExceeded) {
+		// retrying won't help before the budget is renewed, the group keeps its places until it changes again
+		applogger.Warn("Skipping places of group", job.Key, err)
+		return nil
+	}
+	return err
+}
+
 // side effects:
 // 1. recalculate group midpoint
-// 2. delete existing group places
-// 3. populate group places (parallelly) for all place types
-func _triggerGroupMidpointUpdate(group *dto.GroupResponse) {
-	groupResp, err := _recalculateGroupMidpoint(group.ID)
+// 2. recalculate group clusters and their midpoints
+// 3. search for places of all place types, around the group and cluster midpoints
+// 4. replace the group places with the ones found
+// while a venue is chosen, steps 3 and 4 are skipped so the places the venue was chosen from stay put
+// if any step fails the old places are kept, and the error is returned so the job is retried
+func _triggerGroupMidpointUpdate(ctx context.Context, groupID string) error {
+	groupResp, err := _recalculateGroupMidpoint(ctx, groupID)
 	if err != nil {
 		applogger.Error("Error recalculating group location", err)
+		return err
 	}
-	err = _deleteExistingGroupPlaces(group.ID)
+	clusters, err := groupUsersController.CalculateGroupClusters(ctx, groupID)
 	if err != nil {
-		applogger.Error("Error deleting existing group places", err)
+		applogger.Error("Error recalculating group clusters", err)
+		return err
 	}
-	for _, placeType := range group.PlaceTypes {
-		// TODO: can be parallelized?
-		func(placeType config.PlaceType) {
-			err := _populateGroupPlaces(groupResp, placeType)
-			if err != nil {
-				applogger.Error("Error populating group places", group.ID, "with type", placeType, err)
-			}
-		}(placeType)
+	if groupResp.Venue != nil {
+		applogger.Info("Keeping places of group", groupID, "as its venue is chosen")
+		return nil
+	}
+	places, err := _searchGroupPlaces(services.WithPlacesCaller(ctx, services.PlacesCaller{GroupID: groupID}), groupResp, clusters)
+	if err != nil {
+		applogger.Error("Error searching group places", groupID, err)
+		return err
 	}
+	return groupPlacesController.ReplaceGroupPlaces(groupID, places)
 }
 
-func _recalculateGroupMidpoint(groupID string) (*dto.GroupResponse, error) {
+func _recalculateGroupMidpoint(ctx context.Context, groupID string) (*dto.GroupResponse, error) {
 	applogger.Info("Recalculating group midpoint for group", groupID)
-	centroidLatitude, centroidLongitude, err := groupUsersController.CalculateGroupCentroid(groupID)
+	centroidLatitude, centroidLongitude, err := groupUsersController.CalculateGroupMidpoint(ctx, groupID)
 	if err != nil {
 		return nil, err
 	}
@@ -257,6 +457,14 @@ func _recalculateGroupMidpoint(groupID string) (*dto.GroupResponse, error) {
 	groupMidpointUpdateRequest := &dto.UpdateGroupMidpointRequest{}
 	groupMidpointUpdateRequest.Latitude = centroidLatitude
 	groupMidpointUpdateRequest.Longitude = centroidLongitude
+	// a midpoint without a label is still a midpoint, so geocoding failures are only logged
+	if geocoder := services.GetGeocoder(); geocoder != nil {
+		label, err := geocoder.ReverseGeocode(ctx, groupMidpointUpdateRequest.Location)
+		if err != nil && !errors.Is(err, services.ErrAddressNotFound) {
+			applogger.Warn("Failed to label midpoint of group", groupID, err)
+		}
+		groupMidpointUpdateRequest.Label = label
+	}
 
 	groupResp, err := groupsController.UpdateGroupMidpoint(groupID, groupMidpointUpdateRequest)
 	if err != nil {
@@ -266,35 +474,115 @@ func _recalculateGroupMidpoint(groupID string) (*dto.GroupResponse, error) {
 	return groupResp, nil
 }
 
-func _deleteExistingGroupPlaces(groupID string) error {
-	applogger.Warn("Deleting existing group places for group", groupID)
-	err := groupPlacesController.RemoveAllPlacesFromGroup(groupID)
-	if err != nil {
-		return err
+// _searchGroupPlaces finds places of each place type around the group midpoint (cluster 0) and each cluster's
+// midpoint, running the searches in parallel. It fails as soon as any search fails.
+// Searches finding too few places are widened up to config.PlacesSearchMaxRadius. If one still finds nothing,
+// it is run again around the nearest places the other searches of its cluster found.
+func _searchGroupPlaces(ctx context.Context, group *dto.GroupResponse, clusters []models.GroupCluster) ([]dto.GroupPlacesAddRequest, error) {
+	type placesSearch struct {
+		location  dto.Location
+		cluster   int
+		placeType config.PlaceType
+	}
+	var searches []placesSearch
+	for _, placeType := range group.PlaceTypes {
+		searches = append(searches, placesSearch{
+			location:  dto.Location{Latitude: group.MidpointLatitude, Longitude: group.MidpointLongitude},
+			cluster:   0,
+			placeType: placeType,
+		})
+		for _, cluster := range clusters {
+			searches = append(searches, placesSearch{
+				location:  dto.Location{Latitude: cluster.MidpointLatitude, Longitude: cluster.MidpointLongitude},
+				cluster:   cluster.Number,
+				placeType: placeType,
+			})
+		}
 	}
-	applogger.Warn("Deleted existing group places for group", groupID)
-	return nil
-}
 
-func _populateGroupPlaces(group *dto.GroupResponse, placeType config.PlaceType) error {
-	applogger.Info("Populating group places for group", group.ID, "with type", placeType)
-	location := dto.Location{
-		Latitude:  group.MidpointLatitude,
-		Longitude: group.MidpointLongitude,
+	preferences := group.SearchPreferences
+	query := func(search placesSearch, location dto.Location, radius int) services.NearbyPlacesQuery {
+		return services.NearbyPlacesQuery{
+			Location:             location,
+			Radius:               radius,
+			PlaceType:            search.placeType,
+			MaxResults:           preferences.ResultsPerType,
+			MinRating:            preferences.MinRating,
+			MinPriceLevel:        preferences.MinPriceLevel,
+			MaxPriceLevel:        preferences.MaxPriceLevel,
+			OpenNow:              preferences.OpenNow,
+			WheelchairAccessible: preferences.WheelchairAccessible,
+		}
+	}
+	searchAll := func(indexes []int, search func(ctx context.Context, i int) error) error {
+		g, gctx := errgroup.WithContext(ctx)
+		for _, i := range indexes {
+			g.Go(func() error { return search(gctx, i) })
+		}
+		return g.Wait()
 	}
 
-	places, err := placesSearchService.NearbyPlaces(location, group.Radius, placeType)
+	results := make([]dto.GroupPlacesAddRequest, len(searches))
+	err := searchAll(lo.Range(len(searches)), func(ctx context.Context, i int) error {
+		search := searches[i]
+		places, radius, err := services.SearchWithAdaptiveRadius(ctx, query(search, search.location, group.Radius),
+			config.PlacesSearchMaxRadius, config.PlacesSearchRadiusGrowth, config.PlacesSearchMinResults, _searchPlaces)
+		if err != nil {
+			applogger.Error("Error searching places for group", group.ID, "cluster", search.cluster, "with type", search.placeType, err)
+			return err
+		}
+		results[i] = dto.GroupPlacesAddRequest{Places: places, Cluster: search.cluster, Radius: radius}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	groupPlacesAddRequest := &dto.GroupPlacesAddRequest{
-		Places: places,
+
+	// nothing was found for these even at the widest radius
+	empty := lo.Filter(lo.Range(len(searches)), func(i int, _ int) bool { return len(results[i].Places) == 0 })
+	err = searchAll(empty, func(ctx context.Context, i int) error {
+		search := searches[i]
+		nearby := lo.FlatMap(results, func(result dto.GroupPlacesAddRequest, _ int) []dto.Place {
+			if result.Cluster != search.cluster {
+				return nil
+			}
+			return result.Places
+		})
+		center, ok := services.NearestPlacesCluster(nearby)
+		if !ok {
+			return nil
+		}
+		places, err := _searchPlaces(ctx, query(search, center, group.Radius))
+		if err != nil {
+			applogger.Error("Error searching nearest places for group", group.ID, "cluster", search.cluster, "with type", search.placeType, err)
+			return err
+		}
+		if len(places) > 0 {
+			results[i] = dto.GroupPlacesAddRequest{Places: places, Cluster: search.cluster, Radius: services.PlacesRadius(search.location, places)}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	_, err = groupPlacesController.AddPlacesToGroup(group.ID, groupPlacesAddRequest)
+	return results, nil
+}
+
+// _searchPlaces waits for a free search slot, then searches with a timeout
+// The places found are filtered by the query's preferences, whichever provider found them
+func _searchPlaces(ctx context.Context, query services.NearbyPlacesQuery) ([]dto.Place, error) {
+	if err := placesSearchSemaphore.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer placesSearchSemaphore.Release(1)
+
+	ctx, cancel := context.WithTimeout(ctx, config.PlacesSearchTimeout)
+	defer cancel()
+	places, err := placesProvider.NearbyPlaces(ctx, query)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return services.FilterNearbyPlaces(query, places), nil
 }
 
 // @Summary Create a new group
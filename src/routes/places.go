@@ -0,0 +1,120 @@
+package routes
+
+import (
+	"context"
+	"errors"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/security"
+	"github.com/championswimmer/api.midpoint.place/src/security/ratelimit"
+	"github.com/championswimmer/api.midpoint.place/src/server/validators"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+)
+
+var placeSearchService *services.PlaceSearchService
+
+func PlacesRoute() func(router fiber.Router) {
+	placeSearchService = services.GetPlaceSearchService()
+
+	return func(router fiber.Router) {
+		router.Get("/types", listPlaceTypes)
+		router.Get("/search", security.MandatoryJwtAuthMiddleware, ratelimit.PlaceSearchRateLimiter(), searchPlaces)
+	}
+}
+
+// @Summary List place types
+// @Description List the place types groups can search for, with their labels and icons (Material Symbols names)
+// @Tags places
+// @ID list-place-types
+// @Produce json
+// @Success 200 {array} dto.PlaceTypeResponse
+// @Router /places/types [get]
+func listPlaceTypes(ctx *fiber.Ctx) error {
+	placeTypes := lo.Map(config.PlaceTypeInfos(), func(placeType config.PlaceTypeInfo, _ int) dto.PlaceTypeResponse {
+		return dto.PlaceTypeResponse{
+			ID:      placeType.ID,
+			Label:   placeType.Label,
+			Icon:    placeType.Icon,
+			Default: placeType.Default,
+		}
+	})
+	return ctx.Status(fiber.StatusOK).JSON(placeTypes)
+}
+
+// @Summary Search places
+// @Description Browse places around any point, without changing a group. Search for places of a type nearby, for places matching a query (through the places provider's text search), or both. Results are cached for a while and paginated, each search finds up to 20 places. Users can search 20 times a minute.
+// @Tags places
+// @ID search-places
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param radius query int false "Radius in meters, defaults to 1000" maximum(50000)
+// @Param type query string false "Place type, see /places/types"
+// @Param query query string false "Text to search for, like a cuisine or the name of a place"
+// @Param page query int false "Page number, from 1"
+// @Param page_size query int false "Places per page, defaults to 10" maximum(20)
+// @Success 200 {object} dto.PlaceSearchResponse
+// @Failure 400 {object} dto.ErrorResponse "Missing or invalid location"
+// @Failure 422 {object} dto.ErrorResponse "Search validation failed"
+// @Failure 429 {object} dto.ErrorResponse "Too many place searches"
+// @Failure 501 {object} dto.ErrorResponse "Text search is not supported by the places provider"
+// @Failure 502 {object} dto.ErrorResponse "Failed to search places"
+// @Failure 503 {object} dto.ErrorResponse "Places budget used up"
+// @Router /places/search [get]
+// @Security BearerAuth
+func searchPlaces(ctx *fiber.Ctx) error {
+	if ctx.Query("lat") == "" || ctx.Query("lng") == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "lat and lng are required"))
+	}
+	req := &dto.PlaceSearchRequest{
+		Location: dto.Location{
+			Latitude:  ctx.QueryFloat("lat"),
+			Longitude: ctx.QueryFloat("lng"),
+		},
+		Radius:    ctx.QueryInt("radius", 1000),
+		PlaceType: config.PlaceType(ctx.Query("type")),
+		Text:      ctx.Query("query"),
+		Page:      ctx.QueryInt("page", 1),
+		PageSize:  ctx.QueryInt("page_size", config.DefaultPlaceSearchPageSize),
+	}
+	if validateErr := validators.ValidatePlaceSearchRequest(req); validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	user := ctx.Locals(config.LOCALS_USER).(*models.User)
+	searchCtx, cancel := context.WithTimeout(services.WithPlacesCaller(ctx.Context(), services.PlacesCaller{UserID: user.ID}), config.PlacesSearchTimeout)
+	defer cancel()
+	places, err := placeSearchService.Search(searchCtx, services.PlaceSearchQuery{
+		Location:  req.Location,
+		Radius:    req.Radius,
+		PlaceType: req.PlaceType,
+		Text:      req.Text,
+	})
+	if errors.Is(err, services.ErrTextSearchUnsupported) {
+		return ctx.Status(fiber.StatusNotImplemented).JSON(dto.CreateErrorResponse(fiber.StatusNotImplemented, "Text search is not supported by the places provider"))
+	}
+	if errors.Is(err, services.ErrPlacesBudgetExceeded) {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(dto.CreateErrorResponse(fiber.StatusServiceUnavailable, "Places budget used up, try again tomorrow"))
+	}
+	if err != nil {
+		return ctx.Status(fiber.StatusBadGateway).JSON(dto.CreateErrorResponse(fiber.StatusBadGateway, "Failed to search places"))
+	}
+
+	start := min((req.Page-1)*req.PageSize, len(places))
+	end := min(start+req.PageSize, len(places))
+	page := places[start:end]
+	if page == nil {
+		page = []dto.Place{}
+	}
+	return ctx.Status(fiber.StatusOK).JSON(dto.PlaceSearchResponse{
+		Places:   page,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Total:    len(places),
+		HasMore:  end < len(places),
+	})
+}
@@ -1,6 +1,9 @@
 package routes
 
 import (
+	"context"
+	"errors"
+	"net/url"
 	"strconv"
 
 	"github.com/championswimmer/api.midpoint.place/src/config"
@@ -11,6 +14,8 @@ import (
 	"github.com/championswimmer/api.midpoint.place/src/security/ratelimit"
 	"github.com/championswimmer/api.midpoint.place/src/server/parsers"
 	"github.com/championswimmer/api.midpoint.place/src/server/validators"
+	"github.com/championswimmer/api.midpoint.place/src/services"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -23,7 +28,10 @@ func UsersRoute() func(router fiber.Router) {
 	return func(router fiber.Router) {
 		router.Post("/", ratelimit.UserCreateRateLimiter(), registerUser)
 		router.Post("/login", loginUser)
-		router.Post("/:userid", security.MandatoryJwtAuthMiddleware, updateUserData)
+		router.Post("/:userid", security.MandatoryJwtAuthMiddleware, ratelimit.GeocodeRateLimiter(), updateUserData)
+		router.Get("/:userid/locations", security.MandatoryJwtAuthMiddleware, listSavedLocations)
+		router.Put("/:userid/locations/:name", security.MandatoryJwtAuthMiddleware, ratelimit.GeocodeRateLimiter(), saveLocation)
+		router.Delete("/:userid/locations/:name", security.MandatoryJwtAuthMiddleware, deleteSavedLocation)
 	}
 
 }
@@ -89,7 +97,8 @@ func loginUser(ctx *fiber.Ctx) error {
 }
 
 // @Summary Update user location
-// @Description Update location details for a user
+// @Description Update location details for a user, the location can be given as an address to geocode.
+// @Description This is the user's default saved location, group memberships which follow it move with it, unless it is too far from their group to join from, which stops them following it.
 // @Tags users
 // @ID update-user-location
 // @Accept json
@@ -99,16 +108,16 @@ func loginUser(ctx *fiber.Ctx) error {
 // @Success 200 {object} dto.UserResponse "User updated successfully"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request"
 // @Failure 403 {object} dto.ErrorResponse "You are not allowed to update this user's data"
+// @Failure 422 {object} dto.ErrorResponse "Address not found"
+// @Failure 429 {object} dto.ErrorResponse "Too many addresses to geocode"
+// @Failure 501 {object} dto.ErrorResponse "Addresses are not supported"
+// @Failure 502 {object} dto.ErrorResponse "Failed to geocode address"
 // @Router /users/{userid} [post]
 // @Security BearerAuth
 func updateUserData(ctx *fiber.Ctx) error {
-	userID, err := strconv.ParseUint(ctx.Params("userid"), 10, 32)
+	userID, err := _selfUserIDFromCtx(ctx)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid user ID"))
-	}
-	// allow only for self
-	if userID != uint64(ctx.Locals(config.LOCALS_USER).(*models.User).ID) {
-		return ctx.Status(fiber.StatusForbidden).JSON(dto.CreateErrorResponse(fiber.StatusForbidden, "You are not allowed to update this user's data"))
+		return ctx.Status(err.Code).JSON(dto.CreateErrorResponse(err.Code, err.Error()))
 	}
 
 	req, parseError := parsers.ParseBody[dto.UserUpdateRequest](ctx)
@@ -116,15 +125,186 @@ func updateUserData(ctx *fiber.Ctx) error {
 		return parsers.SendParsingError(ctx, parseError)
 	}
 
+	if validateErr := validators.ValidateAddress(req.Address); validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+	if req.Address != "" {
+		location, err := _geocodeAddress(ctx.Context(), req.Address)
+		if err != nil {
+			return ctx.Status(err.Code).JSON(dto.CreateErrorResponse(err.Code, err.Error()))
+		}
+		req.Location = *location
+	}
+
 	validateErr := validators.ValidateLocation(req.Location)
 	if validateErr != nil {
 		return validators.SendValidationError(ctx, validateErr)
 	}
 
-	user, err := usersController.UpdateUserLocation(uint(userID), req)
-	if err != nil {
-		return ctx.Status(err.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(err.(*fiber.Error).Code, err.Error()))
+	user, updateErr := usersController.UpdateUserLocation(userID, req)
+	if updateErr != nil {
+		return ctx.Status(updateErr.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(updateErr.(*fiber.Error).Code, updateErr.Error()))
+	}
+	if moveErr := _moveSavedLocationMemberships(userID, config.DefaultSavedLocation, req.Location); moveErr != nil {
+		return ctx.Status(moveErr.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(moveErr.(*fiber.Error).Code, moveErr.Error()))
 	}
 
 	return ctx.Status(fiber.StatusAccepted).JSON(user)
 }
+
+// @Summary List saved locations
+// @Description List the user's saved locations to join groups from, starting with the default one (the user's own location) if it is set
+// @Tags users
+// @ID list-saved-locations
+// @Produce json
+// @Param userid path string true "User ID"
+// @Success 200 {array} dto.SavedLocationResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "You are not allowed to update this user's data"
+// @Router /users/{userid}/locations [get]
+// @Security BearerAuth
+func listSavedLocations(ctx *fiber.Ctx) error {
+	userID, err := _selfUserIDFromCtx(ctx)
+	if err != nil {
+		return ctx.Status(err.Code).JSON(dto.CreateErrorResponse(err.Code, err.Error()))
+	}
+
+	locations, listErr := usersController.ListSavedLocations(userID)
+	if listErr != nil {
+		return ctx.Status(listErr.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(listErr.(*fiber.Error).Code, listErr.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(locations)
+}
+
+// @Summary Save a location
+// @Description Save a location under a name like home or work, from a location or an address to geocode. Group memberships which follow the saved location move with it, unless it is too far from their group to join from, which stops them following it.
+// @Tags users
+// @ID save-location
+// @Accept json
+// @Produce json
+// @Param userid path string true "User ID"
+// @Param name path string true "Name of the saved location, default for the user's own location"
+// @Param location body dto.SavedLocationRequest true "Location"
+// @Success 200 {object} dto.SavedLocationResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "You are not allowed to update this user's data"
+// @Failure 409 {object} dto.ErrorResponse "Too many saved locations"
+// @Failure 422 {object} dto.ErrorResponse "Address not found"
+// @Failure 429 {object} dto.ErrorResponse "Too many addresses to geocode"
+// @Router /users/{userid}/locations/{name} [put]
+// @Security BearerAuth
+func saveLocation(ctx *fiber.Ctx) error {
+	userID, err := _selfUserIDFromCtx(ctx)
+	if err != nil {
+		return ctx.Status(err.Code).JSON(dto.CreateErrorResponse(err.Code, err.Error()))
+	}
+	name, unescapeErr := url.PathUnescape(ctx.Params("name"))
+	if unescapeErr != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid location name"))
+	}
+	if validateErr := validators.ValidateSavedLocationName(name); validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	req, parseError := parsers.ParseBody[dto.SavedLocationRequest](ctx)
+	if parseError != nil {
+		return parsers.SendParsingError(ctx, parseError)
+	}
+	if validateErr := validators.ValidateAddress(req.Address); validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+	if req.Address != "" {
+		location, err := _geocodeAddress(ctx.Context(), req.Address)
+		if err != nil {
+			return ctx.Status(err.Code).JSON(dto.CreateErrorResponse(err.Code, err.Error()))
+		}
+		req.Location = *location
+	}
+	if validateErr := validators.ValidateLocation(req.Location); validateErr != nil {
+		return validators.SendValidationError(ctx, validateErr)
+	}
+
+	location, saveErr := usersController.SaveLocation(userID, name, req.Location)
+	if saveErr != nil {
+		return ctx.Status(saveErr.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(saveErr.(*fiber.Error).Code, saveErr.Error()))
+	}
+	if moveErr := _moveSavedLocationMemberships(userID, name, req.Location); moveErr != nil {
+		return ctx.Status(moveErr.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(moveErr.(*fiber.Error).Code, moveErr.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(location)
+}
+
+// @Summary Delete a saved location
+// @Description Delete one of the user's named locations, group memberships which followed it stay where they are
+// @Tags users
+// @ID delete-saved-location
+// @Param userid path string true "User ID"
+// @Param name path string true "Name of the saved location"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "You are not allowed to update this user's data"
+// @Failure 404 {object} dto.ErrorResponse "Saved location not found"
+// @Failure 422 {object} dto.ErrorResponse "The default location can't be deleted"
+// @Router /users/{userid}/locations/{name} [delete]
+// @Security BearerAuth
+func deleteSavedLocation(ctx *fiber.Ctx) error {
+	userID, err := _selfUserIDFromCtx(ctx)
+	if err != nil {
+		return ctx.Status(err.Code).JSON(dto.CreateErrorResponse(err.Code, err.Error()))
+	}
+	name, unescapeErr := url.PathUnescape(ctx.Params("name"))
+	if unescapeErr != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.CreateErrorResponse(fiber.StatusBadRequest, "Invalid location name"))
+	}
+
+	if deleteErr := usersController.DeleteSavedLocation(userID, name); deleteErr != nil {
+		return ctx.Status(deleteErr.(*fiber.Error).Code).JSON(dto.CreateErrorResponse(deleteErr.(*fiber.Error).Code, deleteErr.Error()))
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// _selfUserIDFromCtx returns the user ID in the path, users can only see and change their own data
+func _selfUserIDFromCtx(ctx *fiber.Ctx) (uint, *fiber.Error) {
+	userID, err := strconv.ParseUint(ctx.Params("userid"), 10, 32)
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+	if uint(userID) != ctx.Locals(config.LOCALS_USER).(*models.User).ID {
+		return 0, fiber.NewError(fiber.StatusForbidden, "You are not allowed to update this user's data")
+	}
+	return uint(userID), nil
+}
+
+// _moveSavedLocationMemberships moves the memberships which follow a saved location along with it,
+// and recalculates the midpoints of their groups
+func _moveSavedLocationMemberships(userID uint, name string, location dto.Location) error {
+	groupIDs, err := usersController.MoveSavedLocationMemberships(userID, name, location)
+	if err != nil {
+		return err
+	}
+	for _, groupID := range groupIDs {
+		_scheduleGroupMidpointUpdate(&dto.GroupResponse{ID: groupID})
+	}
+	return nil
+}
+
+// _geocodeAddress finds the location of an address members sent instead of their location
+func _geocodeAddress(ctx context.Context, address string) (*dto.Location, *fiber.Error) {
+	geocoder := services.GetGeocoder()
+	if geocoder == nil {
+		return nil, fiber.NewError(fiber.StatusNotImplemented, "Addresses are not supported, send a location instead")
+	}
+	geocoded, err := geocoder.Geocode(ctx, address)
+	if errors.Is(err, services.ErrAddressNotFound) {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Address not found")
+	}
+	if err != nil {
+		applogger.Error("Failed to geocode address", err)
+		return nil, fiber.NewError(fiber.StatusBadGateway, "Failed to geocode address")
+	}
+	// member addresses are often their homes, so where they are isn't logged
+	return &geocoded.Location, nil
+}
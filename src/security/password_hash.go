@@ -1,18 +1,17 @@
 package security
 
 import (
+	"github.com/championswimmer/api.midpoint.place/src/config"
 	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
 	"github.com/samber/lo"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const HashCostFactor = 10
-
 func HashPassword(password string) string {
 	if password == "" {
 		applogger.Error("Hashing empty password")
 	}
-	hashedPassword := lo.Must(bcrypt.GenerateFromPassword([]byte(password), HashCostFactor))
+	hashedPassword := lo.Must(bcrypt.GenerateFromPassword([]byte(password), config.PasswordHashCost))
 
 	return string(hashedPassword)
 }
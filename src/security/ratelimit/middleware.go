@@ -1,9 +1,13 @@
 package ratelimit
 
 import (
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
@@ -88,3 +92,54 @@ func GroupCreateRateLimiter() fiber.Handler {
 		},
 	})
 }
+
+// PlaceSearchRateLimiter creates a new rate limiter for ad-hoc place searches, per user as they are behind authentication.
+func PlaceSearchRateLimiter() fiber.Handler {
+	if config.Env == "test" {
+		return noopMiddleware
+	}
+	return limiter.New(limiter.Config{
+		Max:        20,
+		Expiration: 1 * time.Minute,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			if user, ok := c.Locals(config.LOCALS_USER).(*models.User); ok {
+				return "user:" + strconv.FormatUint(uint64(user.ID), 10)
+			}
+			return c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"message": "Too many place searches (20 req/min)",
+			})
+		},
+	})
+}
+
+// GeocodeRateLimiter creates a new rate limiter for requests sending an address to geocode, per user as they are behind
+// authentication. Requests without an address aren't counted.
+func GeocodeRateLimiter() fiber.Handler {
+	if config.Env == "test" {
+		return noopMiddleware
+	}
+	return limiter.New(limiter.Config{
+		Max:        10,
+		Expiration: 1 * time.Minute,
+		Next: func(c *fiber.Ctx) bool {
+			var body struct {
+				Address string `json:"address"`
+			}
+			return json.Unmarshal(c.Body(), &body) != nil || strings.TrimSpace(body.Address) == ""
+		},
+		KeyGenerator: func(c *fiber.Ctx) string {
+			if user, ok := c.Locals(config.LOCALS_USER).(*models.User); ok {
+				return "user:" + strconv.FormatUint(uint64(user.ID), 10)
+			}
+			return c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"message": "Too many addresses to geocode (10 req/min)",
+			})
+		},
+	})
+}
@@ -3,9 +3,11 @@ package ratelimit
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/db/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 )
@@ -96,6 +98,57 @@ func TestGroupCreateRateLimiter(t *testing.T) {
 	})
 }
 
+func TestPlaceSearchRateLimiter(t *testing.T) {
+	withNonTestEnv(t, func() {
+		app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+		app.Get("/places/search", func(c *fiber.Ctx) error {
+			c.Locals(config.LOCALS_USER, &models.User{ID: uint(c.QueryInt("user"))})
+			return c.Next()
+		}, PlaceSearchRateLimiter(), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+		for range 20 {
+			req := httptest.NewRequest("GET", "/places/search?user=1", nil)
+			req.Header.Set(fiber.HeaderXForwardedFor, "10.0.0.5")
+			resp := assertRequest(t, app, req)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		}
+
+		req := httptest.NewRequest("GET", "/places/search?user=1", nil)
+		req.Header.Set(fiber.HeaderXForwardedFor, "10.0.0.5")
+		resp := assertRequest(t, app, req)
+		assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+
+		// limits are per user, not per IP
+		req = httptest.NewRequest("GET", "/places/search?user=2", nil)
+		req.Header.Set(fiber.HeaderXForwardedFor, "10.0.0.5")
+		resp = assertRequest(t, app, req)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestGeocodeRateLimiter(t *testing.T) {
+	withNonTestEnv(t, func() {
+		app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+		app.Put("/join", func(c *fiber.Ctx) error {
+			c.Locals(config.LOCALS_USER, &models.User{ID: 1})
+			return c.Next()
+		}, GeocodeRateLimiter(), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusAccepted) })
+		send := func(body string) int {
+			req := httptest.NewRequest("PUT", "/join", strings.NewReader(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return assertRequest(t, app, req).StatusCode
+		}
+
+		for range 10 {
+			assert.Equal(t, fiber.StatusAccepted, send(`{"address": "Soho, London"}`))
+		}
+		assert.Equal(t, fiber.StatusTooManyRequests, send(`{"address": "Soho, London"}`))
+
+		// requests without an address aren't limited
+		assert.Equal(t, fiber.StatusAccepted, send(`{"latitude": 51.5, "longitude": -0.1}`))
+	})
+}
+
 func assertRequest(t *testing.T, app *fiber.App, req *http.Request) *http.Response {
 	t.Helper()
 	resp, err := app.Test(req, -1)
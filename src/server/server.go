@@ -1,9 +1,11 @@
 package server
 
 import (
+	"github.com/championswimmer/api.midpoint.place/src/controllers"
 	_ "github.com/championswimmer/api.midpoint.place/src/docs" // docs is generated by Swag CLI, you have to import it.
 	"github.com/championswimmer/api.midpoint.place/src/routes"
 	"github.com/championswimmer/api.midpoint.place/src/security/ratelimit"
+	"github.com/championswimmer/api.midpoint.place/src/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -41,10 +43,15 @@ func CreateServer() *fiber.App {
 
 	apiV1 := app.Group("/v1")
 
+	// count calls to the places provider, before the routes start using it
+	services.InjectPlacesUsageStore(controllers.CreatePlacesUsageController())
+
 	// Register routes
 	apiV1.Route("/users", routes.UsersRoute())
 	apiV1.Route("/groups", routes.GroupsRoute())
 	apiV1.Route("/waitlist", routes.WaitlistRoute())
+	apiV1.Route("/places", routes.PlacesRoute())
+	apiV1.Route("/admin", routes.AdminRoute())
 
 	app.Get("/docs/*", swagger.HandlerDefault)
 
@@ -63,6 +63,83 @@ func validatePlaceTypes(placeTypes []config.PlaceType) *ValidationError {
 	return nil
 }
 
+func validateMidpointStrategy(strategy config.MidpointStrategy) *ValidationError {
+	if !config.IsSupportedMidpointStrategy(strategy) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Midpoint strategy must be one of centroid, min_total_time or min_max_time",
+		}
+	}
+	return nil
+}
+
+// ValidateClusterSettings checks the cluster settings of a group, taken together
+// k-means needs a cluster count, and DBSCAN needs a cluster radius
+func ValidateClusterSettings(method config.ClusterMethod, count int, radius int) *ValidationError {
+	if !config.IsSupportedClusterMethod(method) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Cluster method must be one of none, kmeans or dbscan",
+		}
+	}
+	if count != 0 && (count < 2 || count > config.MaxGroupClusters) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Cluster count must be between 2 and " + strconv.Itoa(config.MaxGroupClusters),
+		}
+	}
+	if radius < 0 {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Cluster radius must be a positive integer",
+		}
+	}
+	if method == config.ClusterMethodKMeans && count == 0 {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Cluster count is required for kmeans clustering",
+		}
+	}
+	if method == config.ClusterMethodDBSCAN && radius == 0 {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Cluster radius is required for dbscan clustering",
+		}
+	}
+	return nil
+}
+
+func validateSearchPreferences(preferences *dto.PlaceSearchPreferences) *ValidationError {
+	if preferences.ResultsPerType < 0 || preferences.ResultsPerType > config.MaxPlacesResultsPerType {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Results per type must be between 1 and " + strconv.Itoa(config.MaxPlacesResultsPerType) + ", or 0 for the default",
+		}
+	}
+	if preferences.MinRating < 0 || preferences.MinRating > 5 {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Minimum rating must be between 0 (any rating) and 5",
+		}
+	}
+	for _, priceLevel := range []config.PriceLevel{preferences.MinPriceLevel, preferences.MaxPriceLevel} {
+		if priceLevel < config.PriceLevelUnknown || priceLevel > config.PriceLevelVeryExpensive {
+			return &ValidationError{
+				status:  fiber.StatusUnprocessableEntity,
+				message: "Price levels must be between 1 (free) and 5 (very expensive), or 0 for any",
+			}
+		}
+	}
+	if preferences.MinPriceLevel != config.PriceLevelUnknown && preferences.MaxPriceLevel != config.PriceLevelUnknown &&
+		preferences.MinPriceLevel > preferences.MaxPriceLevel {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Minimum price level cannot be above the maximum",
+		}
+	}
+	return nil
+}
+
 func ValidateCreateGroupRequest(req *dto.CreateGroupRequest) *ValidationError {
 	if err := validateName(req.Name); err != nil {
 		return err
@@ -78,6 +155,21 @@ func ValidateCreateGroupRequest(req *dto.CreateGroupRequest) *ValidationError {
 			return err
 		}
 	}
+	if req.MidpointStrategy != "" {
+		if err := validateMidpointStrategy(req.MidpointStrategy); err != nil {
+			return err
+		}
+	}
+	if req.ClusterMethod != "" {
+		if err := ValidateClusterSettings(req.ClusterMethod, req.ClusterCount, req.ClusterRadius); err != nil {
+			return err
+		}
+	}
+	if req.SearchPreferences != nil {
+		if err := validateSearchPreferences(req.SearchPreferences); err != nil {
+			return err
+		}
+	}
 	// Add any other specific validations for CreateGroupRequest
 	return nil
 }
@@ -103,10 +195,129 @@ func ValidateUpdateGroupRequest(req *dto.UpdateGroupRequest) *ValidationError {
 			return err
 		}
 	}
+	if req.MidpointStrategy != "" {
+		if err := validateMidpointStrategy(req.MidpointStrategy); err != nil {
+			return err
+		}
+	}
+	if req.ClusterMethod != "" && !config.IsSupportedClusterMethod(req.ClusterMethod) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Cluster method must be one of none, kmeans or dbscan",
+		}
+	}
+	if req.SearchPreferences != nil {
+		if err := validateSearchPreferences(req.SearchPreferences); err != nil {
+			return err
+		}
+	}
+	// the method, count and radius are checked together once merged with the group's current settings
 	// Add any other specific validations for UpdateGroupRequest
 	return nil
 }
 
+func ValidateGroupUserJoinRequest(req *dto.GroupUserJoinRequest) *ValidationError {
+	if err := ValidateAddress(req.Address); err != nil {
+		return err
+	}
+	if req.SavedLocation != "" {
+		if req.Address != "" {
+			return &ValidationError{
+				status:  fiber.StatusUnprocessableEntity,
+				message: "Join from either an address or a saved location",
+			}
+		}
+		if err := ValidateSavedLocationName(req.SavedLocation); err != nil {
+			return err
+		}
+	}
+	if req.TravelMode != "" && !config.IsSupportedTravelMode(req.TravelMode) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Travel mode must be one of walk, bike, drive or transit",
+		}
+	}
+	if req.LocationPrivacy != "" && !config.IsSupportedLocationPrivacy(req.LocationPrivacy) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Location privacy must be one of exact, approximate or hidden",
+		}
+	}
+	return nil
+}
+
+func ValidateGroupMemberUpdateRequest(req *dto.GroupMemberUpdateRequest) *ValidationError {
+	switch req.MidpointInclusion {
+	case config.MidpointInclusionAuto, config.MidpointInclusionIncluded, config.MidpointInclusionExcluded:
+		return nil
+	}
+	return &ValidationError{
+		status:  fiber.StatusUnprocessableEntity,
+		message: "Midpoint inclusion must be one of auto, included or excluded",
+	}
+}
+
+func ValidateGroupPlaceVoteRequest(req *dto.GroupPlaceVoteRequest) *ValidationError {
+	if !config.IsSupportedPlaceVote(req.Vote) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Vote must be one of up, down or none",
+		}
+	}
+	return nil
+}
+
+func ValidateGroupPlaceRankingRequest(req *dto.GroupPlaceRankingRequest) *ValidationError {
+	if len(req.PlaceIDs) > config.MaxRankedPlaces {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "At most " + strconv.Itoa(config.MaxRankedPlaces) + " places can be ranked",
+		}
+	}
+	return nil
+}
+
+func ValidateGroupVenueRequest(req *dto.GroupVenueRequest) *ValidationError {
+	if (req.PlaceID == "") == (req.Custom == nil) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Venue must be either a place of the group or a custom place",
+		}
+	}
+	if req.Custom == nil {
+		return nil
+	}
+	if req.Custom.Name == "" || len(req.Custom.Name) > 100 {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Custom venue name must be between 1 and 100 characters",
+		}
+	}
+	return ValidateLocation(req.Custom.Location)
+}
+
+func ValidateCustomPlaceAddRequest(req *dto.CustomPlaceAddRequest) *ValidationError {
+	if req.Name == "" || len(req.Name) > 100 {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Place name must be between 1 and 100 characters",
+		}
+	}
+	if req.Address == "" {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Place address is required",
+		}
+	}
+	if !config.IsSupportedPlaceType(req.Type) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Invalid place type",
+		}
+	}
+	return ValidateLocation(req.Location)
+}
+
 // TODO: there are no e2e tests for this yet.
 func ValidateLocationProximity(loc1 dto.Location, loc2 dto.Location) *ValidationError {
 	coord1 := haversine.Coord{
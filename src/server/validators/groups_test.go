@@ -1,6 +1,7 @@
 package validators
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/championswimmer/api.midpoint.place/src/config"
@@ -31,3 +32,89 @@ func TestValidateUpdateGroupRequest_PlaceTypes(t *testing.T) {
 	assert.NotNil(t, ValidateUpdateGroupRequest(&dto.UpdateGroupRequest{PlaceTypes: &invalid}))
 	assert.Nil(t, ValidateUpdateGroupRequest(&dto.UpdateGroupRequest{PlaceTypes: &valid}))
 }
+
+func TestValidateGroupMemberUpdateRequest(t *testing.T) {
+	assert.Nil(t, ValidateGroupMemberUpdateRequest(&dto.GroupMemberUpdateRequest{MidpointInclusion: config.MidpointInclusionExcluded}))
+	assert.Nil(t, ValidateGroupMemberUpdateRequest(&dto.GroupMemberUpdateRequest{MidpointInclusion: config.MidpointInclusionAuto}))
+	assert.NotNil(t, ValidateGroupMemberUpdateRequest(&dto.GroupMemberUpdateRequest{MidpointInclusion: "sometimes"}))
+	assert.NotNil(t, ValidateGroupMemberUpdateRequest(&dto.GroupMemberUpdateRequest{}))
+}
+
+func TestValidateGroupUserJoinRequest(t *testing.T) {
+	assert.Nil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{}))
+	assert.Nil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{TravelMode: config.TravelModeTransit}))
+	assert.NotNil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{TravelMode: "teleport"}))
+	assert.Nil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{LocationPrivacy: config.LocationPrivacyHidden}))
+	assert.NotNil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{LocationPrivacy: "secret"}))
+	assert.Nil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{Address: "Soho, London"}))
+	assert.NotNil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{Address: strings.Repeat("a", config.MaxAddressLength+1)}))
+	assert.Nil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{SavedLocation: "Work 2"}))
+	assert.NotNil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{SavedLocation: "home", Address: "Soho"}))
+	assert.NotNil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{SavedLocation: "../home"}))
+	assert.NotNil(t, ValidateGroupUserJoinRequest(&dto.GroupUserJoinRequest{SavedLocation: strings.Repeat("a", config.MaxSavedLocationNameLength+1)}))
+}
+
+func TestValidateClusterSettings(t *testing.T) {
+	assert.Nil(t, ValidateClusterSettings(config.ClusterMethodNone, 0, 0))
+	assert.Nil(t, ValidateClusterSettings(config.ClusterMethodKMeans, 3, 0))
+	assert.Nil(t, ValidateClusterSettings(config.ClusterMethodDBSCAN, 0, 5000))
+	assert.NotNil(t, ValidateClusterSettings("hierarchical", 0, 0))
+	assert.NotNil(t, ValidateClusterSettings(config.ClusterMethodKMeans, 0, 0))
+	assert.NotNil(t, ValidateClusterSettings(config.ClusterMethodKMeans, config.MaxGroupClusters+1, 0))
+	assert.NotNil(t, ValidateClusterSettings(config.ClusterMethodDBSCAN, 0, 0))
+	assert.NotNil(t, ValidateClusterSettings(config.ClusterMethodDBSCAN, 0, -10))
+}
+
+func TestValidateGroupPlaceVoteRequest(t *testing.T) {
+	assert.Nil(t, ValidateGroupPlaceVoteRequest(&dto.GroupPlaceVoteRequest{Vote: config.PlaceVoteUp}))
+	assert.Nil(t, ValidateGroupPlaceVoteRequest(&dto.GroupPlaceVoteRequest{Vote: config.PlaceVoteNone}))
+	assert.NotNil(t, ValidateGroupPlaceVoteRequest(&dto.GroupPlaceVoteRequest{Vote: "sideways"}))
+	assert.NotNil(t, ValidateGroupPlaceVoteRequest(&dto.GroupPlaceVoteRequest{}))
+}
+
+func TestValidateGroupPlaceRankingRequest(t *testing.T) {
+	assert.Nil(t, ValidateGroupPlaceRankingRequest(&dto.GroupPlaceRankingRequest{}))
+	assert.Nil(t, ValidateGroupPlaceRankingRequest(&dto.GroupPlaceRankingRequest{PlaceIDs: []string{"a", "b"}}))
+	assert.NotNil(t, ValidateGroupPlaceRankingRequest(&dto.GroupPlaceRankingRequest{PlaceIDs: make([]string, config.MaxRankedPlaces+1)}))
+}
+
+func TestValidateGroupVenueRequest(t *testing.T) {
+	custom := &dto.CustomVenueRequest{Name: "My place", Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}}
+	assert.Nil(t, ValidateGroupVenueRequest(&dto.GroupVenueRequest{PlaceID: "place1"}))
+	assert.Nil(t, ValidateGroupVenueRequest(&dto.GroupVenueRequest{Custom: custom}))
+	assert.NotNil(t, ValidateGroupVenueRequest(&dto.GroupVenueRequest{}))
+	assert.NotNil(t, ValidateGroupVenueRequest(&dto.GroupVenueRequest{PlaceID: "place1", Custom: custom}))
+	assert.NotNil(t, ValidateGroupVenueRequest(&dto.GroupVenueRequest{Custom: &dto.CustomVenueRequest{Location: custom.Location}}))
+	assert.NotNil(t, ValidateGroupVenueRequest(&dto.GroupVenueRequest{Custom: &dto.CustomVenueRequest{Name: "Nowhere", Location: dto.Location{Latitude: 100}}}))
+}
+
+func TestValidateCustomPlaceAddRequest(t *testing.T) {
+	valid := dto.CustomPlaceAddRequest{Name: "Sam's flat", Address: "2 Rue de Rivoli", Type: config.PlaceTypeCafe, Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}}
+	assert.Nil(t, ValidateCustomPlaceAddRequest(&valid))
+
+	noName, noAddress, badType, badLocation := valid, valid, valid, valid
+	noName.Name = ""
+	noAddress.Address = ""
+	badType.Type = "castle"
+	badLocation.Longitude = 200
+	assert.NotNil(t, ValidateCustomPlaceAddRequest(&noName))
+	assert.NotNil(t, ValidateCustomPlaceAddRequest(&noAddress))
+	assert.NotNil(t, ValidateCustomPlaceAddRequest(&badType))
+	assert.NotNil(t, ValidateCustomPlaceAddRequest(&badLocation))
+}
+
+func TestValidateUpdateGroupRequest_SearchPreferences(t *testing.T) {
+	valid := dto.PlaceSearchPreferences{ResultsPerType: 5, MinRating: 4, MinPriceLevel: config.PriceLevelInexpensive, MaxPriceLevel: config.PriceLevelModerate, OpenNow: true}
+	assert.Nil(t, ValidateUpdateGroupRequest(&dto.UpdateGroupRequest{SearchPreferences: &valid}))
+	assert.Nil(t, ValidateUpdateGroupRequest(&dto.UpdateGroupRequest{SearchPreferences: &dto.PlaceSearchPreferences{}}))
+
+	tooMany, badRating, badPrice, priceRange := valid, valid, valid, valid
+	tooMany.ResultsPerType = config.MaxPlacesResultsPerType + 1
+	badRating.MinRating = 6
+	badPrice.MaxPriceLevel = 9
+	priceRange.MinPriceLevel = config.PriceLevelExpensive
+	assert.NotNil(t, ValidateUpdateGroupRequest(&dto.UpdateGroupRequest{SearchPreferences: &tooMany}))
+	assert.NotNil(t, ValidateUpdateGroupRequest(&dto.UpdateGroupRequest{SearchPreferences: &badRating}))
+	assert.NotNil(t, ValidateUpdateGroupRequest(&dto.UpdateGroupRequest{SearchPreferences: &badPrice}))
+	assert.NotNil(t, ValidateCreateGroupRequest(&dto.CreateGroupRequest{Name: "Test Group", SearchPreferences: &priceRange}))
+}
@@ -0,0 +1,52 @@
+package validators
+
+import (
+	"strconv"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ValidatePlaceSearchRequest(req *dto.PlaceSearchRequest) *ValidationError {
+	if err := ValidateLocation(req.Location); err != nil {
+		return err
+	}
+	if req.Radius < 1 || req.Radius > config.MaxPlaceSearchRadius {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Radius must be between 1 and " + strconv.Itoa(config.MaxPlaceSearchRadius) + " meters",
+		}
+	}
+	if req.PlaceType == "" && req.Text == "" {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Search needs a place type, a query or both",
+		}
+	}
+	if req.PlaceType != "" && !config.IsSupportedPlaceType(req.PlaceType) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Invalid place type",
+		}
+	}
+	if len(req.Text) > config.MaxPlaceSearchTextLength {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Query must be at most " + strconv.Itoa(config.MaxPlaceSearchTextLength) + " characters",
+		}
+	}
+	if req.Page < 1 {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Page must be 1 or more",
+		}
+	}
+	if req.PageSize < 1 || req.PageSize > config.MaxPlacesResultsPerType {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Page size must be between 1 and " + strconv.Itoa(config.MaxPlacesResultsPerType),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package validators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePlaceSearchRequest(t *testing.T) {
+	valid := dto.PlaceSearchRequest{Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}, Radius: 1000, PlaceType: config.PlaceTypeCafe, Page: 1, PageSize: 10}
+	assert.Nil(t, ValidatePlaceSearchRequest(&valid))
+	textOnly := valid
+	textOnly.PlaceType = ""
+	textOnly.Text = "croissant"
+	assert.Nil(t, ValidatePlaceSearchRequest(&textOnly))
+
+	noRadius, tooFar, nothing, badType, longText, noPage, bigPage := valid, valid, valid, valid, valid, valid, valid
+	noRadius.Radius = 0
+	tooFar.Radius = config.MaxPlaceSearchRadius + 1
+	nothing.PlaceType = ""
+	badType.PlaceType = "castle"
+	longText.Text = strings.Repeat("a", config.MaxPlaceSearchTextLength+1)
+	noPage.Page = 0
+	bigPage.PageSize = config.MaxPlacesResultsPerType + 1
+	for _, invalid := range []dto.PlaceSearchRequest{noRadius, tooFar, nothing, badType, longText, noPage, bigPage} {
+		assert.NotNil(t, ValidatePlaceSearchRequest(&invalid), invalid)
+	}
+}
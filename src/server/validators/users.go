@@ -2,7 +2,9 @@ package validators
 
 import (
 	"regexp"
+	"strconv"
 
+	"github.com/championswimmer/api.midpoint.place/src/config"
 	"github.com/championswimmer/api.midpoint.place/src/dto"
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,6 +24,9 @@ var invalidDisplayNameLengthError = &ValidationError{
 	message: "Display name must be between 3 and 25 characters long",
 }
 
+// Saved location names are short words like "home" or "work"
+var savedLocationNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9 _-]*$`)
+
 // Basic email regex, can be improved for more strict validation
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\'-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 
@@ -63,3 +68,24 @@ func ValidateLocation(location dto.Location) *ValidationError {
 	}
 	return nil
 }
+
+// ValidateAddress checks an address to geocode, an empty one meaning the location is sent instead
+func ValidateAddress(address string) *ValidationError {
+	if len(address) > config.MaxAddressLength {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Address must be at most " + strconv.Itoa(config.MaxAddressLength) + " characters",
+		}
+	}
+	return nil
+}
+
+func ValidateSavedLocationName(name string) *ValidationError {
+	if len(name) > config.MaxSavedLocationNameLength || !savedLocationNameRegex.MatchString(name) {
+		return &ValidationError{
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Saved location names must be up to " + strconv.Itoa(config.MaxSavedLocationNameLength) + " letters, digits, spaces, dashes or underscores",
+		}
+	}
+	return nil
+}
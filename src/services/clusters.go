@@ -0,0 +1,167 @@
+package services
+
+import (
+	"math"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+)
+
+const (
+	// k-means stops after this many iterations even if some points are still moving between clusters
+	kMeansMaxIterations = 100
+	// a DBSCAN core point needs this many locations (including itself) within the radius
+	dbscanMinPoints = 2
+)
+
+// KMeansClusters splits the locations into (at most) k clusters with spherical k-means
+// Returns the cluster of each location, numbered from 0 in order of first appearance
+// Starting centers are picked farthest-first, so the result is deterministic
+func KMeansClusters(locations []dto.Location, k int) []int {
+	labels := make([]int, len(locations))
+	if len(locations) == 0 || k <= 1 {
+		return labels
+	}
+	if k > len(locations) {
+		k = len(locations)
+	}
+
+	points := make([][3]float64, len(locations))
+	for i, loc := range locations {
+		points[i] = toUnitVector(loc)
+	}
+
+	// farthest-first: start with the location farthest from the centroid,
+	// then keep adding the location farthest from all the centers picked so far
+	mean := toUnitVector(geo.Centroid(locations))
+	centers := [][3]float64{points[farthestPoint(points, func(p [3]float64) float64 { return dot(p, mean) })]}
+	for len(centers) < k {
+		centers = append(centers, points[farthestPoint(points, func(p [3]float64) float64 {
+			closest := -1.0
+			for _, center := range centers {
+				closest = math.Max(closest, dot(p, center))
+			}
+			return closest
+		})])
+	}
+
+	for i := range labels {
+		labels[i] = -1
+	}
+	for iteration := 0; iteration < kMeansMaxIterations; iteration++ {
+		changed := false
+		for i, p := range points {
+			best, bestDot := 0, math.Inf(-1)
+			for c, center := range centers {
+				if d := dot(p, center); d > bestDot {
+					best, bestDot = c, d
+				}
+			}
+			if labels[i] != best {
+				labels[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		sums := make([][3]float64, k)
+		for i, p := range points {
+			for axis := range p {
+				sums[labels[i]][axis] += p[axis]
+			}
+		}
+		for c, sum := range sums {
+			// an empty cluster keeps its old center
+			if norm := math.Sqrt(dot(sum, sum)); norm > 0 {
+				centers[c] = [3]float64{sum[0] / norm, sum[1] / norm, sum[2] / norm}
+			}
+		}
+	}
+	return renumberClusters(labels)
+}
+
+// DBSCANClusters puts locations within radiusKm of each other in the same cluster
+// Clusters grow through chains of nearby locations, so a cluster can be wider than the radius.
+// Locations with no neighbours are noise, and aren't in any cluster.
+// Returns the cluster of each location, numbered from 0 in order of first appearance, or -1 for noise
+func DBSCANClusters(locations []dto.Location, radiusKm float64) []int {
+	labels := make([]int, len(locations))
+	for i := range labels {
+		labels[i] = -1
+	}
+
+	neighbours := func(i int) []int {
+		var result []int
+		for j, loc := range locations {
+			if geo.DistanceKm(locations[i], loc) <= radiusKm {
+				result = append(result, j)
+			}
+		}
+		return result
+	}
+
+	cluster := 0
+	for i := range locations {
+		if labels[i] >= 0 {
+			continue
+		}
+		seeds := neighbours(i)
+		if len(seeds) < dbscanMinPoints {
+			// noise, it stays out of the clusters unless a later cluster reaches it
+			continue
+		}
+		labels[i] = cluster
+		for len(seeds) > 0 {
+			j := seeds[0]
+			seeds = seeds[1:]
+			if labels[j] >= 0 && j != i {
+				continue
+			}
+			labels[j] = cluster
+			if next := neighbours(j); len(next) >= dbscanMinPoints {
+				for _, n := range next {
+					if labels[n] < 0 {
+						seeds = append(seeds, n)
+					}
+				}
+			}
+		}
+		cluster++
+	}
+	return labels
+}
+
+// renumberClusters relabels clusters in order of first appearance, dropping unused labels
+func renumberClusters(labels []int) []int {
+	mapping := map[int]int{}
+	for i, label := range labels {
+		if _, ok := mapping[label]; !ok {
+			mapping[label] = len(mapping)
+		}
+		labels[i] = mapping[label]
+	}
+	return labels
+}
+
+// farthestPoint returns the index of the point with the lowest similarity
+func farthestPoint(points [][3]float64, similarity func([3]float64) float64) int {
+	farthest, lowest := 0, math.Inf(1)
+	for i, p := range points {
+		if s := similarity(p); s < lowest {
+			farthest, lowest = i, s
+		}
+	}
+	return farthest
+}
+
+func toUnitVector(loc dto.Location) [3]float64 {
+	lat := loc.Latitude * math.Pi / 180
+	lng := loc.Longitude * math.Pi / 180
+	return [3]float64{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+func dot(a [3]float64, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
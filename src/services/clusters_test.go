@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// two neighbourhoods of London, ~15km apart
+var clusterTestLocations = []dto.Location{
+	{Latitude: 51.5450, Longitude: -0.2050}, // Kilburn
+	{Latitude: 51.4620, Longitude: -0.0100}, // Lewisham
+	{Latitude: 51.5470, Longitude: -0.2000},
+	{Latitude: 51.4600, Longitude: -0.0150},
+	{Latitude: 51.5430, Longitude: -0.2080},
+	{Latitude: 51.4650, Longitude: -0.0120},
+}
+
+func TestKMeansClusters(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 0, 1, 0, 1}, KMeansClusters(clusterTestLocations, 2))
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 0}, KMeansClusters(clusterTestLocations, 1))
+	// never more clusters than locations
+	assert.Equal(t, []int{0, 1}, KMeansClusters(clusterTestLocations[:2], 5))
+	assert.Empty(t, KMeansClusters(nil, 3))
+}
+
+func TestDBSCANClusters(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 0, 1, 0, 1}, DBSCANClusters(clusterTestLocations, 2))
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 0}, DBSCANClusters(clusterTestLocations, 20))
+}
+
+func TestDBSCANClusters_ChainsAndNoise(t *testing.T) {
+	locations := []dto.Location{
+		{Latitude: 51.50, Longitude: -0.100},
+		{Latitude: 51.50, Longitude: -0.090}, // ~0.7km from the previous one
+		{Latitude: 51.50, Longitude: -0.080}, // ~1.4km from the first, but chained through the second
+		{Latitude: 51.60, Longitude: -0.100}, // ~11km from everyone, noise
+	}
+	assert.Equal(t, []int{0, 0, 0, -1}, DBSCANClusters(locations, 1))
+	// the noise comes first, later clusters are still numbered from 0
+	assert.Equal(t, []int{-1, 0, 0, 0}, DBSCANClusters(append(locations[3:], locations[:3]...), 1))
+	assert.Equal(t, []int{-1, -1}, DBSCANClusters([]dto.Location{locations[0], locations[3]}, 1))
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"math"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+)
+
+// DistanceFairness summarises how evenly distances are spread between members
+// Gini is the mean absolute difference between all pairs, relative to twice the mean
+func DistanceFairness(distances []float64) dto.FairnessMetrics {
+	var metrics dto.FairnessMetrics
+	if len(distances) == 0 {
+		return metrics
+	}
+
+	var sum float64
+	for _, d := range distances {
+		sum += d
+		metrics.MaxKm = math.Max(metrics.MaxKm, d)
+	}
+	n := float64(len(distances))
+	metrics.MeanKm = sum / n
+
+	var squaredDeviations, pairDifferences float64
+	for _, a := range distances {
+		squaredDeviations += (a - metrics.MeanKm) * (a - metrics.MeanKm)
+		for _, b := range distances {
+			pairDifferences += math.Abs(a - b)
+		}
+	}
+	metrics.StdDevKm = math.Sqrt(squaredDeviations / n)
+	if metrics.MeanKm > 0 {
+		metrics.Gini = pairDifferences / (2 * n * n * metrics.MeanKm)
+	}
+	return metrics
+}
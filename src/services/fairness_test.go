@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceFairness(t *testing.T) {
+	even := DistanceFairness([]float64{5, 5, 5, 5})
+	assert.Equal(t, 5.0, even.MaxKm)
+	assert.Equal(t, 5.0, even.MeanKm)
+	assert.Equal(t, 0.0, even.StdDevKm)
+	assert.Equal(t, 0.0, even.Gini)
+
+	uneven := DistanceFairness([]float64{0, 0, 0, 12})
+	assert.Equal(t, 12.0, uneven.MaxKm)
+	assert.Equal(t, 3.0, uneven.MeanKm)
+	assert.InDelta(t, 5.196, uneven.StdDevKm, 0.001)
+	// one member does all the travelling, the most unfair a group of 4 can be
+	assert.InDelta(t, 0.75, uneven.Gini, 0.0001)
+
+	assert.Equal(t, 0.0, DistanceFairness(nil).Gini)
+	assert.Equal(t, 0.0, DistanceFairness([]float64{0, 0}).Gini)
+}
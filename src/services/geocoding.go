@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/samber/lo"
+)
+
+// ErrAddressNotFound is returned when an address can't be geocoded, or a location has no known neighbourhood
+var ErrAddressNotFound = errors.New("address not found")
+
+// GeocodedLocation is where an address is, along with the name the geocoder knows it by
+type GeocodedLocation struct {
+	dto.Location
+	Label string
+}
+
+// Geocoder turns addresses and place names into locations, and locations into neighbourhood names
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (*GeocodedLocation, error)
+	// ReverseGeocode returns a human readable label for the neighbourhood of a location, e.g. "Soho, London"
+	ReverseGeocode(ctx context.Context, location dto.Location) (string, error)
+}
+
+var geocoder Geocoder
+var geocoderOnce sync.Once
+
+// GetGeocoder returns the geocoder chosen by config, or nil if addresses are not geocoded
+func GetGeocoder() Geocoder {
+
+	geocoderOnce.Do(func() {
+		switch config.Geocoder {
+		case "", "none":
+			return
+		case "google":
+			applogger.Warn("App: Using Google geocoding")
+			geocoder = NewGoogleGeocoder(config.GoogleMapsAPIKey)
+		case "gazetteer":
+			applogger.Warn("App: Loading gazetteer from", config.GazetteerFile)
+			geocoder = lo.Must(LoadGazetteerGeocoder(config.GazetteerFile, config.GazetteerMaxDistance))
+		default:
+			panic("Geocoder config incorrect")
+		}
+	})
+
+	return geocoder
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+// GazetteerPlace is a named place of a gazetteer
+type GazetteerPlace struct {
+	Name        string
+	Names       []string
+	Location    dto.Location
+	CountryCode string
+	Population  int64
+}
+
+// GazetteerGeocoder geocodes offline, from a list of named places such as a GeoNames dump
+// Places are scanned one by one, which is fast enough for the cities and neighbourhoods of a few countries.
+type GazetteerGeocoder struct {
+	places []GazetteerPlace
+	// maxDistance in meters, locations further than it from every place have no neighbourhood
+	maxDistance int
+}
+
+func NewGazetteerGeocoder(maxDistance int) *GazetteerGeocoder {
+	return &GazetteerGeocoder{maxDistance: maxDistance}
+}
+
+func (g *GazetteerGeocoder) AddPlace(place GazetteerPlace) {
+	place.Names = lo.Uniq(lo.Compact(lo.Map(append([]string{place.Name}, place.Names...), func(name string, _ int) string {
+		return normalizePlaceName(name)
+	})))
+	g.places = append(g.places, place)
+}
+
+// LoadGazetteerGeocoder reads a file in the GeoNames format, tab or comma separated:
+// geonameid, name, asciiname, alternatenames, latitude, longitude, feature class, feature code, country code, cc2,
+// admin1 to admin4 codes, population, ... Only the columns up to longitude are needed, lines starting with # are skipped,
+// as is a header line.
+func LoadGazetteerGeocoder(path string, maxDistance int) (*GazetteerGeocoder, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 4096)
+	n, err := file.Read(header)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.Comma = ','
+	firstLine, _ := lo.Find(strings.Split(string(header[:n]), "\n"), func(line string) bool {
+		return !strings.HasPrefix(line, "#")
+	})
+	if strings.Contains(firstLine, "\t") {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	geocoder := NewGazetteerGeocoder(maxDistance)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid gazetteer file: %w", err)
+		}
+		if len(record) < 6 {
+			return nil, fmt.Errorf("invalid gazetteer file: line %d has %d columns", line, len(record))
+		}
+		latitude, latErr := strconv.ParseFloat(record[4], 64)
+		longitude, lngErr := strconv.ParseFloat(record[5], 64)
+		if latErr != nil || lngErr != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("invalid gazetteer file: line %d has no location", line)
+		}
+
+		place := GazetteerPlace{
+			Name:     record[1],
+			Names:    append([]string{record[2]}, strings.Split(record[3], ",")...),
+			Location: dto.Location{Latitude: latitude, Longitude: longitude},
+		}
+		if len(record) > 8 {
+			place.CountryCode = strings.ToUpper(record[8])
+		}
+		if len(record) > 14 {
+			place.Population, _ = strconv.ParseInt(record[14], 10, 64)
+		}
+		geocoder.AddPlace(place)
+	}
+	return geocoder, nil
+}
+
+// Geocode finds the most populous place with the name, the address can go on with a country code ("Paris, FR")
+// or the name of a place nearby ("Soho, London"), which picks the place with the name nearest to it
+func (g *GazetteerGeocoder) Geocode(_ context.Context, address string) (*GeocodedLocation, error) {
+	parts := lo.Compact(lo.Map(strings.Split(address, ","), func(part string, _ int) string {
+		return normalizePlaceName(part)
+	}))
+	if len(parts) == 0 {
+		return nil, ErrAddressNotFound
+	}
+
+	candidates := g.named(parts[0])
+	var near *GazetteerPlace
+	for _, qualifier := range parts[1:] {
+		if len(qualifier) == 2 {
+			inCountry := lo.Filter(candidates, func(place *GazetteerPlace, _ int) bool {
+				return strings.EqualFold(place.CountryCode, qualifier)
+			})
+			if len(inCountry) > 0 {
+				candidates = inCountry
+				continue
+			}
+		}
+		if places := g.named(qualifier); len(places) > 0 {
+			near = mostPopulousPlace(places)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrAddressNotFound
+	}
+
+	place := mostPopulousPlace(candidates)
+	if near != nil {
+		place = lo.MinBy(candidates, func(a *GazetteerPlace, b *GazetteerPlace) bool {
+			return geo.DistanceKm(a.Location, near.Location) < geo.DistanceKm(b.Location, near.Location)
+		})
+	}
+	label := place.Name
+	if place.CountryCode != "" {
+		label += ", " + place.CountryCode
+	}
+	return &GeocodedLocation{Location: place.Location, Label: label}, nil
+}
+
+// ReverseGeocode labels a location with the nearest place, followed by the most populous place
+// within the max distance if that's another one ("Soho, London")
+func (g *GazetteerGeocoder) ReverseGeocode(_ context.Context, location dto.Location) (string, error) {
+	maxDistanceKm := float64(g.maxDistance) / 1000
+	var nearby []*GazetteerPlace
+	for i := range g.places {
+		if geo.DistanceKm(location, g.places[i].Location) <= maxDistanceKm {
+			nearby = append(nearby, &g.places[i])
+		}
+	}
+	if len(nearby) == 0 {
+		return "", ErrAddressNotFound
+	}
+
+	nearest := lo.MinBy(nearby, func(a *GazetteerPlace, b *GazetteerPlace) bool {
+		return geo.DistanceKm(location, a.Location) < geo.DistanceKm(location, b.Location)
+	})
+	largest := mostPopulousPlace(nearby)
+	if largest.Name == nearest.Name {
+		return nearest.Name, nil
+	}
+	return nearest.Name + ", " + largest.Name, nil
+}
+
+func (g *GazetteerGeocoder) named(name string) []*GazetteerPlace {
+	var places []*GazetteerPlace
+	for i := range g.places {
+		if lo.Contains(g.places[i].Names, name) {
+			places = append(places, &g.places[i])
+		}
+	}
+	return places
+}
+
+func mostPopulousPlace(places []*GazetteerPlace) *GazetteerPlace {
+	return lo.MaxBy(places, func(a *GazetteerPlace, b *GazetteerPlace) bool {
+		return a.Population > b.Population
+	})
+}
+
+func normalizePlaceName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
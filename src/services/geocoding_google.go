@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/samber/lo"
+)
+
+const googleGeocodingURL = "https://maps.googleapis.com/maps/api/geocode/json"
+
+// GoogleGeocoder uses the Google Geocoding API
+type GoogleGeocoder struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewGoogleGeocoder(apiKey string) *GoogleGeocoder {
+	return &GoogleGeocoder{
+		baseURL:    googleGeocodingURL,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type googleGeocodingResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Results      []struct {
+		FormattedAddress  string `json:"formatted_address"`
+		AddressComponents []struct {
+			LongName string   `json:"long_name"`
+			Types    []string `json:"types"`
+		} `json:"address_components"`
+		Geometry struct {
+			Location struct {
+				Lat float64 `json:"lat"`
+				Lng float64 `json:"lng"`
+			} `json:"location"`
+		} `json:"geometry"`
+	} `json:"results"`
+}
+
+func (g *GoogleGeocoder) Geocode(ctx context.Context, address string) (*GeocodedLocation, error) {
+	geocoding, err := g.geocode(ctx, url.Values{"address": {address}})
+	if err != nil {
+		return nil, err
+	}
+	result := geocoding.Results[0]
+	return &GeocodedLocation{
+		Location: dto.Location{Latitude: result.Geometry.Location.Lat, Longitude: result.Geometry.Location.Lng},
+		Label:    result.FormattedAddress,
+	}, nil
+}
+
+// ReverseGeocode labels a location with its neighbourhood (or sublocality) and locality
+func (g *GoogleGeocoder) ReverseGeocode(ctx context.Context, location dto.Location) (string, error) {
+	latlng := strconv.FormatFloat(location.Latitude, 'f', 6, 64) + "," + strconv.FormatFloat(location.Longitude, 'f', 6, 64)
+	geocoding, err := g.geocode(ctx, url.Values{"latlng": {latlng}})
+	if err != nil {
+		return "", err
+	}
+
+	// results go from the most to the least precise, so the first name found for each type is the nearest
+	names := map[string]string{}
+	for _, result := range geocoding.Results {
+		for _, component := range result.AddressComponents {
+			for _, componentType := range component.Types {
+				if _, ok := names[componentType]; !ok {
+					names[componentType] = component.LongName
+				}
+			}
+		}
+	}
+	neighbourhood, _ := lo.Coalesce(names["neighborhood"], names["sublocality_level_1"], names["sublocality"])
+	label := strings.Join(lo.Uniq(lo.Compact([]string{neighbourhood, names["locality"]})), ", ")
+	if label == "" {
+		return "", ErrAddressNotFound
+	}
+	return label, nil
+}
+
+func (g *GoogleGeocoder) geocode(ctx context.Context, params url.Values) (*googleGeocodingResponse, error) {
+	params.Set("key", g.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var geocoding googleGeocodingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geocoding); err != nil {
+		return nil, fmt.Errorf("invalid geocoding response (status %d): %w", resp.StatusCode, err)
+	}
+	switch geocoding.Status {
+	case "OK":
+	case "ZERO_RESULTS":
+		return nil, ErrAddressNotFound
+	default:
+		return nil, fmt.Errorf("geocoding request failed: %s %s", geocoding.Status, geocoding.ErrorMessage)
+	}
+	if len(geocoding.Results) == 0 {
+		return nil, ErrAddressNotFound
+	}
+	return &geocoding, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// a few places in the GeoNames format, tab separated
+const testGazetteerTSV = "# places in London, Paris and New York\n" +
+	"2643743\tLondon\tLondon\tLondres,Londra\t51.50853\t-0.12574\tP\tPPLC\tGB\t\tENG\tGLA\t\t\t8961989\t\t25\tEurope/London\t2024-01-01\n" +
+	"6545173\tSoho\tSoho\t\t51.51357\t-0.13680\tP\tPPLX\tGB\t\tENG\tGLA\t\t\t0\t\t28\tEurope/London\t2024-01-01\n" +
+	"2988507\tParis\tParis\tParigi\t48.85341\t2.3488\tP\tPPLC\tFR\t\t11\t75\t\t\t2138551\t\t42\tEurope/Paris\t2024-01-01\n" +
+	"4717560\tParis\tParis\t\t33.66094\t-95.55551\tP\tPPLA2\tUS\t\tTX\t277\t\t\t24782\t\t183\tAmerica/Chicago\t2024-01-01\n" +
+	"9999999\tSoho\tSoho\t\t40.72330\t-74.00300\tP\tPPLX\tUS\t\tNY\t061\t\t\t0\t\t10\tAmerica/New_York\t2024-01-01\n" +
+	"5128581\tNew York City\tNew York City\tNYC\t40.71427\t-74.00597\tP\tPPL\tUS\t\tNY\t\t\t\t8804190\t\t10\tAmerica/New_York\t2024-01-01\n"
+
+const testGazetteerCSV = `geonameid,name,asciiname,alternatenames,latitude,longitude,feature class,feature code,country code
+# cities only
+2643743,London,London,"Londres,Londra",51.50853,-0.12574,P,PPLC,GB
+2988507,Paris,Paris,,48.85341,2.3488,P,PPLC,FR
+`
+
+func loadTestGazetteer(t *testing.T, name string, contents string) *GazetteerGeocoder {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	geocoder, err := LoadGazetteerGeocoder(path, 5000)
+	require.NoError(t, err)
+	return geocoder
+}
+
+func TestGazetteerGeocoder_Geocode(t *testing.T) {
+	gazetteer := loadTestGazetteer(t, "gazetteer.txt", testGazetteerTSV)
+	ctx := context.Background()
+
+	t.Run("most populous place with the name", func(t *testing.T) {
+		geocoded, err := gazetteer.Geocode(ctx, "  paris ")
+		assert.NoError(t, err)
+		assert.Equal(t, "Paris, FR", geocoded.Label)
+		assert.InDelta(t, 48.85341, geocoded.Latitude, 0.00001)
+	})
+
+	t.Run("alternate names", func(t *testing.T) {
+		geocoded, err := gazetteer.Geocode(ctx, "Londres")
+		assert.NoError(t, err)
+		assert.Equal(t, "London, GB", geocoded.Label)
+	})
+
+	t.Run("country code", func(t *testing.T) {
+		geocoded, err := gazetteer.Geocode(ctx, "Paris, US")
+		assert.NoError(t, err)
+		assert.InDelta(t, 33.66094, geocoded.Latitude, 0.00001)
+	})
+
+	t.Run("place nearby", func(t *testing.T) {
+		geocoded, err := gazetteer.Geocode(ctx, "Soho, New York City")
+		assert.NoError(t, err)
+		assert.InDelta(t, 40.72330, geocoded.Latitude, 0.00001)
+
+		geocoded, err = gazetteer.Geocode(ctx, "Soho, London")
+		assert.NoError(t, err)
+		assert.InDelta(t, 51.51357, geocoded.Latitude, 0.00001)
+	})
+
+	t.Run("unknown address", func(t *testing.T) {
+		_, err := gazetteer.Geocode(ctx, "Atlantis")
+		assert.ErrorIs(t, err, ErrAddressNotFound)
+		_, err = gazetteer.Geocode(ctx, " , ")
+		assert.ErrorIs(t, err, ErrAddressNotFound)
+	})
+}
+
+func TestGazetteerGeocoder_ReverseGeocode(t *testing.T) {
+	gazetteer := loadTestGazetteer(t, "gazetteer.txt", testGazetteerTSV)
+	ctx := context.Background()
+
+	label, err := gazetteer.ReverseGeocode(ctx, dto.Location{Latitude: 51.5130, Longitude: -0.1350})
+	assert.NoError(t, err)
+	assert.Equal(t, "Soho, London", label)
+
+	label, err = gazetteer.ReverseGeocode(ctx, dto.Location{Latitude: 48.8566, Longitude: 2.3522})
+	assert.NoError(t, err)
+	assert.Equal(t, "Paris", label)
+
+	// the middle of the Atlantic is far from everything
+	_, err = gazetteer.ReverseGeocode(ctx, dto.Location{Latitude: 40, Longitude: -40})
+	assert.ErrorIs(t, err, ErrAddressNotFound)
+}
+
+func TestLoadGazetteerGeocoder_CSV(t *testing.T) {
+	gazetteer := loadTestGazetteer(t, "gazetteer.csv", testGazetteerCSV)
+	assert.Len(t, gazetteer.places, 2)
+
+	geocoded, err := gazetteer.Geocode(context.Background(), "Londra")
+	assert.NoError(t, err)
+	assert.InDelta(t, 51.50853, geocoded.Latitude, 0.00001)
+
+	path := filepath.Join(t.TempDir(), "broken.csv")
+	require.NoError(t, os.WriteFile(path, []byte("1,London,London,,51.5\n"), 0o644))
+	_, err = LoadGazetteerGeocoder(path, 5000)
+	assert.Error(t, err)
+}
+
+func TestGoogleGeocoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.URL.Query().Get("key"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Query().Get("address") == "Nowhere":
+			_, _ = w.Write([]byte(`{"status": "ZERO_RESULTS", "results": []}`))
+		case r.URL.Query().Get("address") != "":
+			_, _ = w.Write([]byte(`{"status": "OK", "results": [{"formatted_address": "Soho, London, UK",
+				"geometry": {"location": {"lat": 51.5136, "lng": -0.1365}}}]}`))
+		case r.URL.Query().Get("latlng") == "51.513600,-0.136500":
+			_, _ = w.Write([]byte(`{"status": "OK", "results": [
+				{"address_components": [{"long_name": "1", "types": ["street_number"]},
+					{"long_name": "Soho", "types": ["neighborhood", "political"]},
+					{"long_name": "London", "types": ["postal_town"]}]},
+				{"address_components": [{"long_name": "London", "types": ["locality", "political"]}]}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"status": "REQUEST_DENIED", "error_message": "bad key"}`))
+		}
+	}))
+	defer server.Close()
+
+	geocoder := NewGoogleGeocoder("test-key")
+	geocoder.baseURL = server.URL
+	ctx := context.Background()
+
+	geocoded, err := geocoder.Geocode(ctx, "Soho")
+	assert.NoError(t, err)
+	assert.Equal(t, "Soho, London, UK", geocoded.Label)
+	assert.InDelta(t, 51.5136, geocoded.Latitude, 0.00001)
+
+	_, err = geocoder.Geocode(ctx, "Nowhere")
+	assert.ErrorIs(t, err, ErrAddressNotFound)
+
+	label, err := geocoder.ReverseGeocode(ctx, dto.Location{Latitude: 51.5136, Longitude: -0.1365})
+	assert.NoError(t, err)
+	assert.Equal(t, "Soho, London", label)
+
+	_, err = geocoder.ReverseGeocode(ctx, dto.Location{Latitude: 1, Longitude: 1})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrAddressNotFound)
+}
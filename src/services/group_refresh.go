@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+)
+
+// GroupRefreshScheduler debounces group refreshes (midpoint, clusters and places)
+// A refresh runs once no more triggers have arrived for delay, or maxWait after the first trigger,
+// so a burst of joins collapses into a single run. Only one refresh of a group runs at a time;
+// triggers arriving while it runs cause one more run afterwards.
+type GroupRefreshScheduler struct {
+	delay   time.Duration
+	maxWait time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	groups  map[string]*groupRefresh
+	pending sync.WaitGroup
+	closed  bool
+}
+
+type groupRefresh struct {
+	refresh   func(groupID string)
+	timer     *time.Timer
+	firstAt   time.Time
+	running   bool
+	triggered bool
+}
+
+func NewGroupRefreshScheduler(delay time.Duration, maxWait time.Duration) *GroupRefreshScheduler {
+	return &GroupRefreshScheduler{
+		delay:   delay,
+		maxWait: max(maxWait, delay),
+		now:     time.Now,
+		groups:  map[string]*groupRefresh{},
+	}
+}
+
+var groupRefreshScheduler *GroupRefreshScheduler
+var groupRefreshSchedulerOnce sync.Once
+
+// GetGroupRefreshScheduler returns the scheduler configured by GROUP_REFRESH_DELAY and GROUP_REFRESH_MAX_WAIT
+func GetGroupRefreshScheduler() *GroupRefreshScheduler {
+	groupRefreshSchedulerOnce.Do(func() {
+		applogger.Warn("App: Debouncing group refreshes by", config.GroupRefreshDelay)
+		groupRefreshScheduler = NewGroupRefreshScheduler(config.GroupRefreshDelay, config.GroupRefreshMaxWait)
+	})
+	return groupRefreshScheduler
+}
+
+// Schedule runs refresh for the group after the debounce delay, unless a run is already scheduled,
+// in which case that run is pushed back (up to maxWait) and uses this refresh func instead
+func (s *GroupRefreshScheduler) Schedule(groupID string, refresh func(groupID string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		applogger.Warn("Dropping refresh of group", groupID, "as the app is shutting down")
+		return
+	}
+
+	state, ok := s.groups[groupID]
+	if !ok {
+		state = &groupRefresh{}
+		s.groups[groupID] = state
+		s.pending.Add(1)
+	}
+	state.refresh = refresh
+
+	switch {
+	case state.running:
+		state.triggered = true
+	case state.timer == nil:
+		state.firstAt = s.now()
+		state.timer = time.AfterFunc(s.delay, func() { s.run(groupID) })
+	case state.timer.Stop():
+		state.timer.Reset(s.nextRunIn(state))
+	default:
+		// the timer has fired but the run has not started yet, it will see this change anyway
+	}
+}
+
+// IsPending is true while a refresh of the group is waiting to run, or running
+func (s *GroupRefreshScheduler) IsPending(groupID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.groups[groupID]
+	return ok
+}
+
+// Shutdown stops accepting refreshes, runs the waiting ones right away, and waits for them to finish
+func (s *GroupRefreshScheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	for groupID, state := range s.groups {
+		if state.timer != nil && state.timer.Stop() {
+			state.timer = nil
+			go s.run(groupID)
+		}
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *GroupRefreshScheduler) nextRunIn(state *groupRefresh) time.Duration {
+	if s.closed {
+		return 0
+	}
+	return min(s.delay, s.maxWait-s.now().Sub(state.firstAt))
+}
+
+func (s *GroupRefreshScheduler) run(groupID string) {
+	s.mu.Lock()
+	state := s.groups[groupID]
+	state.timer = nil
+	state.running = true
+	refresh := state.refresh
+	s.mu.Unlock()
+
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				applogger.Error("Refresh of group", groupID, "panicked", err)
+			}
+		}()
+		refresh(groupID)
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state.running = false
+	if state.triggered {
+		state.triggered = false
+		state.firstAt = s.now()
+		state.timer = time.AfterFunc(s.nextRunIn(state), func() { s.run(groupID) })
+		return
+	}
+	delete(s.groups, groupID)
+	s.pending.Done()
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupRefreshScheduler_CoalescesBursts(t *testing.T) {
+	scheduler := NewGroupRefreshScheduler(20*time.Millisecond, time.Second)
+	var runs atomic.Int32
+	refresh := func(string) { runs.Add(1) }
+
+	for i := 0; i < 20; i++ {
+		scheduler.Schedule("group1", refresh)
+	}
+	assert.True(t, scheduler.IsPending("group1"))
+	assert.False(t, scheduler.IsPending("group2"))
+
+	assert.Eventually(t, func() bool { return !scheduler.IsPending("group1") }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestGroupRefreshScheduler_MaxWait(t *testing.T) {
+	scheduler := NewGroupRefreshScheduler(50*time.Millisecond, 100*time.Millisecond)
+	var runs atomic.Int32
+	refresh := func(string) { runs.Add(1) }
+
+	// keep triggering more often than the delay, the max wait still lets a refresh through
+	start := time.Now()
+	for time.Since(start) < 300*time.Millisecond {
+		scheduler.Schedule("group1", refresh)
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.GreaterOrEqual(t, runs.Load(), int32(2))
+}
+
+func TestGroupRefreshScheduler_RerunsAfterTriggerDuringRun(t *testing.T) {
+	scheduler := NewGroupRefreshScheduler(time.Millisecond, time.Second)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var running, maxRunning, runs atomic.Int32
+	var once sync.Once
+	refresh := func(string) {
+		if n := running.Add(1); n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		defer running.Add(-1)
+		runs.Add(1)
+		once.Do(func() {
+			close(started)
+			<-release
+		})
+	}
+
+	scheduler.Schedule("group1", refresh)
+	<-started
+	scheduler.Schedule("group1", refresh)
+	scheduler.Schedule("group1", refresh)
+	close(release)
+
+	assert.Eventually(t, func() bool { return !scheduler.IsPending("group1") }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), runs.Load())
+	assert.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestGroupRefreshScheduler_Shutdown(t *testing.T) {
+	scheduler := NewGroupRefreshScheduler(time.Hour, time.Hour)
+	var runs atomic.Int32
+	refresh := func(string) { runs.Add(1) }
+
+	scheduler.Schedule("group1", refresh)
+	scheduler.Schedule("group2", refresh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, scheduler.Shutdown(ctx))
+	assert.Equal(t, int32(2), runs.Load())
+
+	// refreshes after shutdown are dropped
+	scheduler.Schedule("group3", refresh)
+	assert.False(t, scheduler.IsPending("group3"))
+}
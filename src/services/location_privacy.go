@@ -0,0 +1,69 @@
+package services
+
+import (
+	"math"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+// MaskMemberLocations shows the members' locations as the viewer may see them: exactly for the viewer's own
+// membership, and at the member's privacy level for everyone else's
+func MaskMemberLocations(members []dto.GroupUserResponse, viewerID uint) []dto.GroupUserResponse {
+	return lo.Map(members, func(member dto.GroupUserResponse, _ int) dto.GroupUserResponse {
+		if member.UserID == viewerID {
+			return member
+		}
+		location := MaskLocation(dto.Location{Latitude: member.Latitude, Longitude: member.Longitude}, member.LocationPrivacy)
+		member.Latitude, member.Longitude = location.Latitude, location.Longitude
+		return member
+	})
+}
+
+// MaskLocation is where a member is shown to be to the other members, 0, 0 if the location is hidden
+func MaskLocation(location dto.Location, privacy config.LocationPrivacy) dto.Location {
+	switch privacy {
+	case config.LocationPrivacyExact:
+		return location
+	case config.LocationPrivacyHidden:
+		return dto.Location{}
+	default:
+		return geo.SnapToGrid(location, config.ApproximateLocationGridSize)
+	}
+}
+
+// LeastExactLocationPrivacy is the privacy of a midpoint of members with the privacies, as the midpoint of a
+// single member is their location: hidden if any of them is hidden, exact only if all of them are exact
+func LeastExactLocationPrivacy(privacies []config.LocationPrivacy) config.LocationPrivacy {
+	privacy := config.LocationPrivacyExact
+	for _, memberPrivacy := range privacies {
+		if memberPrivacy == config.LocationPrivacyHidden {
+			return config.LocationPrivacyHidden
+		}
+		if memberPrivacy != config.LocationPrivacyExact {
+			privacy = config.LocationPrivacyApproximate
+		}
+	}
+	return privacy
+}
+
+// MaskDistanceKm rounds a member's distance to somewhere to the approximate location grid, unless the member's
+// location is exact, so their location can't be worked out from their distances to a few places
+func MaskDistanceKm(distanceKm float64, privacy config.LocationPrivacy) float64 {
+	if privacy == config.LocationPrivacyExact {
+		return distanceKm
+	}
+	gridKm := float64(config.ApproximateLocationGridSize) / 1000
+	return math.Round(distanceKm/gridKm) * gridKm
+}
+
+// MaskMinutes rounds a member's travel time to somewhere to the nearest 5 minutes, unless the member's location
+// is exact, for the same reason as MaskDistanceKm
+func MaskMinutes(minutes float64, privacy config.LocationPrivacy) float64 {
+	if privacy == config.LocationPrivacyExact {
+		return minutes
+	}
+	return math.Round(minutes/5) * 5
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskMemberLocations(t *testing.T) {
+	home := dto.Location{Latitude: 51.5074, Longitude: -0.1278}
+	members := []dto.GroupUserResponse{
+		{UserID: 1, Latitude: home.Latitude, Longitude: home.Longitude, LocationPrivacy: config.LocationPrivacyHidden},
+		{UserID: 2, Latitude: home.Latitude, Longitude: home.Longitude, LocationPrivacy: config.LocationPrivacyExact},
+		{UserID: 3, Latitude: home.Latitude, Longitude: home.Longitude, LocationPrivacy: config.LocationPrivacyApproximate},
+		{UserID: 4, Latitude: home.Latitude, Longitude: home.Longitude, LocationPrivacy: config.LocationPrivacyHidden},
+	}
+
+	masked := MaskMemberLocations(members, 1)
+	// the viewer sees their own location
+	assert.Equal(t, home.Latitude, masked[0].Latitude)
+	assert.Equal(t, home.Longitude, masked[1].Longitude)
+	assert.Equal(t, geo.SnapToGrid(home, config.ApproximateLocationGridSize).Latitude, masked[2].Latitude)
+	assert.Zero(t, masked[3].Latitude)
+	assert.Zero(t, masked[3].Longitude)
+	// the members themselves aren't changed
+	assert.Equal(t, home.Latitude, members[3].Latitude)
+}
+
+func TestMaskDistanceKm(t *testing.T) {
+	assert.Equal(t, 1.234, MaskDistanceKm(1.234, config.LocationPrivacyExact))
+	assert.InDelta(t, 1.0, MaskDistanceKm(1.234, config.LocationPrivacyApproximate), 0.0001)
+	assert.InDelta(t, 1.5, MaskDistanceKm(1.3, config.LocationPrivacyHidden), 0.0001)
+
+	assert.Equal(t, 12.3, MaskMinutes(12.3, config.LocationPrivacyExact))
+	assert.Equal(t, 10.0, MaskMinutes(12.3, config.LocationPrivacyApproximate))
+}
+
+func TestLeastExactLocationPrivacy(t *testing.T) {
+	assert.Equal(t, config.LocationPrivacyExact, LeastExactLocationPrivacy(nil))
+	assert.Equal(t, config.LocationPrivacyExact, LeastExactLocationPrivacy([]config.LocationPrivacy{config.LocationPrivacyExact}))
+	assert.Equal(t, config.LocationPrivacyApproximate, LeastExactLocationPrivacy([]config.LocationPrivacy{
+		config.LocationPrivacyExact, config.LocationPrivacyApproximate,
+	}))
+	assert.Equal(t, config.LocationPrivacyHidden, LeastExactLocationPrivacy([]config.LocationPrivacy{
+		config.LocationPrivacyApproximate, config.LocationPrivacyHidden, config.LocationPrivacyExact,
+	}))
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+const (
+	// with fewer locations there is no majority to compare against
+	outlierMinLocations = 3
+	// locations this close to the typical distance are never flagged, however tight the group is
+	outlierMinDistanceKm = 1.0
+
+	// candidate midpoints are a grid of this many points per side over the members' bounding box
+	midpointGridSize = 7
+	// the best candidate is refined with a finer grid of this many points per side around it
+	midpointRefineGridSize = 5
+)
+
+// DetectLocationOutliers flags locations which are unusually far away from the rest of the group
+// Each location's distance from the component-wise median is scored with the modified z-score
+// (median absolute deviation based), and anything scoring above threshold is an outlier.
+// A threshold <= 0 disables detection.
+func DetectLocationOutliers(locations []dto.Location, threshold float64) []bool {
+	outliers := make([]bool, len(locations))
+	if threshold <= 0 || len(locations) < outlierMinLocations {
+		return outliers
+	}
+
+	center := geo.ComponentMedian(locations)
+	distances := make([]float64, len(locations))
+	for i, loc := range locations {
+		distances[i] = geo.DistanceKm(center, loc)
+	}
+	medianDistance := geo.Median(distances)
+
+	// MAD / 0.6745 is a consistent estimator of the standard deviation
+	scale := geo.MedianAbsoluteDeviation(distances) / 0.6745
+	if scale == 0 {
+		// more than half the locations are equally far away, fall back to the mean absolute deviation
+		var sumDeviation float64
+		for _, d := range distances {
+			sumDeviation += math.Abs(d - medianDistance)
+		}
+		scale = 1.253314 * sumDeviation / float64(len(distances))
+	}
+	if scale == 0 {
+		return outliers
+	}
+
+	for i, d := range distances {
+		if d-medianDistance < outlierMinDistanceKm {
+			continue
+		}
+		outliers[i] = (d-medianDistance)/scale > threshold
+	}
+	return outliers
+}
+
+// SelectMidpoint picks the midpoint of the travellers using the given strategy
+// Travel time strategies score a grid of candidates over the travellers' bounding box, then a finer
+// grid around the best one. If no candidate is reachable by everyone, the centroid is used instead.
+func SelectMidpoint(ctx context.Context, estimator *TravelTimeEstimator, travellers []Traveller, strategy config.MidpointStrategy) (dto.Location, error) {
+	locations := lo.Map(travellers, func(traveller Traveller, _ int) dto.Location { return traveller.Location })
+	centroid := geo.Centroid(locations)
+	if strategy == config.MidpointStrategyCentroid || strategy == "" || len(locations) < 2 {
+		return centroid, nil
+	}
+
+	minLat, maxLat := locations[0].Latitude, locations[0].Latitude
+	minLng, maxLng := locations[0].Longitude, locations[0].Longitude
+	for _, loc := range locations[1:] {
+		minLat, maxLat = math.Min(minLat, loc.Latitude), math.Max(maxLat, loc.Latitude)
+		minLng, maxLng = math.Min(minLng, loc.Longitude), math.Max(maxLng, loc.Longitude)
+	}
+	latStep := (maxLat - minLat) / (midpointGridSize - 1)
+	lngStep := (maxLng - minLng) / (midpointGridSize - 1)
+
+	// centroid goes first, so it wins any ties
+	candidates := append([]dto.Location{centroid}, midpointGrid(minLat, minLng, latStep, lngStep, midpointGridSize)...)
+	best, bestCost, err := bestMidpointCandidate(ctx, estimator, travellers, candidates, strategy)
+	if err != nil {
+		return centroid, err
+	}
+	if math.IsInf(bestCost, 1) {
+		applogger.Warn("No midpoint candidate is reachable by all members - using centroid")
+		return centroid, nil
+	}
+
+	// refine with a finer grid spanning one coarse step either side of the best candidate
+	half := midpointRefineGridSize / 2
+	refineLatStep := latStep / float64(half)
+	refineLngStep := lngStep / float64(half)
+	candidates = append([]dto.Location{best}, midpointGrid(
+		best.Latitude-latStep, best.Longitude-lngStep, refineLatStep, refineLngStep, midpointRefineGridSize,
+	)...)
+	best, _, err = bestMidpointCandidate(ctx, estimator, travellers, candidates, strategy)
+	if err != nil {
+		return centroid, err
+	}
+	return best, nil
+}
+
+func midpointGrid(startLat float64, startLng float64, latStep float64, lngStep float64, size int) []dto.Location {
+	grid := make([]dto.Location, 0, size*size)
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			grid = append(grid, dto.Location{
+				Latitude:  startLat + float64(i)*latStep,
+				Longitude: startLng + float64(j)*lngStep,
+			})
+		}
+	}
+	return grid
+}
+
+func bestMidpointCandidate(ctx context.Context, estimator *TravelTimeEstimator, travellers []Traveller, candidates []dto.Location, strategy config.MidpointStrategy) (dto.Location, float64, error) {
+	times, err := estimator.TravelTimes(ctx, travellers, candidates)
+	if err != nil {
+		return dto.Location{}, 0, err
+	}
+
+	bestIndex, bestCost := 0, math.Inf(1)
+	for j := range candidates {
+		column := make([]float64, len(travellers))
+		for i := range travellers {
+			column[i] = times[i][j]
+		}
+		if cost := MidpointCost(column, strategy); cost < bestCost {
+			bestIndex, bestCost = j, cost
+		}
+	}
+	return candidates[bestIndex], bestCost, nil
+}
+
+// MidpointCost is what a travel time strategy minimises, given each member's minutes to a candidate
+func MidpointCost(minutes []float64, strategy config.MidpointStrategy) float64 {
+	var cost float64
+	for _, m := range minutes {
+		switch strategy {
+		case config.MidpointStrategyMinMaxTime:
+			cost = math.Max(cost, m)
+		default:
+			cost += m
+		}
+	}
+	return cost
+}
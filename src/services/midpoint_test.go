@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLocationOutliers(t *testing.T) {
+	locations := []dto.Location{
+		{Latitude: 51.5072, Longitude: -0.1276},
+		{Latitude: 51.5101, Longitude: -0.1340},
+		{Latitude: 51.5033, Longitude: -0.1195},
+		{Latitude: 51.5155, Longitude: -0.1419},
+		{Latitude: 51.7520, Longitude: -1.2577}, // Oxford, ~80km away
+	}
+
+	outliers := DetectLocationOutliers(locations, 3.5)
+	assert.Equal(t, []bool{false, false, false, false, true}, outliers)
+}
+
+func TestDetectLocationOutliers_TightGroup(t *testing.T) {
+	// a few hundred meters apart, nobody should be flagged even though one is "furthest"
+	locations := []dto.Location{
+		{Latitude: 51.5072, Longitude: -0.1276},
+		{Latitude: 51.5073, Longitude: -0.1277},
+		{Latitude: 51.5074, Longitude: -0.1275},
+		{Latitude: 51.5100, Longitude: -0.1300},
+	}
+
+	assert.Equal(t, []bool{false, false, false, false}, DetectLocationOutliers(locations, 3.5))
+}
+
+func TestDetectLocationOutliers_Disabled(t *testing.T) {
+	locations := []dto.Location{
+		{Latitude: 51.5072, Longitude: -0.1276},
+		{Latitude: 51.5101, Longitude: -0.1340},
+		{Latitude: 51.5033, Longitude: -0.1195},
+		{Latitude: 51.7520, Longitude: -1.2577},
+	}
+
+	assert.Equal(t, []bool{false, false, false, false}, DetectLocationOutliers(locations, 0))
+	assert.Equal(t, []bool{false, false}, DetectLocationOutliers(locations[2:], 3.5))
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+)
+
+// photos fetched from a provider are cut off beyond this size
+const maxPlacePhotoBytes = 10 << 20
+
+// ErrPlaceNotFound is returned by a PlaceDetailsProvider which doesn't know the place or photo
+var ErrPlaceNotFound = errors.New("place not found")
+
+// PlaceDetailsProvider is a places provider which can also look up more about the places it found
+type PlaceDetailsProvider interface {
+	PlaceDetails(ctx context.Context, placeID string) (*dto.PlaceDetails, error)
+	// PlacePhoto fetches one of the photos from PlaceDetails, scaled down to maxWidth pixels
+	PlacePhoto(ctx context.Context, photo dto.PlacePhoto, maxWidth int) (*PlacePhotoMedia, error)
+}
+
+// PlacePhotoMedia is the image of a place photo
+type PlacePhotoMedia struct {
+	ContentType string
+	Data        []byte
+}
+
+// CachedPlaceDetailsProvider is a PlaceDetailsProvider which remembers place details in memory, in an LRU of maxEntries
+// Photos are not cached, clients are told to cache them instead
+type CachedPlaceDetailsProvider struct {
+	provider   PlaceDetailsProvider
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type placeDetailsCacheEntry struct {
+	placeID   string
+	details   *dto.PlaceDetails
+	expiresAt time.Time
+}
+
+func NewCachedPlaceDetailsProvider(provider PlaceDetailsProvider, ttl time.Duration, maxEntries int) *CachedPlaceDetailsProvider {
+	return &CachedPlaceDetailsProvider{
+		provider:   provider,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+func (p *CachedPlaceDetailsProvider) PlaceDetails(ctx context.Context, placeID string) (*dto.PlaceDetails, error) {
+	if details, ok := p.get(placeID); ok {
+		return details, nil
+	}
+	details, err := p.provider.PlaceDetails(ctx, placeID)
+	if err != nil {
+		return nil, err
+	}
+	p.set(placeID, details)
+	return details, nil
+}
+
+func (p *CachedPlaceDetailsProvider) PlacePhoto(ctx context.Context, photo dto.PlacePhoto, maxWidth int) (*PlacePhotoMedia, error) {
+	return p.provider.PlacePhoto(ctx, photo, maxWidth)
+}
+
+func (p *CachedPlaceDetailsProvider) get(placeID string) (*dto.PlaceDetails, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	element, ok := p.entries[placeID]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*placeDetailsCacheEntry)
+	if !p.now().Before(entry.expiresAt) {
+		p.lru.Remove(element)
+		delete(p.entries, placeID)
+		return nil, false
+	}
+	p.lru.MoveToFront(element)
+	return entry.details, true
+}
+
+func (p *CachedPlaceDetailsProvider) set(placeID string, details *dto.PlaceDetails) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry := &placeDetailsCacheEntry{placeID: placeID, details: details, expiresAt: p.now().Add(p.ttl)}
+	if element, ok := p.entries[placeID]; ok {
+		element.Value = entry
+		p.lru.MoveToFront(element)
+		return
+	}
+	p.entries[placeID] = p.lru.PushFront(entry)
+	for p.maxEntries > 0 && p.lru.Len() > p.maxEntries {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.entries, oldest.Value.(*placeDetailsCacheEntry).placeID)
+	}
+}
+
+var placeDetailsProvider PlaceDetailsProvider
+var placeDetailsProviderOnce sync.Once
+
+// GetPlaceDetailsProvider returns the places provider chosen by config, if it can look up place details, and nil otherwise
+func GetPlaceDetailsProvider() PlaceDetailsProvider {
+
+	placeDetailsProviderOnce.Do(func() {
+		detailsProvider, ok := basePlacesProvider().(PlaceDetailsProvider)
+		if !ok {
+			applogger.Warn("App:", config.PlacesProvider, "places provider has no place details")
+			return
+		}
+		placeDetailsProvider = NewCachedPlaceDetailsProvider(detailsProvider, config.PlaceDetailsCacheTTL, config.PlaceDetailsCacheMaxEntries)
+	})
+
+	return placeDetailsProvider
+}
+
+// fetchPlacePhoto downloads a photo from a URL the provider handed out
+func fetchPlacePhoto(ctx context.Context, uri string) (*PlacePhotoMedia, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("place photo responded with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPlacePhotoBytes))
+	if err != nil {
+		return nil, err
+	}
+	return &PlacePhotoMedia{ContentType: resp.Header.Get("Content-Type"), Data: data}, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingPlaceDetailsProvider struct {
+	*FakePlacesProvider
+	placeIDs []string
+}
+
+func (p *countingPlaceDetailsProvider) PlaceDetails(ctx context.Context, placeID string) (*dto.PlaceDetails, error) {
+	p.placeIDs = append(p.placeIDs, placeID)
+	return p.FakePlacesProvider.PlaceDetails(ctx, placeID)
+}
+
+func TestFakePlacesProvider_PlaceDetails(t *testing.T) {
+	provider := NewFakePlacesProvider()
+	ctx := context.Background()
+
+	details, err := provider.PlaceDetails(ctx, "fake-abc-1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, details.PhoneNumber)
+	assert.Len(t, details.OpeningHours, 7)
+	assert.Len(t, details.Photos, 2)
+
+	again, _ := provider.PlaceDetails(ctx, "fake-abc-1")
+	assert.Equal(t, details, again)
+
+	photo, err := provider.PlacePhoto(ctx, details.Photos[0], 400)
+	require.NoError(t, err)
+	assert.Equal(t, "image/svg+xml", photo.ContentType)
+	assert.Contains(t, string(photo.Data), `width="400" height="300"`)
+
+	_, err = provider.PlaceDetails(ctx, "osm:node/1")
+	assert.ErrorIs(t, err, ErrPlaceNotFound)
+	_, err = provider.PlacePhoto(ctx, dto.PlacePhoto{Reference: "osm:node/1/photos/1"}, 400)
+	assert.ErrorIs(t, err, ErrPlaceNotFound)
+}
+
+func TestCachedPlaceDetailsProvider(t *testing.T) {
+	provider := &countingPlaceDetailsProvider{FakePlacesProvider: NewFakePlacesProvider()}
+	cached := NewCachedPlaceDetailsProvider(provider, time.Hour, 2)
+	now := time.Now()
+	cached.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	first, err := cached.PlaceDetails(ctx, "fake-abc-1")
+	require.NoError(t, err)
+	second, err := cached.PlaceDetails(ctx, "fake-abc-1")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, []string{"fake-abc-1"}, provider.placeIDs)
+
+	// unknown places are not cached
+	_, err = cached.PlaceDetails(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrPlaceNotFound)
+	_, err = cached.PlaceDetails(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrPlaceNotFound)
+	assert.Len(t, provider.placeIDs, 3)
+
+	// the least recently used place is evicted beyond maxEntries
+	_, _ = cached.PlaceDetails(ctx, "fake-abc-2")
+	_, _ = cached.PlaceDetails(ctx, "fake-abc-1")
+	_, _ = cached.PlaceDetails(ctx, "fake-abc-3")
+	assert.Len(t, provider.placeIDs, 5)
+	_, _ = cached.PlaceDetails(ctx, "fake-abc-1")
+	assert.Len(t, provider.placeIDs, 5)
+	_, _ = cached.PlaceDetails(ctx, "fake-abc-2")
+	assert.Len(t, provider.placeIDs, 6)
+
+	// and expires after the TTL
+	now = now.Add(time.Hour)
+	_, _ = cached.PlaceDetails(ctx, "fake-abc-2")
+	assert.Len(t, provider.placeIDs, 7)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"math"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+// places with this many reviews (or more) get full marks for their review count
+const placeScoreReviewCountCap = 1000
+
+// the inputs which are distances from the members or their midpoint
+var placeScoreDistanceInputs = []config.PlaceScoreInput{
+	config.PlaceScoreInputMidpointDistance,
+	config.PlaceScoreInputMaxMemberDistance,
+	config.PlaceScoreInputMeanMemberDistance,
+}
+
+// PlaceScoreCandidate is what a place is scored on
+type PlaceScoreCandidate struct {
+	Location    dto.Location
+	Rating      float64
+	ReviewCount int
+	VoteScore   int
+}
+
+// ScorePlaces scores places against each other, for members meeting around a midpoint
+// Each input is normalized between 0 (worst) and 1 (best), and the score is their weighted mean:
+//   - rating goes from 1 to 5 stars, places without a rating get 0
+//   - review count grows logarithmically up to placeScoreReviewCountCap reviews
+//   - distances (straight line, to the midpoint and the farthest and mean member) and votes are relative
+//     to the other places, the best of them gets 1 and the worst 0. If all places are the same, they all get 0.
+//
+// The distances in the breakdown are masked with MaskDistanceKm by the least exact of the members' location privacy,
+// so their locations can't be worked out from them. The scores themselves use the exact distances.
+// The scores are in the same order as the candidates.
+func ScorePlaces(candidates []PlaceScoreCandidate, midpoint dto.Location, members []dto.Location, privacy config.LocationPrivacy, weights map[config.PlaceScoreInput]float64) []dto.GroupPlaceScore {
+	values := make([]map[config.PlaceScoreInput]float64, len(candidates))
+	for i, candidate := range candidates {
+		memberDistances := lo.Map(members, func(member dto.Location, _ int) float64 {
+			return geo.DistanceKm(member, candidate.Location) * 1000
+		})
+		values[i] = map[config.PlaceScoreInput]float64{
+			config.PlaceScoreInputRating:             candidate.Rating,
+			config.PlaceScoreInputReviewCount:        float64(candidate.ReviewCount),
+			config.PlaceScoreInputMidpointDistance:   geo.DistanceKm(midpoint, candidate.Location) * 1000,
+			config.PlaceScoreInputMaxMemberDistance:  lo.Max(memberDistances),
+			config.PlaceScoreInputMeanMemberDistance: lo.Sum(memberDistances) / float64(max(len(memberDistances), 1)),
+			config.PlaceScoreInputVotes:              float64(candidate.VoteScore),
+		}
+	}
+
+	normalizers := map[config.PlaceScoreInput]func(value float64) float64{
+		config.PlaceScoreInputRating: func(rating float64) float64 {
+			return math.Max(0, math.Min(1, (rating-1)/4))
+		},
+		config.PlaceScoreInputReviewCount: func(reviewCount float64) float64 {
+			return math.Min(1, math.Log1p(reviewCount)/math.Log1p(placeScoreReviewCountCap))
+		},
+		config.PlaceScoreInputMidpointDistance:   _relativePlaceScore(values, config.PlaceScoreInputMidpointDistance, false),
+		config.PlaceScoreInputMaxMemberDistance:  _relativePlaceScore(values, config.PlaceScoreInputMaxMemberDistance, false),
+		config.PlaceScoreInputMeanMemberDistance: _relativePlaceScore(values, config.PlaceScoreInputMeanMemberDistance, false),
+		config.PlaceScoreInputVotes:              _relativePlaceScore(values, config.PlaceScoreInputVotes, true),
+	}
+
+	totalWeight := lo.Sum(lo.Values(weights))
+	return lo.Map(values, func(placeValues map[config.PlaceScoreInput]float64, _ int) dto.GroupPlaceScore {
+		score := dto.GroupPlaceScore{Breakdown: make([]dto.PlaceScoreComponent, len(config.PlaceScoreInputs))}
+		for i, input := range config.PlaceScoreInputs {
+			normalized := normalizers[input](placeValues[input])
+			points := 0.0
+			if totalWeight > 0 {
+				points = normalized * weights[input] / totalWeight
+			}
+			score.Score += points
+			value := placeValues[input]
+			if lo.Contains(placeScoreDistanceInputs, input) {
+				value = MaskDistanceKm(value/1000, privacy) * 1000
+			}
+			score.Breakdown[i] = dto.PlaceScoreComponent{
+				Input:      input,
+				Value:      math.Round(value*100) / 100,
+				Normalized: math.Round(normalized*1000) / 1000,
+				Weight:     weights[input],
+				Points:     math.Round(points*1000) / 1000,
+			}
+		}
+		score.Score = math.Round(score.Score*1000) / 1000
+		return score
+	})
+}
+
+// _relativePlaceScore normalizes an input between the worst and best of the places, higher values being better or worse
+func _relativePlaceScore(values []map[config.PlaceScoreInput]float64, input config.PlaceScoreInput, higherIsBetter bool) func(value float64) float64 {
+	inputValues := lo.Map(values, func(placeValues map[config.PlaceScoreInput]float64, _ int) float64 { return placeValues[input] })
+	lowest, highest := lo.Min(inputValues), lo.Max(inputValues)
+	return func(value float64) float64 {
+		if highest == lowest {
+			return 0
+		}
+		if higherIsBetter {
+			return (value - lowest) / (highest - lowest)
+		}
+		return (highest - value) / (highest - lowest)
+	}
+}
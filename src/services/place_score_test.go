@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScorePlaces(t *testing.T) {
+	midpoint := dto.Location{Latitude: 51.5000, Longitude: -0.1000}
+	members := []dto.Location{{Latitude: 51.5100, Longitude: -0.1000}, {Latitude: 51.4900, Longitude: -0.1000}}
+	near := PlaceScoreCandidate{Location: dto.Location{Latitude: 51.5005, Longitude: -0.1000}, Rating: 3, ReviewCount: 10}
+	far := PlaceScoreCandidate{Location: dto.Location{Latitude: 51.5080, Longitude: -0.1000}, Rating: 5, ReviewCount: 1000, VoteScore: 2}
+	component := func(score dto.GroupPlaceScore, input config.PlaceScoreInput) dto.PlaceScoreComponent {
+		c, _ := lo.Find(score.Breakdown, func(c dto.PlaceScoreComponent) bool { return c.Input == input })
+		return c
+	}
+
+	t.Run("breakdown of each input", func(t *testing.T) {
+		scores := ScorePlaces([]PlaceScoreCandidate{near, far}, midpoint, members, config.LocationPrivacyExact, map[config.PlaceScoreInput]float64{config.PlaceScoreInputRating: 1})
+		assert.Len(t, scores[0].Breakdown, len(config.PlaceScoreInputs))
+
+		assert.Equal(t, 0.5, component(scores[0], config.PlaceScoreInputRating).Normalized)
+		assert.Equal(t, 1.0, component(scores[1], config.PlaceScoreInputRating).Normalized)
+		assert.Equal(t, 1.0, component(scores[1], config.PlaceScoreInputReviewCount).Normalized)
+		assert.InDelta(t, 55.6, component(scores[0], config.PlaceScoreInputMidpointDistance).Value, 1)
+		assert.Equal(t, 1.0, component(scores[0], config.PlaceScoreInputMidpointDistance).Normalized)
+		assert.Equal(t, 0.0, component(scores[1], config.PlaceScoreInputMidpointDistance).Normalized)
+		assert.Equal(t, 1.0, component(scores[0], config.PlaceScoreInputMaxMemberDistance).Normalized)
+		assert.Equal(t, 1.0, component(scores[1], config.PlaceScoreInputVotes).Normalized)
+
+		// only the rating weighs anything
+		assert.Equal(t, 0.5, scores[0].Score)
+		assert.Equal(t, 1.0, scores[1].Score)
+		assert.Equal(t, 0.0, component(scores[0], config.PlaceScoreInputVotes).Points)
+	})
+
+	t.Run("score is the weighted mean", func(t *testing.T) {
+		weights := map[config.PlaceScoreInput]float64{
+			config.PlaceScoreInputRating:            1,
+			config.PlaceScoreInputMaxMemberDistance: 3,
+		}
+		scores := ScorePlaces([]PlaceScoreCandidate{near, far}, midpoint, members, config.LocationPrivacyExact, weights)
+		assert.Equal(t, 0.875, scores[0].Score)
+		assert.Equal(t, 0.25, scores[1].Score)
+		assert.Equal(t, scores[0].Score, lo.SumBy(scores[0].Breakdown, func(c dto.PlaceScoreComponent) float64 { return c.Points }))
+	})
+
+	t.Run("distances are masked like the members' locations", func(t *testing.T) {
+		exact := ScorePlaces([]PlaceScoreCandidate{near, far}, midpoint, members, config.LocationPrivacyExact, map[config.PlaceScoreInput]float64{config.PlaceScoreInputMeanMemberDistance: 1})
+		masked := ScorePlaces([]PlaceScoreCandidate{near, far}, midpoint, members, config.LocationPrivacyApproximate, map[config.PlaceScoreInput]float64{config.PlaceScoreInputMeanMemberDistance: 1})
+		for _, input := range placeScoreDistanceInputs {
+			for i := range masked {
+				assert.Equal(t, MaskDistanceKm(component(exact[i], input).Value/1000, config.LocationPrivacyApproximate)*1000, component(masked[i], input).Value)
+				assert.Equal(t, component(exact[i], input).Normalized, component(masked[i], input).Normalized)
+			}
+		}
+		assert.Equal(t, exact[0].Score, masked[0].Score)
+		assert.Equal(t, component(exact[1], config.PlaceScoreInputRating).Value, component(masked[1], config.PlaceScoreInputRating).Value)
+	})
+
+	t.Run("inputs all places share don't count", func(t *testing.T) {
+		scores := ScorePlaces([]PlaceScoreCandidate{near, near}, midpoint, nil, config.LocationPrivacyExact, map[config.PlaceScoreInput]float64{config.PlaceScoreInputVotes: 1, config.PlaceScoreInputMeanMemberDistance: 1})
+		assert.Equal(t, 0.0, scores[0].Score)
+		assert.Equal(t, 0.0, scores[1].Score)
+	})
+}
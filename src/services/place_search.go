@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+// ErrTextSearchUnsupported is returned when searching by text with a places provider which can't
+var ErrTextSearchUnsupported = errors.New("places provider cannot search by text")
+
+// TextPlacesQuery is a search for places matching some text, like "ramen" or the name of a place,
+// within a radius (in meters) of a location. PlaceType narrows it down to one type, if it is set.
+type TextPlacesQuery struct {
+	Text       string
+	Location   dto.Location
+	Radius     int
+	PlaceType  config.PlaceType
+	MaxResults int
+}
+
+// PlacesTextSearchProvider is a places provider which can also find places by text
+type PlacesTextSearchProvider interface {
+	TextSearchPlaces(ctx context.Context, query TextPlacesQuery) ([]dto.Place, error)
+}
+
+// PlaceSearchQuery is an ad-hoc search, by text if Text is set and otherwise for places of PlaceType nearby
+type PlaceSearchQuery struct {
+	Location  dto.Location
+	Radius    int
+	PlaceType config.PlaceType
+	Text      string
+}
+
+// PlaceSearchService runs ad-hoc place searches for clients, apart from the searches of group refreshes
+// Each search finds as many places as a provider gives at once, and the whole list is cached,
+// so the pages of a search are served from it. Searches in the same geohash cell share results.
+type PlaceSearchService struct {
+	provider         PlacesProvider
+	textProvider     PlacesTextSearchProvider
+	cache            PlacesCacheBackend
+	ttl              time.Duration
+	geohashPrecision int
+}
+
+// NewPlaceSearchService creates a search service, textProvider is nil if the provider can't search by text
+func NewPlaceSearchService(provider PlacesProvider, textProvider PlacesTextSearchProvider, cache PlacesCacheBackend, ttl time.Duration, geohashPrecision int) *PlaceSearchService {
+	return &PlaceSearchService{
+		provider:         provider,
+		textProvider:     textProvider,
+		cache:            cache,
+		ttl:              ttl,
+		geohashPrecision: geohashPrecision,
+	}
+}
+
+// Search returns all the places found for the query, up to config.MaxPlacesResultsPerType
+func (s *PlaceSearchService) Search(ctx context.Context, query PlaceSearchQuery) ([]dto.Place, error) {
+	text := strings.ToLower(strings.Join(strings.Fields(query.Text), " "))
+	if text != "" && s.textProvider == nil {
+		return nil, ErrTextSearchUnsupported
+	}
+	key := fmt.Sprintf("search:%s:%d:%s:%s", geo.Geohash(query.Location, s.geohashPrecision), query.Radius, query.PlaceType, text)
+
+	places, ok, err := s.cache.Get(ctx, key)
+	if err != nil {
+		applogger.Error("Failed to read place search cache", key, err)
+	}
+	if ok {
+		return places, nil
+	}
+
+	if text == "" {
+		places, err = s.provider.NearbyPlaces(ctx, NearbyPlacesQuery{
+			Location:   query.Location,
+			Radius:     query.Radius,
+			PlaceType:  query.PlaceType,
+			MaxResults: config.MaxPlacesResultsPerType,
+		})
+	} else {
+		places, err = s.textProvider.TextSearchPlaces(ctx, TextPlacesQuery{
+			Text:       text,
+			Location:   query.Location,
+			Radius:     query.Radius,
+			PlaceType:  query.PlaceType,
+			MaxResults: config.MaxPlacesResultsPerType,
+		})
+		// providers may only be biased towards the radius, places beyond it are dropped
+		places = lo.Filter(places, func(place dto.Place, _ int) bool {
+			return geo.DistanceKm(query.Location, place.Location)*1000 <= float64(query.Radius)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Set(ctx, key, places, s.ttl); err != nil {
+		applogger.Error("Failed to write place search cache", key, err)
+	}
+	return places, nil
+}
+
+var placeSearchService *PlaceSearchService
+var placeSearchServiceOnce sync.Once
+
+// GetPlaceSearchService returns the search service of the places provider chosen by config
+func GetPlaceSearchService() *PlaceSearchService {
+
+	placeSearchServiceOnce.Do(func() {
+		textProvider, ok := basePlacesProvider().(PlacesTextSearchProvider)
+		if !ok {
+			applogger.Warn("App:", config.PlacesProvider, "places provider cannot search by text")
+		}
+		placeSearchService = NewPlaceSearchService(
+			GetPlacesProvider(),
+			textProvider,
+			NewMemoryPlacesCache(config.PlaceSearchCacheMaxEntries),
+			config.PlaceSearchCacheTTL,
+			config.PlacesCacheGeohashPrecision,
+		)
+	})
+
+	return placeSearchService
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceSearchService(t *testing.T) {
+	center := dto.Location{Latitude: 51.5072, Longitude: -0.1276}
+	fake := &FakePlacesProvider{Places: []dto.Place{
+		{Id: "1", Name: "Blue Cafe", Type: config.PlaceTypeCafe, Location: dto.Location{Latitude: 51.5075, Longitude: -0.1276}},
+		{Id: "2", Name: "Red Cafe", Type: config.PlaceTypeCafe, Location: dto.Location{Latitude: 51.5080, Longitude: -0.1276}},
+		{Id: "3", Name: "Blue Bar", Type: config.PlaceTypeBar, Location: dto.Location{Latitude: 51.5080, Longitude: -0.1276}},
+		{Id: "4", Name: "Far Blue Cafe", Type: config.PlaceTypeCafe, Location: dto.Location{Latitude: 51.6, Longitude: -0.1276}},
+	}}
+	provider := &countingPlacesProvider{PlacesProvider: fake}
+	search := NewPlaceSearchService(provider, fake, NewMemoryPlacesCache(10), time.Hour, 6)
+	ctx := context.Background()
+
+	t.Run("nearby places of a type, cached", func(t *testing.T) {
+		places, err := search.Search(ctx, PlaceSearchQuery{Location: center, Radius: 1000, PlaceType: config.PlaceTypeCafe})
+		require.NoError(t, err)
+		assert.Len(t, places, 2)
+		require.Len(t, provider.queries, 1)
+		assert.Equal(t, config.MaxPlacesResultsPerType, provider.queries[0].MaxResults)
+
+		again, err := search.Search(ctx, PlaceSearchQuery{Location: center, Radius: 1000, PlaceType: config.PlaceTypeCafe})
+		require.NoError(t, err)
+		assert.Equal(t, places, again)
+		assert.Len(t, provider.queries, 1)
+	})
+
+	t.Run("places matching text within the radius", func(t *testing.T) {
+		places, err := search.Search(ctx, PlaceSearchQuery{Location: center, Radius: 1000, Text: "  BLUE "})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"1", "3"}, []string{places[0].Id, places[1].Id})
+
+		places, err = search.Search(ctx, PlaceSearchQuery{Location: center, Radius: 1000, Text: "blue", PlaceType: config.PlaceTypeBar})
+		require.NoError(t, err)
+		require.Len(t, places, 1)
+		assert.Equal(t, "3", places[0].Id)
+	})
+
+	t.Run("text search needs a provider which can", func(t *testing.T) {
+		withoutText := NewPlaceSearchService(provider, nil, NewMemoryPlacesCache(10), time.Hour, 6)
+		_, err := withoutText.Search(ctx, PlaceSearchQuery{Location: center, Radius: 1000, Text: "blue"})
+		assert.ErrorIs(t, err, ErrTextSearchUnsupported)
+	})
+}
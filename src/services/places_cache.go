@@ -0,0 +1,200 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+)
+
+// openNowCacheTTL is how long results of searches for places open now are cached, as they stop being true
+// well before the results of other searches
+const openNowCacheTTL = 15 * time.Minute
+
+// PlacesCacheBackend stores place search results
+type PlacesCacheBackend interface {
+	// Get returns the places stored under key, and false if there are none (or they have expired)
+	Get(ctx context.Context, key string) ([]dto.Place, bool, error)
+	Set(ctx context.Context, key string, places []dto.Place, ttl time.Duration) error
+	Name() string
+}
+
+// CachedPlacesProvider is a PlacesProvider which remembers the results of another one
+// Searches share results if they are in the same geohash cell, for the same place type,
+// their radius rounds up to the same bucket and they ask the provider for as many places.
+// The rounded up radius is what gets searched. Results are cached before filtering, so searches
+// with different filters share them too, except searches for places open now, which are cached apart
+// for at most openNowCacheTTL.
+// Errors from the cache backend are logged and the search goes to the provider instead.
+// Places from the fallback of a metered provider aren't cached, so they stop being used once the budgets reset.
+type CachedPlacesProvider struct {
+	provider         PlacesProvider
+	backend          PlacesCacheBackend
+	ttl              time.Duration
+	geohashPrecision int
+	radiusBucket     int
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+func NewCachedPlacesProvider(provider PlacesProvider, backend PlacesCacheBackend, ttl time.Duration, geohashPrecision int, radiusBucket int) *CachedPlacesProvider {
+	return &CachedPlacesProvider{
+		provider:         provider,
+		backend:          backend,
+		ttl:              ttl,
+		geohashPrecision: geohashPrecision,
+		radiusBucket:     max(radiusBucket, 1),
+	}
+}
+
+func (p *CachedPlacesProvider) NearbyPlaces(ctx context.Context, query NearbyPlacesQuery) ([]dto.Place, error) {
+	query.Radius = (query.Radius + p.radiusBucket - 1) / p.radiusBucket * p.radiusBucket
+	key := fmt.Sprintf("places:%s:%s:%d:%d", geo.Geohash(query.Location, p.geohashPrecision), query.PlaceType, query.Radius, query.ProviderResultCount())
+	ttl := p.ttl
+	if query.OpenNow {
+		key += ":open"
+		ttl = min(ttl, openNowCacheTTL)
+	}
+
+	places, ok, err := p.backend.Get(ctx, key)
+	if err != nil {
+		p.errors.Add(1)
+		applogger.Error("Failed to read places cache", key, err)
+	}
+	if ok {
+		p.hits.Add(1)
+		return places, nil
+	}
+	p.misses.Add(1)
+
+	fromFallback := false
+	if metered, ok := p.provider.(*MeteredPlacesProvider); ok {
+		places, fromFallback, err = metered.nearbyPlaces(ctx, query)
+	} else {
+		places, err = p.provider.NearbyPlaces(ctx, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fromFallback {
+		return places, nil
+	}
+	if err := p.backend.Set(ctx, key, places, ttl); err != nil {
+		p.errors.Add(1)
+		applogger.Error("Failed to write places cache", key, err)
+	}
+	return places, nil
+}
+
+// Stats returns the cache counters since the app started
+func (p *CachedPlacesProvider) Stats() dto.PlacesCacheStats {
+	stats := dto.PlacesCacheStats{
+		Enabled: true,
+		Backend: p.backend.Name(),
+		Hits:    p.hits.Load(),
+		Misses:  p.misses.Load(),
+		Errors:  p.errors.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	if counted, ok := p.backend.(interface{ Len() int }); ok {
+		stats.Entries = counted.Len()
+	}
+	return stats
+}
+
+// Close closes the cache backend and the provider, if they hold connections
+func (p *CachedPlacesProvider) Close() error {
+	if closer, ok := p.backend.(interface{ Close() error }); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	if closer, ok := p.provider.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// MemoryPlacesCache is an in-process LRU cache, which evicts the least recently used entries beyond maxEntries
+type MemoryPlacesCache struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type memoryPlacesCacheEntry struct {
+	key       string
+	places    []dto.Place
+	expiresAt time.Time
+}
+
+func NewMemoryPlacesCache(maxEntries int) *MemoryPlacesCache {
+	return &MemoryPlacesCache{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+func (c *MemoryPlacesCache) Get(_ context.Context, key string) ([]dto.Place, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryPlacesCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.lru.MoveToFront(element)
+	// a copy, so that callers sorting or filtering the places don't change the cached ones
+	return slices.Clone(entry.places), true, nil
+}
+
+func (c *MemoryPlacesCache) Set(_ context.Context, key string, places []dto.Place, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryPlacesCacheEntry{key: key, places: slices.Clone(places), expiresAt: c.now().Add(ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryPlacesCacheEntry).key)
+	}
+	return nil
+}
+
+func (c *MemoryPlacesCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *MemoryPlacesCache) Name() string {
+	return "memory"
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/redis/go-redis/v9"
+)
+
+// commands without a deadline in their context give up after this long
+const redisDefaultTimeout = 2 * time.Second
+
+// RedisPlacesCache stores place search results in Redis (or anything that speaks its protocol)
+// Entries expire after their TTL; set a maxmemory-policy of allkeys-lru on the server to limit its size.
+type RedisPlacesCache struct {
+	client *redis.Client
+}
+
+// NewRedisPlacesCache connects lazily to a redis://[user:password@]host:port[/db] URL
+func NewRedisPlacesCache(redisURL string) (*RedisPlacesCache, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	options.DialTimeout = redisDefaultTimeout
+	options.ReadTimeout = redisDefaultTimeout
+	options.WriteTimeout = redisDefaultTimeout
+	// a place search's own deadline is kept to if it is sooner
+	options.ContextTimeoutEnabled = true
+	return &RedisPlacesCache{client: redis.NewClient(options)}, nil
+}
+
+func (c *RedisPlacesCache) Get(ctx context.Context, key string) ([]dto.Place, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var places []dto.Place
+	if err := json.Unmarshal(value, &places); err != nil {
+		return nil, false, fmt.Errorf("invalid cached places: %w", err)
+	}
+	return places, true, nil
+}
+
+func (c *RedisPlacesCache) Set(ctx context.Context, key string, places []dto.Place, ttl time.Duration) error {
+	value, err := json.Marshal(places)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *RedisPlacesCache) Name() string {
+	return "redis"
+}
+
+func (c *RedisPlacesCache) Close() error {
+	return c.client.Close()
+}
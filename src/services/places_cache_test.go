@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingPlacesProvider struct {
+	PlacesProvider
+	queries []NearbyPlacesQuery
+}
+
+func (p *countingPlacesProvider) NearbyPlaces(ctx context.Context, query NearbyPlacesQuery) ([]dto.Place, error) {
+	p.queries = append(p.queries, query)
+	return p.PlacesProvider.NearbyPlaces(ctx, query)
+}
+
+func TestCachedPlacesProvider(t *testing.T) {
+	provider := &countingPlacesProvider{PlacesProvider: NewFakePlacesProvider()}
+	cached := NewCachedPlacesProvider(provider, NewMemoryPlacesCache(100), time.Hour, 6, 500)
+	ctx := context.Background()
+	query := NearbyPlacesQuery{Location: dto.Location{Latitude: 51.5072, Longitude: -0.1276}, Radius: 800, PlaceType: config.PlaceTypeCafe}
+
+	first, err := cached.NearbyPlaces(ctx, query)
+	require.NoError(t, err)
+	// radius is rounded up to the bucket before searching
+	require.Len(t, provider.queries, 1)
+	assert.Equal(t, 1000, provider.queries[0].Radius)
+
+	// a few meters away, with a radius in the same bucket, shares the results
+	query.Location.Latitude += 0.0001
+	query.Radius = 950
+	second, err := cached.NearbyPlaces(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Len(t, provider.queries, 1)
+
+	// another place type is searched separately
+	query.PlaceType = config.PlaceTypeBar
+	_, err = cached.NearbyPlaces(ctx, query)
+	require.NoError(t, err)
+	assert.Len(t, provider.queries, 2)
+
+	stats := cached.Stats()
+	assert.Equal(t, "memory", stats.Backend)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, 2, stats.Entries)
+	assert.InDelta(t, 1.0/3, stats.HitRate, 0.0001)
+}
+
+func TestCachedPlacesProvider_ErrorsAreNotCached(t *testing.T) {
+	fake := &FakePlacesProvider{Err: errors.New("quota exceeded")}
+	cached := NewCachedPlacesProvider(fake, NewMemoryPlacesCache(100), time.Hour, 6, 500)
+	query := NearbyPlacesQuery{Location: dto.Location{Latitude: 51.5072, Longitude: -0.1276}, Radius: 1000, PlaceType: config.PlaceTypeCafe}
+
+	_, err := cached.NearbyPlaces(context.Background(), query)
+	assert.Error(t, err)
+
+	fake.Err = nil
+	places, err := cached.NearbyPlaces(context.Background(), query)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, places)
+	assert.Equal(t, int64(0), cached.Stats().Hits)
+}
+
+func TestCachedPlacesProvider_OpenNowIsCachedBriefly(t *testing.T) {
+	provider := &countingPlacesProvider{PlacesProvider: NewFakePlacesProvider()}
+	backend := NewMemoryPlacesCache(100)
+	now := time.Now()
+	backend.now = func() time.Time { return now }
+	cached := NewCachedPlacesProvider(provider, backend, 24*time.Hour, 6, 500)
+	ctx := context.Background()
+	query := NearbyPlacesQuery{Location: dto.Location{Latitude: 51.5072, Longitude: -0.1276}, Radius: 1000, PlaceType: config.PlaceTypeCafe}
+
+	_, err := cached.NearbyPlaces(ctx, query)
+	require.NoError(t, err)
+	// places open now aren't taken from the results of other searches
+	query.OpenNow = true
+	for range 2 {
+		_, err = cached.NearbyPlaces(ctx, query)
+		require.NoError(t, err)
+	}
+	assert.Len(t, provider.queries, 2)
+
+	now = now.Add(time.Hour)
+	_, err = cached.NearbyPlaces(ctx, query)
+	require.NoError(t, err)
+	assert.Len(t, provider.queries, 3)
+	query.OpenNow = false
+	_, err = cached.NearbyPlaces(ctx, query)
+	require.NoError(t, err)
+	assert.Len(t, provider.queries, 3)
+}
+
+func TestCachedPlacesProvider_FallbackPlacesAreNotCached(t *testing.T) {
+	store := &memoryPlacesUsageStore{calls: map[string]map[PlacesCaller]int64{}}
+	fallback := &countingPlacesProvider{PlacesProvider: &OSMPlacesProvider{}}
+	metered := NewMeteredPlacesProvider(NewFakePlacesProvider(), fallback, store, PlacesBudgets{Daily: 1})
+	cached := NewCachedPlacesProvider(metered, NewMemoryPlacesCache(100), time.Hour, 6, 500)
+	ctx := context.Background()
+	query := NearbyPlacesQuery{Location: dto.Location{Latitude: 51.5072, Longitude: -0.1276}, Radius: 1000, PlaceType: config.PlaceTypeCafe}
+
+	// the budget is used up by another search, so this one falls back
+	_, err := cached.NearbyPlaces(ctx, NearbyPlacesQuery{Location: query.Location, Radius: 1000, PlaceType: config.PlaceTypeBar})
+	require.NoError(t, err)
+	for range 2 {
+		_, err = cached.NearbyPlaces(ctx, query)
+		require.NoError(t, err)
+	}
+	assert.Len(t, fallback.queries, 2)
+	assert.Equal(t, int64(0), cached.Stats().Hits)
+
+	// the next day the provider is searched again
+	metered.now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+	places, err := cached.NearbyPlaces(ctx, query)
+	require.NoError(t, err)
+	assert.NotEmpty(t, places)
+	assert.Len(t, fallback.queries, 2)
+}
+
+func TestMemoryPlacesCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewMemoryPlacesCache(2)
+	cache.now = func() time.Time { return now }
+
+	require.NoError(t, cache.Set(ctx, "a", []dto.Place{{Id: "a"}}, time.Hour))
+	require.NoError(t, cache.Set(ctx, "b", []dto.Place{{Id: "b"}}, time.Hour))
+	// reading a makes b the least recently used
+	_, ok, _ := cache.Get(ctx, "a")
+	assert.True(t, ok)
+	require.NoError(t, cache.Set(ctx, "c", []dto.Place{{Id: "c"}}, time.Minute))
+
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok)
+	places, ok, _ := cache.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "a", places[0].Id)
+	// changing the places returned doesn't change the cached ones
+	places[0].Id = "changed"
+	places, _, _ = cache.Get(ctx, "a")
+	assert.Equal(t, "a", places[0].Id)
+
+	now = now.Add(2 * time.Minute)
+	_, ok, _ = cache.Get(ctx, "c")
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestRedisPlacesCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+	cache, err := NewRedisPlacesCache("redis://:secret@" + server.Addr() + "/2")
+	require.NoError(t, err)
+	defer cache.Close()
+	ctx := context.Background()
+
+	_, ok, err := cache.Get(ctx, "places:gcpvj0:cafe:1000")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	stored := []dto.Place{{Id: "cafe-1", Name: "Cafe \r\n One", Location: dto.Location{Latitude: 51.5, Longitude: -0.12}}}
+	require.NoError(t, cache.Set(ctx, "places:gcpvj0:cafe:1000", stored, time.Hour))
+	places, ok, err := cache.Get(ctx, "places:gcpvj0:cafe:1000")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, stored, places)
+
+	server.Select(2)
+	assert.Equal(t, time.Hour, server.TTL("places:gcpvj0:cafe:1000"))
+	server.FastForward(time.Hour)
+	_, ok, err = cache.Get(ctx, "places:gcpvj0:cafe:1000")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRedisPlacesCache_WrongPassword(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+	cache, err := NewRedisPlacesCache("redis://:wrong@" + server.Addr())
+	require.NoError(t, err)
+
+	_, _, err = cache.Get(context.Background(), "key")
+	assert.ErrorContains(t, err, "WRONGPASS")
+
+	_, err = NewRedisPlacesCache("http://localhost")
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+// FakePlacesProvider serves places from memory, for tests and local development
+// If Places is empty, it makes up places around every query, which are always the same for the same query
+type FakePlacesProvider struct {
+	Places []dto.Place
+	Err    error
+}
+
+func NewFakePlacesProvider() *FakePlacesProvider {
+	return &FakePlacesProvider{}
+}
+
+func (p *FakePlacesProvider) NearbyPlaces(_ context.Context, query NearbyPlacesQuery) ([]dto.Place, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	if len(p.Places) == 0 {
+		return fakeNearbyPlaces(query), nil
+	}
+
+	var places []dto.Place
+	for _, place := range p.Places {
+		if place.Type != query.PlaceType || geo.DistanceKm(query.Location, place.Location)*1000 > float64(query.Radius) {
+			continue
+		}
+		places = append(places, place)
+		if len(places) == query.ProviderResultCount() {
+			break
+		}
+	}
+	return places, nil
+}
+
+// fakeNearbyPlaces makes up places spread around the query location, within half its radius
+// they are seeded by the query, rounded to ~10m, so nearby midpoints find the same places
+func fakeNearbyPlaces(query NearbyPlacesQuery) []dto.Place {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%.4f,%.4f,%s", query.Location.Latitude, query.Location.Longitude, query.PlaceType)
+	seed := hash.Sum64()
+
+	places := make([]dto.Place, query.ProviderResultCount())
+	for i := range places {
+		// spread the places evenly around a circle, starting from a seeded angle
+		angle := float64(seed%360)*math.Pi/180 + float64(i)*2*math.Pi/float64(len(places))
+		distanceKm := float64(query.Radius) / 1000 / 2 * float64(i+1) / float64(len(places))
+		location := dto.Location{
+			Latitude:  query.Location.Latitude + distanceKm/111.32*math.Cos(angle),
+			Longitude: query.Location.Longitude + distanceKm/(111.32*math.Cos(query.Location.Latitude*math.Pi/180))*math.Sin(angle),
+		}
+		// price, opening and access come from a generator of their own for each place, as the seed only has 64 bits
+		details := rand.New(rand.NewPCG(seed, uint64(i)))
+		places[i] = dto.Place{
+			Location:             location,
+			Id:                   fmt.Sprintf("fake-%x-%d", seed, i+1),
+			Name:                 fmt.Sprintf("Fake %s %d", query.PlaceType, i+1),
+			Address:              fmt.Sprintf("%d Fake Street", seed%100+uint64(i)+1),
+			MapURI:               fmt.Sprintf("https://www.openstreetmap.org/?mlat=%.6f&mlon=%.6f", location.Latitude, location.Longitude),
+			Type:                 query.PlaceType,
+			Rating:               float64(35+(seed>>uint(8*(i%8)))%16) / 10,
+			PriceLevel:           config.PriceLevel(2 + details.IntN(4)),
+			OpenNow:              lo.ToPtr(details.IntN(4) != 0),
+			WheelchairAccessible: lo.ToPtr(details.IntN(2) == 0),
+			ReviewCount:          details.IntN(2000),
+		}
+	}
+	return places
+}
+
+// PlaceDetails makes up details of a place, which are always the same for the same place
+func (p *FakePlacesProvider) PlaceDetails(_ context.Context, placeID string) (*dto.PlaceDetails, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	if !p.hasPlace(placeID) {
+		return nil, ErrPlaceNotFound
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(placeID))
+	seed := hash.Sum64()
+
+	return &dto.PlaceDetails{
+		PhoneNumber:  fmt.Sprintf("+1 555-01%02d", seed%100),
+		Website:      "https://example.com/places/" + placeID,
+		OpeningHours: lo.Map([]string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}, func(day string, _ int) string { return day + ": 9:00 AM – 10:00 PM" }),
+		OpenNow:      lo.ToPtr(seed%4 != 0),
+		PriceLevel:   config.PriceLevel(2 + seed%4),
+		Photos: lo.Times(2, func(i int) dto.PlacePhoto {
+			return dto.PlacePhoto{
+				Reference:    fmt.Sprintf("%s/photos/%d", placeID, i+1),
+				Width:        800,
+				Height:       600,
+				Attributions: []string{"Fake Photographer"},
+			}
+		}),
+	}, nil
+}
+
+// PlacePhoto draws a plain SVG of the photo's size, in a color picked by its reference
+func (p *FakePlacesProvider) PlacePhoto(_ context.Context, photo dto.PlacePhoto, maxWidth int) (*PlacePhotoMedia, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	placeID, _, ok := strings.Cut(photo.Reference, "/photos/")
+	if !ok || !p.hasPlace(placeID) {
+		return nil, ErrPlaceNotFound
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(photo.Reference))
+	width := min(photo.Width, maxWidth)
+	height := photo.Height * width / max(photo.Width, 1)
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d"><rect width="100%%" height="100%%" fill="#%06x"/></svg>`,
+		width, height, hash.Sum32()&0xffffff)
+	return &PlacePhotoMedia{ContentType: "image/svg+xml", Data: []byte(svg)}, nil
+}
+
+// hasPlace is true for the places the provider was given, or for made up places if it wasn't given any
+func (p *FakePlacesProvider) hasPlace(placeID string) bool {
+	if len(p.Places) == 0 {
+		return strings.HasPrefix(placeID, "fake-")
+	}
+	return lo.ContainsBy(p.Places, func(place dto.Place) bool { return place.Id == placeID })
+}
+
+// TextSearchPlaces finds the given places whose name has the text in it, or makes up places named after it
+func (p *FakePlacesProvider) TextSearchPlaces(_ context.Context, query TextPlacesQuery) ([]dto.Place, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	if len(p.Places) > 0 {
+		return lo.Filter(p.Places, func(place dto.Place, _ int) bool {
+			return strings.Contains(strings.ToLower(place.Name), strings.ToLower(query.Text)) &&
+				(query.PlaceType == "" || place.Type == query.PlaceType) &&
+				geo.DistanceKm(query.Location, place.Location)*1000 <= float64(query.Radius)
+		}), nil
+	}
+
+	placeType := query.PlaceType
+	if placeType == "" {
+		placeType = config.DefaultGroupPlaceTypes()[0]
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(query.Text))
+	places := fakeNearbyPlaces(NearbyPlacesQuery{Location: query.Location, Radius: query.Radius, PlaceType: placeType, MaxResults: query.MaxResults})
+	for i := range places {
+		places[i].Id = fmt.Sprintf("%s-%x", places[i].Id, hash.Sum32())
+		places[i].Name = fmt.Sprintf("Fake %s %d", query.Text, i+1)
+	}
+	return places, nil
+}
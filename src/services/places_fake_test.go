@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakePlacesProvider_MadeUpPlaces(t *testing.T) {
+	query := NearbyPlacesQuery{
+		Location:  dto.Location{Latitude: 28.6139, Longitude: 77.2090},
+		Radius:    1000,
+		PlaceType: config.PlaceTypeCafe,
+	}
+	provider := NewFakePlacesProvider()
+
+	places, err := provider.NearbyPlaces(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, places, config.PlacesResultsPerType)
+	for _, place := range places {
+		assert.Equal(t, config.PlaceTypeCafe, place.Type)
+		assert.LessOrEqual(t, geo.DistanceKm(query.Location, place.Location), 1.0)
+		assert.GreaterOrEqual(t, place.Rating, 1.0)
+		assert.LessOrEqual(t, place.Rating, 5.0)
+	}
+
+	// same query, same places
+	again, _ := provider.NearbyPlaces(context.Background(), query)
+	assert.Equal(t, places, again)
+
+	query.PlaceType = config.PlaceTypeBar
+	bars, _ := provider.NearbyPlaces(context.Background(), query)
+	assert.NotEqual(t, places[0].Id, bars[0].Id)
+}
+
+func TestFakePlacesProvider_FixedPlaces(t *testing.T) {
+	center := dto.Location{Latitude: 51.5072, Longitude: -0.1276}
+	provider := &FakePlacesProvider{Places: []dto.Place{
+		{Id: "near-cafe", Type: config.PlaceTypeCafe, Location: dto.Location{Latitude: 51.5080, Longitude: -0.1276}},
+		{Id: "far-cafe", Type: config.PlaceTypeCafe, Location: dto.Location{Latitude: 51.6072, Longitude: -0.1276}},
+		{Id: "near-bar", Type: config.PlaceTypeBar, Location: dto.Location{Latitude: 51.5070, Longitude: -0.1270}},
+	}}
+
+	places, err := provider.NearbyPlaces(context.Background(), NearbyPlacesQuery{Location: center, Radius: 2000, PlaceType: config.PlaceTypeCafe})
+	assert.NoError(t, err)
+	assert.Len(t, places, 1)
+	assert.Equal(t, "near-cafe", places[0].Id)
+
+	provider.Err = errors.New("quota exceeded")
+	_, err = provider.NearbyPlaces(context.Background(), NearbyPlacesQuery{Location: center, Radius: 2000, PlaceType: config.PlaceTypeCafe})
+	assert.Error(t, err)
+}
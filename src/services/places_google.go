@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+
+	places "cloud.google.com/go/maps/places/apiv1"
+	placespb "cloud.google.com/go/maps/places/apiv1/placespb"
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/samber/lo"
+	"google.golang.org/genproto/googleapis/type/latlng"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GooglePlacesProvider finds places with the Google Places API
+type GooglePlacesProvider struct {
+	placesClient *places.Client
+}
+
+func NewGooglePlacesProvider() *GooglePlacesProvider {
+
+	return &GooglePlacesProvider{
+		placesClient: GetGooglePlacesClient(),
+	}
+}
+
+const fieldsToRequest = "places.id,places.displayName,places.formattedAddress,places.googleMapsUri,places.primaryTypeDisplayName,places.rating,places.userRatingCount,places.location,places.shortFormattedAddress,places.priceLevel,places.currentOpeningHours.openNow,places.accessibilityOptions.wheelchairAccessibleEntrance"
+
+func (s *GooglePlacesProvider) NearbyPlaces(ctx context.Context, query NearbyPlacesQuery) ([]dto.Place, error) {
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-goog-fieldmask", fieldsToRequest)
+
+	searchResp, err := s.placesClient.SearchNearby(
+		ctx,
+		&placespb.SearchNearbyRequest{
+			IncludedTypes:  _getIncludedTypes(query.PlaceType),
+			MaxResultCount: int32(query.ProviderResultCount()),
+			LocationRestriction: &placespb.SearchNearbyRequest_LocationRestriction{
+				Type: &placespb.SearchNearbyRequest_LocationRestriction_Circle{
+					Circle: &placespb.Circle{
+						Center: &latlng.LatLng{
+							Latitude:  query.Location.Latitude,
+							Longitude: query.Location.Longitude,
+						},
+						Radius: float64(query.Radius),
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		applogger.Error("Error searching for nearby places", query.Location, "with radius", query.Radius, "and place type", query.PlaceType, err)
+		return nil, err
+	}
+
+	places := lo.Map(searchResp.Places, func(place *placespb.Place, _ int) dto.Place {
+		return _googlePlaceToPlaceDto(place, query.PlaceType)
+	})
+
+	return places, nil
+}
+
+// TextSearchPlaces searches Google for places matching the text, biased towards the radius around the location
+func (s *GooglePlacesProvider) TextSearchPlaces(ctx context.Context, query TextPlacesQuery) ([]dto.Place, error) {
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-goog-fieldmask", fieldsToRequest+",places.types")
+
+	req := &placespb.SearchTextRequest{
+		TextQuery:      query.Text,
+		MaxResultCount: int32(query.MaxResults),
+		LocationBias: &placespb.SearchTextRequest_LocationBias{
+			Type: &placespb.SearchTextRequest_LocationBias_Circle{
+				Circle: &placespb.Circle{
+					Center: &latlng.LatLng{
+						Latitude:  query.Location.Latitude,
+						Longitude: query.Location.Longitude,
+					},
+					Radius: float64(query.Radius),
+				},
+			},
+		},
+	}
+	if includedTypes := _getIncludedTypes(query.PlaceType); query.PlaceType != "" && len(includedTypes) > 0 {
+		// text search takes a single type
+		req.IncludedType = includedTypes[0]
+	}
+	searchResp, err := s.placesClient.SearchText(ctx, req)
+	if err != nil {
+		applogger.Error("Error searching for places matching", query.Text, "around", query.Location, err)
+		return nil, err
+	}
+
+	places := lo.Map(searchResp.Places, func(place *placespb.Place, _ int) dto.Place {
+		placeType := query.PlaceType
+		if placeType == "" {
+			placeType = _googlePlaceType(place.Types)
+		}
+		return _googlePlaceToPlaceDto(place, placeType)
+	})
+
+	return places, nil
+}
+
+// _googlePlaceType finds the place type which Google's types of a place belong to, if any
+func _googlePlaceType(googleTypes []string) config.PlaceType {
+	for _, placeType := range config.SupportedPlaceTypes() {
+		if lo.Some(_getIncludedTypes(placeType), googleTypes) {
+			return placeType
+		}
+	}
+	return ""
+}
+
+func _getIncludedTypes(placeType config.PlaceType) []string {
+	return config.ProviderPlaceTypes(placeType, "google")
+}
+
+func _googlePlaceToPlaceDto(googlePlace *placespb.Place, placeType config.PlaceType) dto.Place {
+
+	place := dto.Place{
+		Location: dto.Location{
+			Latitude:  googlePlace.Location.Latitude,
+			Longitude: googlePlace.Location.Longitude,
+		},
+		Id:          googlePlace.Id,
+		Name:        googlePlace.DisplayName.Text,
+		Address:     googlePlace.ShortFormattedAddress,
+		MapURI:      googlePlace.GoogleMapsUri,
+		Type:        placeType,
+		Rating:      googlePlace.Rating,
+		ReviewCount: int(googlePlace.GetUserRatingCount()),
+		// Google's price levels are on the same scale
+		PriceLevel: config.PriceLevel(googlePlace.PriceLevel),
+	}
+	if googlePlace.CurrentOpeningHours != nil {
+		place.OpenNow = googlePlace.CurrentOpeningHours.OpenNow
+	}
+	if googlePlace.AccessibilityOptions != nil {
+		place.WheelchairAccessible = googlePlace.AccessibilityOptions.WheelchairAccessibleEntrance
+	}
+	return place
+}
+
+const detailsFieldsToRequest = "id,nationalPhoneNumber,internationalPhoneNumber,websiteUri,regularOpeningHours.weekdayDescriptions,currentOpeningHours.openNow,priceLevel,photos"
+
+// PlaceDetails gets the contact info, opening hours, price level and photos of a place
+func (s *GooglePlacesProvider) PlaceDetails(ctx context.Context, placeID string) (*dto.PlaceDetails, error) {
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-goog-fieldmask", detailsFieldsToRequest)
+
+	googlePlace, err := s.placesClient.GetPlace(ctx, &placespb.GetPlaceRequest{Name: "places/" + placeID})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrPlaceNotFound
+	}
+	if err != nil {
+		applogger.Error("Error getting details of place", placeID, err)
+		return nil, err
+	}
+
+	return _googlePlaceToPlaceDetailsDto(googlePlace), nil
+}
+
+func _googlePlaceToPlaceDetailsDto(googlePlace *placespb.Place) *dto.PlaceDetails {
+
+	details := &dto.PlaceDetails{
+		PhoneNumber: lo.CoalesceOrEmpty(googlePlace.InternationalPhoneNumber, googlePlace.NationalPhoneNumber),
+		Website:     googlePlace.WebsiteUri,
+		PriceLevel:  config.PriceLevel(googlePlace.PriceLevel),
+		Photos: lo.Map(googlePlace.Photos, func(photo *placespb.Photo, _ int) dto.PlacePhoto {
+			return dto.PlacePhoto{
+				Reference: photo.Name,
+				Width:     int(photo.WidthPx),
+				Height:    int(photo.HeightPx),
+				Attributions: lo.Map(photo.AuthorAttributions, func(author *placespb.AuthorAttribution, _ int) string {
+					return author.DisplayName
+				}),
+			}
+		}),
+	}
+	if googlePlace.RegularOpeningHours != nil {
+		details.OpeningHours = googlePlace.RegularOpeningHours.WeekdayDescriptions
+	}
+	if googlePlace.CurrentOpeningHours != nil {
+		details.OpenNow = googlePlace.CurrentOpeningHours.OpenNow
+	}
+	return details
+}
+
+// PlacePhoto asks Google where the photo is, and downloads it from there, so the API key stays on the server
+func (s *GooglePlacesProvider) PlacePhoto(ctx context.Context, photo dto.PlacePhoto, maxWidth int) (*PlacePhotoMedia, error) {
+
+	media, err := s.placesClient.GetPhotoMedia(ctx, &placespb.GetPhotoMediaRequest{
+		Name:             photo.Reference + "/media",
+		MaxWidthPx:       int32(maxWidth),
+		SkipHttpRedirect: true,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrPlaceNotFound
+	}
+	if err != nil {
+		applogger.Error("Error getting place photo", photo.Reference, err)
+		return nil, err
+	}
+
+	return fetchPlacePhoto(ctx, media.PhotoUri)
+}
+
+// Close closes the underlying Google Places client
+func (s *GooglePlacesProvider) Close() error {
+	return s.placesClient.Close()
+}
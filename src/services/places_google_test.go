@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 
 	"github.com/championswimmer/api.midpoint.place/src/config"
@@ -10,13 +11,20 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestPlaceSearchService_NearbyPlaces(t *testing.T) {
-	placeSearchService := NewPlaceSearchService()
+func TestGooglePlacesProvider_NearbyPlaces(t *testing.T) {
+	if config.GoogleMapsAPIKey == "" {
+		t.Skip("GOOGLE_MAPS_API_KEY is not set")
+	}
+	placesProvider := NewGooglePlacesProvider()
 
-	places, err := placeSearchService.NearbyPlaces(dto.Location{
-		Latitude:  28.6139,
-		Longitude: 77.2090,
-	}, 1000, config.PlaceTypePark)
+	places, err := placesProvider.NearbyPlaces(context.Background(), NearbyPlacesQuery{
+		Location: dto.Location{
+			Latitude:  28.6139,
+			Longitude: 77.2090,
+		},
+		Radius:    1000,
+		PlaceType: config.PlaceTypePark,
+	})
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, places)
@@ -30,3 +38,8 @@ func Test_getIncludedTypes(t *testing.T) {
 	assert.Equal(t, []string{"museum"}, _getIncludedTypes(config.PlaceTypeMuseum))
 	assert.Equal(t, []string{"book_store"}, _getIncludedTypes(config.PlaceTypeBookstore))
 }
+
+func Test_googlePlaceType(t *testing.T) {
+	assert.Equal(t, config.PlaceTypeBookstore, _googlePlaceType([]string{"store", "book_store"}))
+	assert.Equal(t, config.PlaceType(""), _googlePlaceType([]string{"car_wash"}))
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+// places are bucketed by geohashes of this precision (cells of ~5km x 5km)
+const osmGeohashPrecision = 5
+
+// OSMPlacesProvider finds places in an OpenStreetMap extract held in memory, without calling any API
+type OSMPlacesProvider struct {
+	places  []dto.Place
+	buckets map[string][]int
+	// details of the places by ID, from their contact and opening_hours tags
+	details map[string]dto.PlaceDetails
+}
+
+type overpassElements struct {
+	Elements []struct {
+		Type   string  `json:"type"`
+		ID     int64   `json:"id"`
+		Lat    float64 `json:"lat"`
+		Lon    float64 `json:"lon"`
+		Center *struct {
+			Lat float64 `json:"lat"`
+			Lon float64 `json:"lon"`
+		} `json:"center"`
+		Tags map[string]string `json:"tags"`
+	} `json:"elements"`
+}
+
+type geoJSONPlaces struct {
+	Features []struct {
+		ID       any `json:"id"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	} `json:"features"`
+}
+
+// LoadOSMPlacesProvider loads the points of interest of an OSM extract, which is either
+// Overpass JSON (use `out center;` so ways come with a location), or a GeoJSON FeatureCollection
+// with the OSM tags as properties. PBF extracts can be converted with `osmium export -f geojson`.
+// Only named POIs whose tags map to a place type are kept.
+func LoadOSMPlacesProvider(path string) (*OSMPlacesProvider, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var format struct {
+		Elements json.RawMessage `json:"elements"`
+		Features json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(file, &format); err != nil {
+		return nil, fmt.Errorf("invalid osm places file: %w", err)
+	}
+
+	provider := NewOSMPlacesProvider()
+	switch {
+	case format.Elements != nil:
+		var overpass overpassElements
+		if err := json.Unmarshal(file, &overpass); err != nil {
+			return nil, fmt.Errorf("invalid overpass json: %w", err)
+		}
+		for _, element := range overpass.Elements {
+			location := dto.Location{Latitude: element.Lat, Longitude: element.Lon}
+			if element.Center != nil {
+				location = dto.Location{Latitude: element.Center.Lat, Longitude: element.Center.Lon}
+			}
+			if location.Latitude == 0 && location.Longitude == 0 {
+				continue
+			}
+			provider.AddPOI(fmt.Sprintf("%s/%d", element.Type, element.ID), location, element.Tags)
+		}
+	case format.Features != nil:
+		var features geoJSONPlaces
+		if err := json.Unmarshal(file, &features); err != nil {
+			return nil, fmt.Errorf("invalid osm places geojson: %w", err)
+		}
+		for i, feature := range features.Features {
+			location, ok := geoJSONFeatureLocation(feature.Geometry.Type, feature.Geometry.Coordinates)
+			if !ok {
+				continue
+			}
+			tags := map[string]string{}
+			for key, value := range feature.Properties {
+				tags[key] = fmt.Sprint(value)
+			}
+			provider.AddPOI(osmFeatureID(feature.ID, tags, i), location, tags)
+		}
+	default:
+		return nil, errors.New("osm places file is neither overpass json nor geojson")
+	}
+
+	if len(provider.places) == 0 {
+		return nil, errors.New("osm places file has no places")
+	}
+	return provider, nil
+}
+
+func NewOSMPlacesProvider() *OSMPlacesProvider {
+	return &OSMPlacesProvider{buckets: map[string][]int{}, details: map[string]dto.PlaceDetails{}}
+}
+
+// AddPOI adds an OSM element, identified like "node/123", if it is named and its tags map to a place type
+func (p *OSMPlacesProvider) AddPOI(osmID string, location dto.Location, tags map[string]string) bool {
+	placeType, ok := _osmPlaceType(tags)
+	if !ok || tags["name"] == "" {
+		return false
+	}
+	p.places = append(p.places, dto.Place{
+		Location: location,
+		Id:       "osm:" + osmID,
+		Name:     tags["name"],
+		Address:  _osmAddress(tags),
+		Type:     placeType,
+		MapURI:   "https://www.openstreetmap.org/" + osmID,
+		// limited means some parts of the place can be reached in a wheelchair, which is enough to meet there
+		WheelchairAccessible: _osmTagBool(tags["wheelchair"], "yes", "limited", "designated"),
+	})
+	hash := geo.Geohash(location, osmGeohashPrecision)
+	p.buckets[hash] = append(p.buckets[hash], len(p.places)-1)
+	p.details["osm:"+osmID] = _osmPlaceDetails(tags)
+	return true
+}
+
+// PlaceDetails returns what the tags of a place say about it, OSM extracts have no photos
+func (p *OSMPlacesProvider) PlaceDetails(_ context.Context, placeID string) (*dto.PlaceDetails, error) {
+	details, ok := p.details[placeID]
+	if !ok {
+		return nil, ErrPlaceNotFound
+	}
+	return &details, nil
+}
+
+func (p *OSMPlacesProvider) hasPlace(placeID string) bool {
+	_, ok := p.details[placeID]
+	return ok
+}
+
+func (p *OSMPlacesProvider) PlacePhoto(_ context.Context, _ dto.PlacePhoto, _ int) (*PlacePhotoMedia, error) {
+	return nil, ErrPlaceNotFound
+}
+
+// _osmPlaceDetails reads the contact tags, with or without the contact: prefix
+// opening_hours is kept as written, e.g. "Mo-Fr 08:00-20:00; Sa 10:00-18:00"
+func _osmPlaceDetails(tags map[string]string) dto.PlaceDetails {
+	details := dto.PlaceDetails{
+		PhoneNumber: lo.CoalesceOrEmpty(tags["phone"], tags["contact:phone"]),
+		Website:     lo.CoalesceOrEmpty(tags["website"], tags["contact:website"]),
+	}
+	if tags["opening_hours"] != "" {
+		details.OpeningHours = []string{tags["opening_hours"]}
+	}
+	return details
+}
+
+// NearbyPlaces returns the closest places of the type within the radius
+func (p *OSMPlacesProvider) NearbyPlaces(_ context.Context, query NearbyPlacesQuery) ([]dto.Place, error) {
+	return p.closestPlaces(query.Location, query.Radius, query.ProviderResultCount(), func(place dto.Place) bool {
+		return place.Type == query.PlaceType
+	}), nil
+}
+
+// TextSearchPlaces returns the closest places within the radius whose name has the text in it
+func (p *OSMPlacesProvider) TextSearchPlaces(_ context.Context, query TextPlacesQuery) ([]dto.Place, error) {
+	text := strings.ToLower(query.Text)
+	return p.closestPlaces(query.Location, query.Radius, query.MaxResults, func(place dto.Place) bool {
+		return strings.Contains(strings.ToLower(place.Name), text) && (query.PlaceType == "" || place.Type == query.PlaceType)
+	}), nil
+}
+
+// closestPlaces returns up to limit places which match, within the radius (in meters) of the location, closest first
+func (p *OSMPlacesProvider) closestPlaces(location dto.Location, radius int, limit int, match func(place dto.Place) bool) []dto.Place {
+	radiusKm := float64(radius) / 1000
+	type nearbyPlace struct {
+		index      int
+		distanceKm float64
+	}
+	var nearby []nearbyPlace
+	for _, hash := range geo.GeohashesInRadius(location, radiusKm, osmGeohashPrecision) {
+		for _, i := range p.buckets[hash] {
+			if !match(p.places[i]) {
+				continue
+			}
+			if distanceKm := geo.DistanceKm(location, p.places[i].Location); distanceKm <= radiusKm {
+				nearby = append(nearby, nearbyPlace{index: i, distanceKm: distanceKm})
+			}
+		}
+	}
+
+	sort.Slice(nearby, func(a, b int) bool { return nearby[a].distanceKm < nearby[b].distanceKm })
+	if len(nearby) > limit {
+		nearby = nearby[:limit]
+	}
+	places := make([]dto.Place, len(nearby))
+	for i, n := range nearby {
+		places[i] = p.places[n.index]
+	}
+	return places
+}
+
+// _getOSMTags is the OSM counterpart of _getIncludedTypes, as key=value tags
+func _getOSMTags(placeType config.PlaceType) []string {
+	return config.ProviderPlaceTypes(placeType, "osm")
+}
+
+func _osmPlaceType(tags map[string]string) (config.PlaceType, bool) {
+	for _, placeType := range config.SupportedPlaceTypes() {
+		for _, tag := range _getOSMTags(placeType) {
+			key, value, _ := strings.Cut(tag, "=")
+			if tags[key] == value {
+				return placeType, true
+			}
+		}
+	}
+	return "", false
+}
+
+// _osmTagBool is true if the tag has one of the values, false if it is "no", and nil if the tag is missing or unclear
+func _osmTagBool(value string, trueValues ...string) *bool {
+	switch {
+	case lo.Contains(trueValues, value):
+		return lo.ToPtr(true)
+	case value == "no":
+		return lo.ToPtr(false)
+	}
+	return nil
+}
+
+func _osmAddress(tags map[string]string) string {
+	street := strings.TrimSpace(tags["addr:housenumber"] + " " + tags["addr:street"])
+	parts := []string{}
+	for _, part := range []string{street, tags["addr:city"]} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// osmFeatureID works out "node/123" style ids from GeoJSON exported by osmium ("n123") or overpass-turbo ("node/123")
+func osmFeatureID(id any, tags map[string]string, index int) string {
+	raw := tags["@id"]
+	if id != nil {
+		raw = fmt.Sprint(id)
+	}
+	if strings.Contains(raw, "/") {
+		return raw
+	}
+	if len(raw) > 1 {
+		switch raw[0] {
+		case 'n':
+			return "node/" + raw[1:]
+		case 'w':
+			return "way/" + raw[1:]
+		case 'r':
+			return "relation/" + raw[1:]
+		}
+	}
+	return fmt.Sprintf("feature/%d", index)
+}
+
+// geoJSONFeatureLocation is the point itself, or the centroid of the outer ring of a polygon
+func geoJSONFeatureLocation(geometryType string, coordinates json.RawMessage) (dto.Location, bool) {
+	toLocations := func(ring [][]float64) []dto.Location {
+		var locations []dto.Location
+		// rings end where they start, which would count that point twice
+		if first, last := ring[0], ring[len(ring)-1]; len(ring) > 1 && len(first) >= 2 && len(last) >= 2 && first[0] == last[0] && first[1] == last[1] {
+			ring = ring[:len(ring)-1]
+		}
+		for _, coord := range ring {
+			if len(coord) >= 2 {
+				// GeoJSON is [longitude, latitude]
+				locations = append(locations, dto.Location{Latitude: coord[1], Longitude: coord[0]})
+			}
+		}
+		return locations
+	}
+
+	switch geometryType {
+	case "Point":
+		var point []float64
+		if err := json.Unmarshal(coordinates, &point); err != nil || len(point) < 2 {
+			return dto.Location{}, false
+		}
+		return dto.Location{Latitude: point[1], Longitude: point[0]}, true
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(coordinates, &rings); err != nil || len(rings) == 0 || len(rings[0]) == 0 {
+			return dto.Location{}, false
+		}
+		return geo.Centroid(toLocations(rings[0])), true
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(coordinates, &polygons); err != nil || len(polygons) == 0 || len(polygons[0]) == 0 || len(polygons[0][0]) == 0 {
+			return dto.Location{}, false
+		}
+		return geo.Centroid(toLocations(polygons[0][0])), true
+	}
+	return dto.Location{}, false
+}
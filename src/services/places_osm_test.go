@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// around Trafalgar Square, plus a cafe in Oxford
+const testOverpassJSON = `{
+	"version": 0.6,
+	"elements": [
+		{"type": "node", "id": 1, "lat": 51.5080, "lon": -0.1281,
+		 "tags": {"amenity": "cafe", "name": "Square Cafe", "addr:housenumber": "1", "addr:street": "Strand", "addr:city": "London"}},
+		{"type": "node", "id": 2, "lat": 51.5100, "lon": -0.1300, "tags": {"shop": "coffee", "name": "Bean There"}},
+		{"type": "node", "id": 3, "lat": 51.5090, "lon": -0.1290, "tags": {"amenity": "cafe"}},
+		{"type": "node", "id": 4, "lat": 51.5085, "lon": -0.1285, "tags": {"amenity": "pub", "name": "The Lord Nelson"}},
+		{"type": "way", "id": 5, "center": {"lat": 51.5030, "lon": -0.1340}, "tags": {"leisure": "park", "name": "St James's Park"}},
+		{"type": "node", "id": 6, "lat": 51.7520, "lon": -1.2577, "tags": {"amenity": "cafe", "name": "Oxford Cafe"}},
+		{"type": "node", "id": 7, "lat": 51.5081, "lon": -0.1282, "tags": {"amenity": "bench"}}
+	]
+}`
+
+const testOSMPlacesGeoJSON = `{
+	"type": "FeatureCollection",
+	"features": [
+		{"type": "Feature", "id": "n10", "properties": {"tourism": "museum", "name": "National Gallery"},
+		 "geometry": {"type": "Point", "coordinates": [-0.1283, 51.5089]}},
+		{"type": "Feature", "id": "w11", "properties": {"shop": "books", "name": "Big Bookshop"},
+		 "geometry": {"type": "Polygon", "coordinates": [[[-0.131, 51.511], [-0.129, 51.511], [-0.129, 51.513], [-0.131, 51.513], [-0.131, 51.511]]]}},
+		{"type": "Feature", "properties": {"highway": "residential", "name": "Some Street"},
+		 "geometry": {"type": "LineString", "coordinates": [[-0.13, 51.50], [-0.12, 51.50]]}}
+	]
+}`
+
+func loadTestOSMPlaces(t *testing.T, name string, contents string) *OSMPlacesProvider {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	provider, err := LoadOSMPlacesProvider(path)
+	require.NoError(t, err)
+	return provider
+}
+
+func TestOSMPlacesProvider_Overpass(t *testing.T) {
+	provider := loadTestOSMPlaces(t, "places.json", testOverpassJSON)
+	trafalgarSquare := dto.Location{Latitude: 51.5080, Longitude: -0.1280}
+
+	cafes, err := provider.NearbyPlaces(context.Background(), NearbyPlacesQuery{Location: trafalgarSquare, Radius: 1000, PlaceType: config.PlaceTypeCafe})
+	require.NoError(t, err)
+	// the unnamed cafe and the one in Oxford are left out, closest comes first
+	require.Len(t, cafes, 2)
+	assert.Equal(t, "osm:node/1", cafes[0].Id)
+	assert.Equal(t, "Square Cafe", cafes[0].Name)
+	assert.Equal(t, "1 Strand, London", cafes[0].Address)
+	assert.Equal(t, "https://www.openstreetmap.org/node/1", cafes[0].MapURI)
+	assert.Equal(t, "Bean There", cafes[1].Name)
+
+	parks, _ := provider.NearbyPlaces(context.Background(), NearbyPlacesQuery{Location: trafalgarSquare, Radius: 1000, PlaceType: config.PlaceTypePark})
+	require.Len(t, parks, 1)
+	assert.Equal(t, "osm:way/5", parks[0].Id)
+
+	parks, _ = provider.NearbyPlaces(context.Background(), NearbyPlacesQuery{Location: trafalgarSquare, Radius: 300, PlaceType: config.PlaceTypePark})
+	assert.Empty(t, parks)
+}
+
+func TestOSMPlacesProvider_GeoJSON(t *testing.T) {
+	provider := loadTestOSMPlaces(t, "places.geojson", testOSMPlacesGeoJSON)
+	trafalgarSquare := dto.Location{Latitude: 51.5080, Longitude: -0.1280}
+
+	museums, _ := provider.NearbyPlaces(context.Background(), NearbyPlacesQuery{Location: trafalgarSquare, Radius: 500, PlaceType: config.PlaceTypeMuseum})
+	require.Len(t, museums, 1)
+	assert.Equal(t, "osm:node/10", museums[0].Id)
+
+	bookstores, _ := provider.NearbyPlaces(context.Background(), NearbyPlacesQuery{Location: trafalgarSquare, Radius: 1000, PlaceType: config.PlaceTypeBookstore})
+	require.Len(t, bookstores, 1)
+	assert.Equal(t, "osm:way/11", bookstores[0].Id)
+	assert.InDelta(t, 51.5120, bookstores[0].Latitude, 0.0001)
+}
+
+func TestOSMPlacesProvider_NoPlaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"elements": []}`), 0o644))
+	_, err := LoadOSMPlacesProvider(path)
+	assert.Error(t, err)
+}
+
+func Test_osmPlaceType(t *testing.T) {
+	placeType, ok := _osmPlaceType(map[string]string{"amenity": "biergarten"})
+	assert.True(t, ok)
+	assert.Equal(t, config.PlaceTypeBar, placeType)
+	_, ok = _osmPlaceType(map[string]string{"amenity": "parking"})
+	assert.False(t, ok)
+}
+
+func TestOSMPlacesProvider_Wheelchair(t *testing.T) {
+	provider := NewOSMPlacesProvider()
+	location := dto.Location{Latitude: 51.5080, Longitude: -0.1280}
+	provider.AddPOI("node/1", location, map[string]string{"amenity": "cafe", "name": "Ramp Cafe", "wheelchair": "yes"})
+	provider.AddPOI("node/2", location, map[string]string{"amenity": "cafe", "name": "Stairs Cafe", "wheelchair": "no"})
+	provider.AddPOI("node/3", location, map[string]string{"amenity": "cafe", "name": "Some Cafe"})
+
+	cafes, _ := provider.NearbyPlaces(context.Background(), NearbyPlacesQuery{Location: location, Radius: 100, PlaceType: config.PlaceTypeCafe})
+	require.Len(t, cafes, 3)
+	access := map[string]*bool{}
+	for _, cafe := range cafes {
+		access[cafe.Name] = cafe.WheelchairAccessible
+	}
+	assert.True(t, *access["Ramp Cafe"])
+	assert.False(t, *access["Stairs Cafe"])
+	assert.Nil(t, access["Some Cafe"])
+}
+
+func TestOSMPlacesProvider_PlaceDetails(t *testing.T) {
+	provider := NewOSMPlacesProvider()
+	location := dto.Location{Latitude: 51.5080, Longitude: -0.1280}
+	provider.AddPOI("node/1", location, map[string]string{
+		"amenity": "cafe", "name": "Corner Cafe", "contact:phone": "+44 20 7946 0000",
+		"website": "https://corner.example", "opening_hours": "Mo-Fr 08:00-18:00",
+	})
+	provider.AddPOI("node/2", location, map[string]string{"amenity": "cafe", "name": "Some Cafe"})
+
+	details, err := provider.PlaceDetails(context.Background(), "osm:node/1")
+	require.NoError(t, err)
+	assert.Equal(t, "+44 20 7946 0000", details.PhoneNumber)
+	assert.Equal(t, "https://corner.example", details.Website)
+	assert.Equal(t, []string{"Mo-Fr 08:00-18:00"}, details.OpeningHours)
+	assert.Empty(t, details.Photos)
+
+	details, err = provider.PlaceDetails(context.Background(), "osm:node/2")
+	require.NoError(t, err)
+	assert.Equal(t, dto.PlaceDetails{}, *details)
+
+	_, err = provider.PlaceDetails(context.Background(), "osm:node/3")
+	assert.ErrorIs(t, err, ErrPlaceNotFound)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/samber/lo"
+)
+
+// NearbyPlacesQuery is a search for places of a type within a radius (in meters) of a location
+// The filters are left at zero for no preference. Providers can use them to narrow down their request,
+// FilterNearbyPlaces applies them to the results of any provider.
+type NearbyPlacesQuery struct {
+	Location  dto.Location
+	Radius    int
+	PlaceType config.PlaceType
+	// MaxResults defaults to config.PlacesResultsPerType
+	MaxResults           int
+	MinRating            float64
+	MinPriceLevel        config.PriceLevel
+	MaxPriceLevel        config.PriceLevel
+	OpenNow              bool
+	WheelchairAccessible bool
+}
+
+// ResultCount is how many places the search returns at most
+func (q NearbyPlacesQuery) ResultCount() int {
+	if q.MaxResults > 0 {
+		return min(q.MaxResults, config.MaxPlacesResultsPerType)
+	}
+	return config.PlacesResultsPerType
+}
+
+// HasFilters is true if some of the places found may not match the query
+func (q NearbyPlacesQuery) HasFilters() bool {
+	return q.MinRating > 0 || q.MinPriceLevel != config.PriceLevelUnknown || q.MaxPriceLevel != config.PriceLevelUnknown ||
+		q.OpenNow || q.WheelchairAccessible
+}
+
+// ProviderResultCount is how many places to ask the provider for
+// With filters it is as many as possible, so there are enough left once the ones which don't match are dropped
+func (q NearbyPlacesQuery) ProviderResultCount() int {
+	if q.HasFilters() {
+		return config.MaxPlacesResultsPerType
+	}
+	return q.ResultCount()
+}
+
+// FilterNearbyPlaces drops the places which don't match the query's filters, and keeps at most ResultCount of them
+// Places are only dropped if the provider knows they don't match, e.g. places without a rating are kept
+func FilterNearbyPlaces(query NearbyPlacesQuery, places []dto.Place) []dto.Place {
+	filtered := lo.Filter(places, func(place dto.Place, _ int) bool {
+		if query.MinRating > 0 && place.Rating > 0 && place.Rating < query.MinRating {
+			return false
+		}
+		if place.PriceLevel != config.PriceLevelUnknown {
+			if query.MinPriceLevel != config.PriceLevelUnknown && place.PriceLevel < query.MinPriceLevel {
+				return false
+			}
+			if query.MaxPriceLevel != config.PriceLevelUnknown && place.PriceLevel > query.MaxPriceLevel {
+				return false
+			}
+		}
+		if query.OpenNow && place.OpenNow != nil && !*place.OpenNow {
+			return false
+		}
+		if query.WheelchairAccessible && place.WheelchairAccessible != nil && !*place.WheelchairAccessible {
+			return false
+		}
+		return true
+	})
+	if len(filtered) > query.ResultCount() {
+		filtered = filtered[:query.ResultCount()]
+	}
+	return filtered
+}
+
+// PlacesProvider finds places to meet at
+type PlacesProvider interface {
+	NearbyPlaces(ctx context.Context, query NearbyPlacesQuery) ([]dto.Place, error)
+}
+
+type PlacesProviderFactory func() PlacesProvider
+
+var placesProviders map[string]PlacesProviderFactory = map[string]PlacesProviderFactory{}
+
+func InjectPlacesProvider(name string, provider PlacesProviderFactory) {
+	placesProviders[name] = provider
+}
+
+func init() {
+	InjectPlacesProvider("google", func() PlacesProvider { return NewGooglePlacesProvider() })
+	InjectPlacesProvider("osm", func() PlacesProvider {
+		applogger.Warn("App: Loading OSM places from", config.OSMPlacesFile)
+		return lo.Must(LoadOSMPlacesProvider(config.OSMPlacesFile))
+	})
+	InjectPlacesProvider("fake", func() PlacesProvider { return NewFakePlacesProvider() })
+}
+
+var placesProvider PlacesProvider
+var placesProviderOnce sync.Once
+
+// GetPlacesProvider returns the places provider chosen by config, metered if a usage store was injected,
+// and wrapped in the configured cache
+func GetPlacesProvider() PlacesProvider {
+
+	placesProviderOnce.Do(func() {
+		provider, ok := placesProviders[config.PlacesProvider]
+		if !ok {
+			panic("Places provider config incorrect")
+		}
+		applogger.Warn("App: Using", config.PlacesProvider, "places provider")
+		placesProvider = provider()
+
+		if billed, ok := placesProvider.(billedPlacesProvider); ok && placesUsageStore != nil {
+			var fallback PlacesProvider
+			if config.PlacesFallbackProvider != "" {
+				fallbackProvider, ok := placesProviders[config.PlacesFallbackProvider]
+				if !ok {
+					panic("Places fallback provider config incorrect")
+				}
+				applogger.Warn("App: Falling back to", config.PlacesFallbackProvider, "places provider when places budgets are used up")
+				fallback = fallbackProvider()
+			}
+			placesProvider = NewMeteredPlacesProvider(billed, fallback, placesUsageStore, PlacesBudgets{
+				Daily:      config.PlacesDailyBudget,
+				GroupDaily: config.PlacesGroupDailyBudget,
+				UserDaily:  config.PlacesUserDailyBudget,
+			})
+		}
+
+		var cacheBackend PlacesCacheBackend
+		switch config.PlacesCache {
+		case "", "none":
+			return
+		case "memory":
+			cacheBackend = NewMemoryPlacesCache(config.PlacesCacheMaxEntries)
+		case "redis":
+			cacheBackend = lo.Must(NewRedisPlacesCache(config.RedisUrl))
+		default:
+			panic("Places cache config incorrect")
+		}
+		applogger.Warn("App: Caching places in", cacheBackend.Name(), "for", config.PlacesCacheTTL)
+		placesProvider = NewCachedPlacesProvider(
+			placesProvider,
+			cacheBackend,
+			config.PlacesCacheTTL,
+			config.PlacesCacheGeohashPrecision,
+			config.PlacesCacheRadiusBucket,
+		)
+	})
+
+	return placesProvider
+}
+
+// basePlacesProvider is the places provider chosen by config, without its cache (but still metered)
+func basePlacesProvider() PlacesProvider {
+	provider := GetPlacesProvider()
+	if cached, ok := provider.(*CachedPlacesProvider); ok {
+		return cached.provider
+	}
+	return provider
+}
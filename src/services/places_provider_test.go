@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNearbyPlacesQuery_ResultCounts(t *testing.T) {
+	query := NearbyPlacesQuery{}
+	assert.Equal(t, config.PlacesResultsPerType, query.ResultCount())
+	assert.Equal(t, config.PlacesResultsPerType, query.ProviderResultCount())
+
+	query.MaxResults = 5
+	assert.Equal(t, 5, query.ResultCount())
+	assert.Equal(t, 5, query.ProviderResultCount())
+	query.MaxResults = 50
+	assert.Equal(t, config.MaxPlacesResultsPerType, query.ResultCount())
+
+	// filtered searches ask the provider for as many places as it can give
+	query = NearbyPlacesQuery{MaxResults: 2, OpenNow: true}
+	assert.Equal(t, 2, query.ResultCount())
+	assert.Equal(t, config.MaxPlacesResultsPerType, query.ProviderResultCount())
+}
+
+func TestFilterNearbyPlaces(t *testing.T) {
+	places := []dto.Place{
+		{Id: "cheap-open", Rating: 4.5, PriceLevel: config.PriceLevelInexpensive, OpenNow: lo.ToPtr(true), WheelchairAccessible: lo.ToPtr(true)},
+		{Id: "pricey", Rating: 4.8, PriceLevel: config.PriceLevelVeryExpensive, OpenNow: lo.ToPtr(true)},
+		{Id: "closed", Rating: 4.2, PriceLevel: config.PriceLevelModerate, OpenNow: lo.ToPtr(false)},
+		{Id: "low-rated", Rating: 2.9, PriceLevel: config.PriceLevelInexpensive},
+		{Id: "stairs", Rating: 4.0, WheelchairAccessible: lo.ToPtr(false)},
+		{Id: "unknown"},
+	}
+	ids := func(places []dto.Place) []string {
+		return lo.Map(places, func(place dto.Place, _ int) string { return place.Id })
+	}
+
+	assert.Equal(t, []string{"cheap-open", "pricey", "closed"}, ids(FilterNearbyPlaces(NearbyPlacesQuery{}, places)))
+	assert.Equal(t, []string{"cheap-open", "pricey", "closed", "stairs", "unknown"},
+		ids(FilterNearbyPlaces(NearbyPlacesQuery{MaxResults: 10, MinRating: 4}, places)))
+	assert.Equal(t, []string{"cheap-open", "closed", "low-rated", "stairs", "unknown"},
+		ids(FilterNearbyPlaces(NearbyPlacesQuery{MaxResults: 10, MaxPriceLevel: config.PriceLevelModerate}, places)))
+	assert.Equal(t, []string{"pricey", "closed"},
+		ids(FilterNearbyPlaces(NearbyPlacesQuery{MaxResults: 2, MinPriceLevel: config.PriceLevelModerate}, places)))
+	assert.Equal(t, []string{"cheap-open", "pricey", "low-rated", "unknown"},
+		ids(FilterNearbyPlaces(NearbyPlacesQuery{MaxResults: 10, OpenNow: true, WheelchairAccessible: true}, places)))
+}
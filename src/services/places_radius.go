@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+// AdaptiveRadii are the radii to search in, one after the other, while too few places are found
+// They start at radius and grow by growth each step, the last one being maxRadius.
+// With a growth of 1 or less, or a maxRadius no larger than radius, only radius is searched.
+func AdaptiveRadii(radius int, maxRadius int, growth float64) []int {
+	radii := []int{radius}
+	if growth <= 1 || radius <= 0 {
+		return radii
+	}
+	for next := radius; next < maxRadius; {
+		next = min(int(math.Ceil(float64(next)*growth)), maxRadius)
+		radii = append(radii, next)
+	}
+	return radii
+}
+
+// SearchWithAdaptiveRadius runs search with the query's radius, and if fewer than minResults places are found,
+// widens it through AdaptiveRadii to the first radius which has enough of them.
+// Each search is a (billed) call to the places provider, so rather than searching every radius, it searches once more
+// with maxRadius and keeps the places within the first radius which has enough.
+// It returns the places and the radius they were found in, which is maxRadius if there still weren't enough.
+// If the wider search fails, e.g. as the places budget is used up, the places found with the query's radius are kept.
+func SearchWithAdaptiveRadius(
+	ctx context.Context,
+	query NearbyPlacesQuery,
+	maxRadius int,
+	growth float64,
+	minResults int,
+	search func(ctx context.Context, query NearbyPlacesQuery) ([]dto.Place, error),
+) ([]dto.Place, int, error) {
+	places, err := search(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	radii := AdaptiveRadii(query.Radius, maxRadius, growth)
+	if len(places) >= minResults || len(radii) == 1 {
+		return places, query.Radius, nil
+	}
+
+	wide := query
+	wide.Radius = radii[len(radii)-1]
+	widePlaces, err := search(ctx, wide)
+	if err != nil {
+		applogger.Warn("Failed to widen places search to", wide.Radius, "keeping the places found within", query.Radius, err)
+		return places, query.Radius, nil
+	}
+	for _, radius := range radii[1 : len(radii)-1] {
+		within := lo.Filter(widePlaces, func(place dto.Place, _ int) bool {
+			return geo.DistanceKm(query.Location, place.Location)*1000 <= float64(radius)
+		})
+		if len(within) >= minResults {
+			return within, radius, nil
+		}
+	}
+	return widePlaces, wide.Radius, nil
+}
+
+// NearestPlacesCluster is where to search again when nothing was found around a midpoint, even at the widest radius:
+// the centroid of the places found nearby for other searches. It returns false if there are no such places.
+func NearestPlacesCluster(places []dto.Place) (dto.Location, bool) {
+	if len(places) == 0 {
+		return dto.Location{}, false
+	}
+	return geo.Centroid(lo.Map(places, func(place dto.Place, _ int) dto.Location { return place.Location })), true
+}
+
+// PlacesRadius is the radius in meters around the midpoint which covers all the places, 0 if there are none
+func PlacesRadius(midpoint dto.Location, places []dto.Place) int {
+	if len(places) == 0 {
+		return 0
+	}
+	return int(math.Ceil(lo.Max(lo.Map(places, func(place dto.Place, _ int) float64 {
+		return geo.DistanceKm(midpoint, place.Location) * 1000
+	}))))
+}
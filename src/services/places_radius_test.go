@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveRadii(t *testing.T) {
+	assert.Equal(t, []int{1000, 2000, 4000, 5000}, AdaptiveRadii(1000, 5000, 2))
+	assert.Equal(t, []int{1000, 1500, 2250}, AdaptiveRadii(1000, 2250, 1.5))
+	assert.Equal(t, []int{1000}, AdaptiveRadii(1000, 5000, 1))
+	assert.Equal(t, []int{5000}, AdaptiveRadii(5000, 1000, 2))
+}
+
+func TestSearchWithAdaptiveRadius(t *testing.T) {
+	query := NearbyPlacesQuery{Location: dto.Location{Latitude: 51.5, Longitude: -0.1}, Radius: 1000}
+	// places 2.5km and 3.5km north, which are found once the radius is at least 3000
+	var searched []int
+	search := func(_ context.Context, query NearbyPlacesQuery) ([]dto.Place, error) {
+		searched = append(searched, query.Radius)
+		if query.Radius >= 3000 {
+			return []dto.Place{
+				{Id: "a", Location: dto.Location{Latitude: 51.5225, Longitude: -0.1}},
+				{Id: "b", Location: dto.Location{Latitude: 51.5315, Longitude: -0.1}},
+			}, nil
+		}
+		return nil, nil
+	}
+
+	t.Run("widens to the first radius with enough places", func(t *testing.T) {
+		searched = nil
+		places, radius, err := SearchWithAdaptiveRadius(context.Background(), query, 10000, 2, 1, search)
+		assert.NoError(t, err)
+		assert.Len(t, places, 2)
+		assert.Equal(t, 4000, radius)
+		// searched once more, with the max radius, rather than with each radius
+		assert.Equal(t, []int{1000, 10000}, searched)
+
+		searched = nil
+		places, radius, err = SearchWithAdaptiveRadius(context.Background(), query, 10000, 1.5, 1, search)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a"}, lo.Map(places, func(place dto.Place, _ int) string { return place.Id }))
+		assert.Equal(t, 3375, radius)
+		assert.Equal(t, []int{1000, 10000}, searched)
+	})
+
+	t.Run("stops at the max radius", func(t *testing.T) {
+		searched = nil
+		places, radius, err := SearchWithAdaptiveRadius(context.Background(), query, 2500, 2, 1, search)
+		assert.NoError(t, err)
+		assert.Empty(t, places)
+		assert.Equal(t, 2500, radius)
+		assert.Equal(t, []int{1000, 2500}, searched)
+	})
+
+	t.Run("keeps the radius when there are enough places", func(t *testing.T) {
+		searched = nil
+		wide := query
+		wide.Radius = 3000
+		_, radius, err := SearchWithAdaptiveRadius(context.Background(), wide, 10000, 2, 2, search)
+		assert.NoError(t, err)
+		assert.Equal(t, 3000, radius)
+		assert.Equal(t, []int{3000}, searched)
+	})
+
+	t.Run("fails when a search fails", func(t *testing.T) {
+		failing := func(context.Context, NearbyPlacesQuery) ([]dto.Place, error) { return nil, errors.New("boom") }
+		_, _, err := SearchWithAdaptiveRadius(context.Background(), query, 10000, 2, 1, failing)
+		assert.Error(t, err)
+	})
+
+	t.Run("keeps the places found when widening fails", func(t *testing.T) {
+		widening := func(_ context.Context, query NearbyPlacesQuery) ([]dto.Place, error) {
+			if query.Radius > 1000 {
+				return nil, ErrPlacesBudgetExceeded
+			}
+			return []dto.Place{{Id: "a"}}, nil
+		}
+		places, radius, err := SearchWithAdaptiveRadius(context.Background(), query, 10000, 2, 2, widening)
+		assert.NoError(t, err)
+		assert.Len(t, places, 1)
+		assert.Equal(t, 1000, radius)
+	})
+}
+
+func TestNearestPlacesCluster(t *testing.T) {
+	midpoint := dto.Location{Latitude: 51.5000, Longitude: -0.1000}
+	places := []dto.Place{
+		{Id: "a", Location: dto.Location{Latitude: 51.5100, Longitude: -0.1000}},
+		{Id: "b", Location: dto.Location{Latitude: 51.5200, Longitude: -0.1000}},
+	}
+
+	center, ok := NearestPlacesCluster(places)
+	assert.True(t, ok)
+	assert.InDelta(t, 51.5150, center.Latitude, 0.0001)
+	assert.InDelta(t, -0.1000, center.Longitude, 0.0001)
+	assert.InDelta(t, 2224, PlacesRadius(midpoint, places), 5)
+
+	_, ok = NearestPlacesCluster(nil)
+	assert.False(t, ok)
+	assert.Equal(t, 0, PlacesRadius(midpoint, nil))
+}
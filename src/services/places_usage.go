@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+)
+
+// ErrPlacesBudgetExceeded is returned instead of calling the places provider once a budget is used up,
+// if there is no fallback provider to call instead
+var ErrPlacesBudgetExceeded = errors.New("places budget exceeded")
+
+// PlacesCaller is who calls to the places provider are made for, an empty GroupID or 0 UserID if not known
+type PlacesCaller struct {
+	GroupID string
+	UserID  uint
+}
+
+type placesCallerKey struct{}
+
+// WithPlacesCaller attributes the calls to the places provider made with the context
+func WithPlacesCaller(ctx context.Context, caller PlacesCaller) context.Context {
+	return context.WithValue(ctx, placesCallerKey{}, caller)
+}
+
+func placesCallerFromContext(ctx context.Context) PlacesCaller {
+	caller, _ := ctx.Value(placesCallerKey{}).(PlacesCaller)
+	return caller
+}
+
+// PlacesUsageStore keeps count of the calls made to the places provider
+type PlacesUsageStore interface {
+	RecordPlacesCall(day string, operation config.PlacesOperation, caller PlacesCaller) error
+	// CountPlacesCalls counts the calls of a day, for a group and user, an empty groupID or 0 userID counting all of them
+	CountPlacesCalls(day string, groupID string, userID uint) (int64, error)
+}
+
+// PlacesBudgets are how many calls to the places provider a day can make, 0 is no limit
+type PlacesBudgets struct {
+	Daily      int64
+	GroupDaily int64
+	UserDaily  int64
+}
+
+// billedPlacesProvider is a places provider which does everything places providers can, each call costing money
+type billedPlacesProvider interface {
+	PlacesProvider
+	PlacesTextSearchProvider
+	PlaceDetailsProvider
+}
+
+// MeteredPlacesProvider counts the calls made to a places provider for each day, group and user, and stops
+// calling it once the daily budget of the app, the group or the user is used up.
+// After that, calls go to the fallback provider (if there is one and it can do the same), or fail with
+// ErrPlacesBudgetExceeded. Once the app's budget is used up, the breaker stays open until the next (UTC) day.
+// Budgets are checked before each call, so calls running at the same time can go a little over them.
+// If the usage store fails, calls are still made, so places keep working.
+type MeteredPlacesProvider struct {
+	provider billedPlacesProvider
+	fallback PlacesProvider
+	store    PlacesUsageStore
+	budgets  PlacesBudgets
+	now      func() time.Time
+
+	mu sync.Mutex
+	// openDay is the day the app's budget was used up
+	openDay string
+}
+
+// NewMeteredPlacesProvider meters a places provider, fallback is nil for none
+func NewMeteredPlacesProvider(provider billedPlacesProvider, fallback PlacesProvider, store PlacesUsageStore, budgets PlacesBudgets) *MeteredPlacesProvider {
+	return &MeteredPlacesProvider{
+		provider: provider,
+		fallback: fallback,
+		store:    store,
+		budgets:  budgets,
+		now:      time.Now,
+	}
+}
+
+func (p *MeteredPlacesProvider) NearbyPlaces(ctx context.Context, query NearbyPlacesQuery) ([]dto.Place, error) {
+	places, _, err := p.nearbyPlaces(ctx, query)
+	return places, err
+}
+
+// nearbyPlaces is NearbyPlaces, also telling if the places came from the fallback provider,
+// which the places cache doesn't keep as they would outlive the budget being used up
+func (p *MeteredPlacesProvider) nearbyPlaces(ctx context.Context, query NearbyPlacesQuery) ([]dto.Place, bool, error) {
+	if p.allow(ctx, config.PlacesOperationNearbySearch) {
+		places, err := p.provider.NearbyPlaces(ctx, query)
+		return places, false, err
+	}
+	if p.fallback == nil {
+		return nil, false, ErrPlacesBudgetExceeded
+	}
+	places, err := p.fallback.NearbyPlaces(ctx, query)
+	return places, true, err
+}
+
+func (p *MeteredPlacesProvider) TextSearchPlaces(ctx context.Context, query TextPlacesQuery) ([]dto.Place, error) {
+	if p.allow(ctx, config.PlacesOperationTextSearch) {
+		return p.provider.TextSearchPlaces(ctx, query)
+	}
+	if fallback, ok := p.fallback.(PlacesTextSearchProvider); ok {
+		return fallback.TextSearchPlaces(ctx, query)
+	}
+	return nil, ErrPlacesBudgetExceeded
+}
+
+// PlaceDetails of places found by the fallback provider come from the fallback, as the provider doesn't know them
+func (p *MeteredPlacesProvider) PlaceDetails(ctx context.Context, placeID string) (*dto.PlaceDetails, error) {
+	if fallback, ok := p.fallback.(PlaceDetailsProvider); ok && fallbackHasPlace(fallback, placeID) {
+		return fallback.PlaceDetails(ctx, placeID)
+	}
+	if p.allow(ctx, config.PlacesOperationPlaceDetails) {
+		return p.provider.PlaceDetails(ctx, placeID)
+	}
+	if fallback, ok := p.fallback.(PlaceDetailsProvider); ok {
+		return fallback.PlaceDetails(ctx, placeID)
+	}
+	return nil, ErrPlacesBudgetExceeded
+}
+
+func (p *MeteredPlacesProvider) PlacePhoto(ctx context.Context, photo dto.PlacePhoto, maxWidth int) (*PlacePhotoMedia, error) {
+	if p.allow(ctx, config.PlacesOperationPlacePhoto) {
+		return p.provider.PlacePhoto(ctx, photo, maxWidth)
+	}
+	if fallback, ok := p.fallback.(PlaceDetailsProvider); ok {
+		return fallback.PlacePhoto(ctx, photo, maxWidth)
+	}
+	return nil, ErrPlacesBudgetExceeded
+}
+
+// fallbackHasPlace is true if the fallback provider found the place, for providers which can tell
+func fallbackHasPlace(fallback any, placeID string) bool {
+	holder, ok := fallback.(interface{ hasPlace(placeID string) bool })
+	return ok && holder.hasPlace(placeID)
+}
+
+// BreakerOpen is true while the app's budget for today is used up
+func (p *MeteredPlacesProvider) BreakerOpen() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.openDay == p.today()
+}
+
+// Close closes the provider and the fallback, if they hold connections
+func (p *MeteredPlacesProvider) Close() error {
+	if closer, ok := p.fallback.(interface{ Close() error }); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	if closer, ok := p.provider.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// allow checks the budgets of the caller, and counts the call if it can be made
+func (p *MeteredPlacesProvider) allow(ctx context.Context, operation config.PlacesOperation) bool {
+	if p.BreakerOpen() {
+		return false
+	}
+	day := p.today()
+	caller := placesCallerFromContext(ctx)
+
+	budgets := []struct {
+		name    string
+		budget  int64
+		applies bool
+		groupID string
+		userID  uint
+	}{
+		{"app", p.budgets.Daily, true, "", 0},
+		{"group", p.budgets.GroupDaily, caller.GroupID != "", caller.GroupID, 0},
+		{"user", p.budgets.UserDaily, caller.UserID != 0, "", caller.UserID},
+	}
+	for _, b := range budgets {
+		if b.budget <= 0 || !b.applies {
+			continue
+		}
+		calls, err := p.store.CountPlacesCalls(day, b.groupID, b.userID)
+		if err != nil {
+			applogger.Error("Failed to count places calls", day, b.name, err)
+			continue
+		}
+		if calls >= b.budget {
+			applogger.Warn("Places", b.name, "budget of", b.budget, "calls used up for", day, caller.GroupID, caller.UserID)
+			if b.name == "app" {
+				p.mu.Lock()
+				p.openDay = day
+				p.mu.Unlock()
+			}
+			return false
+		}
+	}
+
+	if err := p.store.RecordPlacesCall(day, operation, caller); err != nil {
+		applogger.Error("Failed to record places call", day, operation, err)
+	}
+	return true
+}
+
+func (p *MeteredPlacesProvider) today() string {
+	return p.now().UTC().Format(time.DateOnly)
+}
+
+var placesUsageStore PlacesUsageStore
+
+// InjectPlacesUsageStore sets where calls to the places provider are counted, before the provider is first used
+// Without a store, calls are not metered
+func InjectPlacesUsageStore(store PlacesUsageStore) {
+	placesUsageStore = store
+}
+
+// GetMeteredPlacesProvider returns the places provider chosen by config, if its calls are metered, and nil otherwise
+func GetMeteredPlacesProvider() *MeteredPlacesProvider {
+	metered, _ := basePlacesProvider().(*MeteredPlacesProvider)
+	return metered
+}
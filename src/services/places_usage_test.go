@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryPlacesUsageStore counts calls in a map, by day, group and user
+type memoryPlacesUsageStore struct {
+	calls map[string]map[PlacesCaller]int64
+}
+
+func (s *memoryPlacesUsageStore) RecordPlacesCall(day string, _ config.PlacesOperation, caller PlacesCaller) error {
+	if s.calls[day] == nil {
+		s.calls[day] = map[PlacesCaller]int64{}
+	}
+	s.calls[day][caller]++
+	return nil
+}
+
+func (s *memoryPlacesUsageStore) CountPlacesCalls(day string, groupID string, userID uint) (int64, error) {
+	var count int64
+	for caller, calls := range s.calls[day] {
+		if (groupID == "" || caller.GroupID == groupID) && (userID == 0 || caller.UserID == userID) {
+			count += calls
+		}
+	}
+	return count, nil
+}
+
+func TestMeteredPlacesProvider(t *testing.T) {
+	query := NearbyPlacesQuery{
+		Location:  dto.Location{Latitude: 28.6139, Longitude: 77.2090},
+		Radius:    1000,
+		PlaceType: config.PlaceTypeCafe,
+	}
+	groupCtx := func(groupID string) context.Context {
+		return WithPlacesCaller(context.Background(), PlacesCaller{GroupID: groupID})
+	}
+	userCtx := func(userID uint) context.Context {
+		return WithPlacesCaller(context.Background(), PlacesCaller{UserID: userID})
+	}
+
+	t.Run("counts calls for each caller", func(t *testing.T) {
+		store := &memoryPlacesUsageStore{calls: map[string]map[PlacesCaller]int64{}}
+		provider := NewMeteredPlacesProvider(NewFakePlacesProvider(), nil, store, PlacesBudgets{})
+
+		_, err := provider.NearbyPlaces(groupCtx("a"), query)
+		assert.NoError(t, err)
+		_, err = provider.TextSearchPlaces(userCtx(1), TextPlacesQuery{Text: "cafe", Location: query.Location, Radius: 1000})
+		assert.NoError(t, err)
+
+		day := time.Now().UTC().Format(time.DateOnly)
+		assert.Equal(t, int64(1), countedCalls(store.CountPlacesCalls(day, "a", 0)))
+		assert.Equal(t, int64(1), countedCalls(store.CountPlacesCalls(day, "", 1)))
+		assert.Equal(t, int64(2), countedCalls(store.CountPlacesCalls(day, "", 0)))
+	})
+
+	t.Run("group budget only stops that group", func(t *testing.T) {
+		store := &memoryPlacesUsageStore{calls: map[string]map[PlacesCaller]int64{}}
+		provider := NewMeteredPlacesProvider(NewFakePlacesProvider(), nil, store, PlacesBudgets{GroupDaily: 1})
+
+		_, err := provider.NearbyPlaces(groupCtx("a"), query)
+		assert.NoError(t, err)
+		_, err = provider.NearbyPlaces(groupCtx("a"), query)
+		assert.ErrorIs(t, err, ErrPlacesBudgetExceeded)
+		_, err = provider.NearbyPlaces(groupCtx("b"), query)
+		assert.NoError(t, err)
+		assert.False(t, provider.BreakerOpen())
+	})
+
+	t.Run("user budget", func(t *testing.T) {
+		store := &memoryPlacesUsageStore{calls: map[string]map[PlacesCaller]int64{}}
+		provider := NewMeteredPlacesProvider(NewFakePlacesProvider(), nil, store, PlacesBudgets{UserDaily: 1})
+
+		_, err := provider.PlaceDetails(userCtx(1), "fake-place")
+		assert.NotErrorIs(t, err, ErrPlacesBudgetExceeded)
+		_, err = provider.PlaceDetails(userCtx(1), "fake-place")
+		assert.ErrorIs(t, err, ErrPlacesBudgetExceeded)
+	})
+
+	t.Run("app budget opens the breaker until the next day", func(t *testing.T) {
+		store := &memoryPlacesUsageStore{calls: map[string]map[PlacesCaller]int64{}}
+		provider := NewMeteredPlacesProvider(NewFakePlacesProvider(), nil, store, PlacesBudgets{Daily: 2})
+		now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+		provider.now = func() time.Time { return now }
+
+		for range 2 {
+			_, err := provider.NearbyPlaces(groupCtx("a"), query)
+			assert.NoError(t, err)
+		}
+		_, err := provider.NearbyPlaces(groupCtx("b"), query)
+		assert.ErrorIs(t, err, ErrPlacesBudgetExceeded)
+		assert.True(t, provider.BreakerOpen())
+
+		now = now.Add(2 * time.Hour)
+		assert.False(t, provider.BreakerOpen())
+		_, err = provider.NearbyPlaces(groupCtx("b"), query)
+		assert.NoError(t, err)
+	})
+
+	t.Run("falls back to another provider", func(t *testing.T) {
+		store := &memoryPlacesUsageStore{calls: map[string]map[PlacesCaller]int64{}}
+		fallback := &OSMPlacesProvider{}
+		provider := NewMeteredPlacesProvider(NewFakePlacesProvider(), fallback, store, PlacesBudgets{Daily: 1})
+
+		places, err := provider.NearbyPlaces(context.Background(), query)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, places)
+		// the fallback knows no places, but isn't metered
+		places, err = provider.NearbyPlaces(context.Background(), query)
+		assert.NoError(t, err)
+		assert.Empty(t, places)
+		assert.Equal(t, int64(1), countedCalls(store.CountPlacesCalls(provider.today(), "", 0)))
+	})
+
+	t.Run("details of places found by the fallback come from the fallback", func(t *testing.T) {
+		store := &memoryPlacesUsageStore{calls: map[string]map[PlacesCaller]int64{}}
+		fallback := NewOSMPlacesProvider()
+		fallback.AddPOI("42", query.Location, map[string]string{"amenity": "cafe", "name": "Corner Cafe", "phone": "+91 11 1234"})
+		provider := NewMeteredPlacesProvider(NewFakePlacesProvider(), fallback, store, PlacesBudgets{})
+
+		details, err := provider.PlaceDetails(context.Background(), "osm:42")
+		assert.NoError(t, err)
+		assert.Equal(t, "+91 11 1234", details.PhoneNumber)
+		assert.Zero(t, countedCalls(store.CountPlacesCalls(provider.today(), "", 0)))
+	})
+}
+
+func countedCalls(calls int64, _ error) int64 {
+	return calls
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/applogger"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/samber/lo"
+)
+
+// TravelTimeProvider estimates how long it takes to get from one location to another
+type TravelTimeProvider interface {
+	// TravelTimes returns the travel time in minutes from every origin to every destination,
+	// indexed as [origin][destination]. Unreachable pairs are +Inf.
+	TravelTimes(ctx context.Context, origins []dto.Location, destinations []dto.Location) ([][]float64, error)
+}
+
+// Traveller is someone setting off from a location, by some travel mode
+type Traveller struct {
+	Location dto.Location
+	Mode     config.TravelMode
+}
+
+// TravelTimeEstimator estimates travel times for travellers who use different travel modes
+// Every mode has a speed model, and a routing provider can be plugged in for any of them
+type TravelTimeEstimator struct {
+	providers map[config.TravelMode]TravelTimeProvider
+}
+
+// NewTravelTimeEstimator creates an estimator which uses the speed model of each travel mode
+func NewTravelTimeEstimator() *TravelTimeEstimator {
+	estimator := &TravelTimeEstimator{providers: map[config.TravelMode]TravelTimeProvider{}}
+	for _, mode := range config.SupportedTravelModes() {
+		estimator.providers[mode] = NewSpeedModelTravelTimeProvider(mode)
+	}
+	return estimator
+}
+
+// WithProvider plugs in a provider for a travel mode, replacing its speed model
+func (e *TravelTimeEstimator) WithProvider(mode config.TravelMode, provider TravelTimeProvider) *TravelTimeEstimator {
+	e.providers[mode] = provider
+	return e
+}
+
+var travelTimeEstimator *TravelTimeEstimator
+var travelTimeEstimatorOnce sync.Once
+
+// GetTravelTimeEstimator returns the estimator with the configured routing provider plugged in
+func GetTravelTimeEstimator() *TravelTimeEstimator {
+	travelTimeEstimatorOnce.Do(func() {
+		travelTimeEstimator = NewTravelTimeEstimator()
+
+		switch config.TravelTimeProvider {
+		case "", "straight_line":
+			return
+		case "road_graph":
+			applogger.Warn("App: Loading road graph from", config.RoadGraphFile)
+			routingProvider := lo.Must(LoadRoadGraphFromGeoJSON(config.RoadGraphFile))
+			for _, mode := range config.RoutedTravelModes {
+				travelTimeEstimator.WithProvider(mode, routingProvider)
+			}
+		case "osrm":
+			applogger.Warn("App: Using OSRM travel times from", config.OSRMUrl)
+			// every mode is routed with its own profile
+			for _, mode := range config.RoutedTravelModes {
+				profile, ok := config.OSRMProfiles[mode]
+				if !ok {
+					panic("OSRM profile missing for travel mode " + string(mode))
+				}
+				travelTimeEstimator.WithProvider(mode, NewOSRMTravelTimeProvider(config.OSRMUrl, profile))
+			}
+		default:
+			panic("Travel time provider config incorrect")
+		}
+	})
+
+	return travelTimeEstimator
+}
+
+// TravelTimes returns the minutes from every traveller to every destination, indexed as [traveller][destination]
+// Travellers without a (known) travel mode use the default one
+func (e *TravelTimeEstimator) TravelTimes(ctx context.Context, travellers []Traveller, destinations []dto.Location) ([][]float64, error) {
+	matrix := make([][]float64, len(travellers))
+
+	byMode := lo.GroupBy(lo.Range(len(travellers)), func(i int) config.TravelMode {
+		if _, ok := e.providers[travellers[i].Mode]; ok {
+			return travellers[i].Mode
+		}
+		return config.DefaultTravelMode
+	})
+	for mode, indices := range byMode {
+		origins := lo.Map(indices, func(i int, _ int) dto.Location { return travellers[i].Location })
+		times, err := e.providers[mode].TravelTimes(ctx, origins, destinations)
+		if err != nil {
+			return nil, err
+		}
+		for k, i := range indices {
+			matrix[i] = times[k]
+		}
+	}
+	return matrix, nil
+}
+
+// SpeedModelTravelTimeProvider assumes travel in a straight line at a constant speed
+// DetourFactor accounts for roads not being straight, OverheadMinutes for parking, waiting for a bus etc.
+type SpeedModelTravelTimeProvider struct {
+	SpeedKmh        float64
+	DetourFactor    float64
+	OverheadMinutes float64
+}
+
+var travelModeSpeedModels = map[config.TravelMode]SpeedModelTravelTimeProvider{
+	config.TravelModeWalk:    {SpeedKmh: 4.8, DetourFactor: 1.25, OverheadMinutes: 0},
+	config.TravelModeBike:    {SpeedKmh: 15, DetourFactor: 1.25, OverheadMinutes: 2},
+	config.TravelModeDrive:   {SpeedKmh: 30, DetourFactor: 1.3, OverheadMinutes: 5},
+	config.TravelModeTransit: {SpeedKmh: 20, DetourFactor: 1.4, OverheadMinutes: 8},
+}
+
+func NewSpeedModelTravelTimeProvider(mode config.TravelMode) *SpeedModelTravelTimeProvider {
+	model, ok := travelModeSpeedModels[mode]
+	if !ok {
+		model = travelModeSpeedModels[config.DefaultTravelMode]
+	}
+	return &model
+}
+
+func (p *SpeedModelTravelTimeProvider) TravelTimes(_ context.Context, origins []dto.Location, destinations []dto.Location) ([][]float64, error) {
+	return travelTimeMatrix(origins, destinations, func(origin dto.Location, destination dto.Location) float64 {
+		return p.OverheadMinutes + geo.DistanceKm(origin, destination)*p.DetourFactor/p.SpeedKmh*60
+	}), nil
+}
+
+// FakeTravelTimeProvider returns whatever its function says, for use in tests
+type FakeTravelTimeProvider struct {
+	Minutes func(origin dto.Location, destination dto.Location) float64
+	Err     error
+}
+
+func (p *FakeTravelTimeProvider) TravelTimes(_ context.Context, origins []dto.Location, destinations []dto.Location) ([][]float64, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return travelTimeMatrix(origins, destinations, p.Minutes), nil
+}
+
+func travelTimeMatrix(origins []dto.Location, destinations []dto.Location, minutes func(dto.Location, dto.Location) float64) [][]float64 {
+	matrix := make([][]float64, len(origins))
+	for i, origin := range origins {
+		matrix[i] = make([]float64, len(destinations))
+		for j, destination := range destinations {
+			matrix[i][j] = minutes(origin, destination)
+		}
+	}
+	return matrix
+}
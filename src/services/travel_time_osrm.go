@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+)
+
+// OSRMTravelTimeProvider asks a self-hosted OSRM (or compatible) server for travel times
+// using its table service: /table/v1/{profile}/{coordinates}
+type OSRMTravelTimeProvider struct {
+	baseURL    string
+	profile    string
+	httpClient *http.Client
+}
+
+func NewOSRMTravelTimeProvider(baseURL string, profile string) *OSRMTravelTimeProvider {
+	if profile == "" {
+		profile = "driving"
+	}
+	return &OSRMTravelTimeProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		profile:    profile,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type osrmTableResponse struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Durations [][]*float64 `json:"durations"`
+}
+
+func (p *OSRMTravelTimeProvider) TravelTimes(ctx context.Context, origins []dto.Location, destinations []dto.Location) ([][]float64, error) {
+	if len(origins) == 0 || len(destinations) == 0 {
+		return travelTimeMatrix(origins, destinations, nil), nil
+	}
+
+	coordinates := make([]string, 0, len(origins)+len(destinations))
+	sources := make([]string, len(origins))
+	targets := make([]string, len(destinations))
+	for i, origin := range origins {
+		coordinates = append(coordinates, osrmCoordinate(origin))
+		sources[i] = strconv.Itoa(i)
+	}
+	for j, destination := range destinations {
+		coordinates = append(coordinates, osrmCoordinate(destination))
+		targets[j] = strconv.Itoa(len(origins) + j)
+	}
+
+	url := fmt.Sprintf("%s/table/v1/%s/%s?sources=%s&destinations=%s&annotations=duration",
+		p.baseURL, p.profile, strings.Join(coordinates, ";"), strings.Join(sources, ";"), strings.Join(targets, ";"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var table osrmTableResponse
+	if err := json.NewDecoder(resp.Body).Decode(&table); err != nil {
+		return nil, fmt.Errorf("invalid OSRM response (status %d): %w", resp.StatusCode, err)
+	}
+	if table.Code != "Ok" {
+		return nil, fmt.Errorf("OSRM table request failed: %s %s", table.Code, table.Message)
+	}
+	if len(table.Durations) != len(origins) {
+		return nil, fmt.Errorf("OSRM returned %d rows for %d origins", len(table.Durations), len(origins))
+	}
+
+	matrix := make([][]float64, len(origins))
+	for i, row := range table.Durations {
+		matrix[i] = make([]float64, len(destinations))
+		for j := range destinations {
+			// null durations mean the destination cannot be reached
+			if j >= len(row) || row[j] == nil {
+				matrix[i][j] = math.Inf(1)
+				continue
+			}
+			matrix[i][j] = *row[j] / 60
+		}
+	}
+	return matrix, nil
+}
+
+func osrmCoordinate(loc dto.Location) string {
+	return strconv.FormatFloat(loc.Longitude, 'f', 6, 64) + "," + strconv.FormatFloat(loc.Latitude, 'f', 6, 64)
+}
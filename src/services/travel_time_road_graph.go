@@ -0,0 +1,334 @@
+package services
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+)
+
+// speeds in km/h used when a road has no (parseable) maxspeed tag
+var roadGraphDefaultSpeeds = map[string]float64{
+	"motorway":       100,
+	"motorway_link":  60,
+	"trunk":          80,
+	"trunk_link":     50,
+	"primary":        60,
+	"primary_link":   40,
+	"secondary":      50,
+	"secondary_link": 40,
+	"tertiary":       40,
+	"tertiary_link":  30,
+	"unclassified":   30,
+	"residential":    30,
+	"living_street":  10,
+	"service":        20,
+}
+
+const (
+	roadGraphFallbackSpeedKmh = 30
+	// walking speed used to get from a location to the nearest node of the graph
+	roadGraphOffRoadSpeedKmh = 5
+	// size of the cells of the nearest-node index, in degrees
+	roadGraphCellSize = 0.01
+	// locations further than this many cells (~100km) from any road are unreachable
+	roadGraphMaxSearchRings = 100
+)
+
+type roadGraphEdge struct {
+	to      int
+	minutes float64
+}
+
+type roadGraphCell struct {
+	lat int
+	lng int
+}
+
+// RoadGraph is a TravelTimeProvider which runs Dijkstra over a road network held in memory
+type RoadGraph struct {
+	nodes     []dto.Location
+	edges     [][]roadGraphEdge
+	nodeIDs   map[[2]int64]int
+	cellIndex map[roadGraphCell][]int
+}
+
+type geoJSONRoads struct {
+	Features []struct {
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	} `json:"features"`
+}
+
+// LoadRoadGraphFromGeoJSON builds a road graph from the LineString and MultiLineString features of
+// a GeoJSON FeatureCollection, e.g. an OSM extract exported with `osmium export -f geojson`.
+// The OSM `highway`, `maxspeed` and `oneway` properties are used for speeds and directions.
+// Features without a `highway` tag (rivers, railways, boundaries etc.) aren't roads, and are skipped.
+func LoadRoadGraphFromGeoJSON(path string) (*RoadGraph, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var roads geoJSONRoads
+	if err := json.Unmarshal(file, &roads); err != nil {
+		return nil, fmt.Errorf("invalid road graph geojson: %w", err)
+	}
+
+	graph := NewRoadGraph()
+	for _, feature := range roads.Features {
+		var lines [][][]float64
+		switch feature.Geometry.Type {
+		case "LineString":
+			var line [][]float64
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &line); err != nil {
+				return nil, fmt.Errorf("invalid road geometry: %w", err)
+			}
+			lines = append(lines, line)
+		case "MultiLineString":
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &lines); err != nil {
+				return nil, fmt.Errorf("invalid road geometry: %w", err)
+			}
+		default:
+			continue
+		}
+		if feature.Properties["highway"] == nil {
+			continue
+		}
+
+		speed := roadSpeedKmh(feature.Properties)
+		oneway := fmt.Sprint(feature.Properties["oneway"])
+		for _, line := range lines {
+			path := make([]dto.Location, 0, len(line))
+			for _, coord := range line {
+				if len(coord) < 2 {
+					continue
+				}
+				// GeoJSON is [longitude, latitude]
+				path = append(path, dto.Location{Latitude: coord[1], Longitude: coord[0]})
+			}
+			switch oneway {
+			case "yes", "true", "1":
+				graph.AddRoad(path, speed, true)
+			case "-1", "reverse":
+				reversed := make([]dto.Location, len(path))
+				for i := range path {
+					reversed[i] = path[len(path)-1-i]
+				}
+				graph.AddRoad(reversed, speed, true)
+			default:
+				graph.AddRoad(path, speed, false)
+			}
+		}
+	}
+
+	if len(graph.nodes) == 0 {
+		return nil, errors.New("road graph has no roads")
+	}
+	return graph, nil
+}
+
+func NewRoadGraph() *RoadGraph {
+	return &RoadGraph{
+		nodeIDs:   map[[2]int64]int{},
+		cellIndex: map[roadGraphCell][]int{},
+	}
+}
+
+// AddRoad adds a road going through the points in order
+// Points shared with other roads (to ~10cm) become junctions
+func (g *RoadGraph) AddRoad(points []dto.Location, speedKmh float64, oneway bool) {
+	for i := 1; i < len(points); i++ {
+		from := g.nodeID(points[i-1])
+		to := g.nodeID(points[i])
+		if from == to {
+			continue
+		}
+		minutes := geo.DistanceKm(points[i-1], points[i]) / speedKmh * 60
+		g.edges[from] = append(g.edges[from], roadGraphEdge{to: to, minutes: minutes})
+		if !oneway {
+			g.edges[to] = append(g.edges[to], roadGraphEdge{to: from, minutes: minutes})
+		}
+	}
+}
+
+func (g *RoadGraph) nodeID(loc dto.Location) int {
+	key := [2]int64{int64(math.Round(loc.Latitude * 1e6)), int64(math.Round(loc.Longitude * 1e6))}
+	if id, ok := g.nodeIDs[key]; ok {
+		return id
+	}
+	id := len(g.nodes)
+	g.nodes = append(g.nodes, loc)
+	g.edges = append(g.edges, nil)
+	g.nodeIDs[key] = id
+	cell := roadGraphCellOf(loc)
+	g.cellIndex[cell] = append(g.cellIndex[cell], id)
+	return id
+}
+
+func roadGraphCellOf(loc dto.Location) roadGraphCell {
+	return roadGraphCell{
+		lat: int(math.Floor(loc.Latitude / roadGraphCellSize)),
+		lng: int(math.Floor(loc.Longitude / roadGraphCellSize)),
+	}
+}
+
+// nearestNode searches rings of cells around the location until it finds a node
+// after the first hit, rings are searched until they are further away than the node found, as a node
+// in a later ring (e.g. straight north, while the first hit is in a corner) can still be closer
+// returns -1 if there is no node nearby
+func (g *RoadGraph) nearestNode(loc dto.Location) (int, float64) {
+	center := roadGraphCellOf(loc)
+	best, bestKm := -1, math.Inf(1)
+	for ring := 0; ring <= roadGraphMaxSearchRings; ring++ {
+		if best >= 0 && roadGraphRingGapKm(loc, ring) >= bestKm {
+			break
+		}
+		for dLat := -ring; dLat <= ring; dLat++ {
+			for dLng := -ring; dLng <= ring; dLng++ {
+				if dLat != -ring && dLat != ring && dLng != -ring && dLng != ring {
+					continue
+				}
+				for _, id := range g.cellIndex[roadGraphCell{lat: center.lat + dLat, lng: center.lng + dLng}] {
+					if km := geo.DistanceKm(loc, g.nodes[id]); km < bestKm {
+						best, bestKm = id, km
+					}
+				}
+			}
+		}
+	}
+	return best, bestKm
+}
+
+// roadGraphRingGapKm is how close a node in the given ring of cells around the location can be to it
+// the location can be anywhere in its own cell, so the ring is at least ring-1 cells away,
+// and cells are narrowest east-west at the poleward edge of the ring
+func roadGraphRingGapKm(loc dto.Location, ring int) float64 {
+	if ring <= 1 {
+		return 0
+	}
+	poleward := math.Min(90, math.Abs(loc.Latitude)+float64(ring+1)*roadGraphCellSize)
+	gapKm := geo.DistanceKm(dto.Location{}, dto.Location{Latitude: float64(ring-1) * roadGraphCellSize})
+	return gapKm * math.Cos(poleward*math.Pi/180)
+}
+
+func (g *RoadGraph) TravelTimes(ctx context.Context, origins []dto.Location, destinations []dto.Location) ([][]float64, error) {
+	if len(g.nodes) == 0 {
+		return nil, errors.New("road graph is empty")
+	}
+
+	destinationNodes := make([]int, len(destinations))
+	destinationWalks := make([]float64, len(destinations))
+	for j, destination := range destinations {
+		node, km := g.nearestNode(destination)
+		destinationNodes[j] = node
+		destinationWalks[j] = km / roadGraphOffRoadSpeedKmh * 60
+	}
+
+	matrix := make([][]float64, len(origins))
+	for i, origin := range origins {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		matrix[i] = make([]float64, len(destinations))
+		node, km := g.nearestNode(origin)
+		if node < 0 {
+			for j := range destinations {
+				matrix[i][j] = math.Inf(1)
+			}
+			continue
+		}
+		originWalk := km / roadGraphOffRoadSpeedKmh * 60
+		pathMinutes := g.shortestPaths(node, destinationNodes)
+
+		for j := range destinations {
+			if destinationNodes[j] < 0 {
+				matrix[i][j] = math.Inf(1)
+				continue
+			}
+			matrix[i][j] = originWalk + pathMinutes[destinationNodes[j]] + destinationWalks[j]
+		}
+	}
+	return matrix, nil
+}
+
+// shortestPaths runs Dijkstra from source until all targets are settled
+// returns minutes to every node reached, unreached nodes are +Inf
+func (g *RoadGraph) shortestPaths(source int, targets []int) []float64 {
+	minutes := make([]float64, len(g.nodes))
+	for i := range minutes {
+		minutes[i] = math.Inf(1)
+	}
+	pending := map[int]bool{}
+	for _, target := range targets {
+		if target >= 0 {
+			pending[target] = true
+		}
+	}
+
+	settled := make([]bool, len(g.nodes))
+	minutes[source] = 0
+	queue := &roadGraphQueue{{node: source, minutes: 0}}
+	for queue.Len() > 0 && len(pending) > 0 {
+		item := heap.Pop(queue).(roadGraphQueueItem)
+		if settled[item.node] {
+			continue
+		}
+		settled[item.node] = true
+		delete(pending, item.node)
+
+		for _, edge := range g.edges[item.node] {
+			if next := item.minutes + edge.minutes; next < minutes[edge.to] {
+				minutes[edge.to] = next
+				heap.Push(queue, roadGraphQueueItem{node: edge.to, minutes: next})
+			}
+		}
+	}
+	return minutes
+}
+
+func roadSpeedKmh(properties map[string]any) float64 {
+	if maxspeed, ok := properties["maxspeed"]; ok {
+		raw := strings.TrimSpace(fmt.Sprint(maxspeed))
+		multiplier := 1.0
+		if strings.HasSuffix(raw, "mph") {
+			raw = strings.TrimSpace(strings.TrimSuffix(raw, "mph"))
+			multiplier = 1.609344
+		}
+		if speed, err := strconv.ParseFloat(raw, 64); err == nil && speed > 0 {
+			return speed * multiplier
+		}
+	}
+	if speed, ok := roadGraphDefaultSpeeds[fmt.Sprint(properties["highway"])]; ok {
+		return speed
+	}
+	return roadGraphFallbackSpeedKmh
+}
+
+type roadGraphQueueItem struct {
+	node    int
+	minutes float64
+}
+
+type roadGraphQueue []roadGraphQueueItem
+
+func (q roadGraphQueue) Len() int           { return len(q) }
+func (q roadGraphQueue) Less(i, j int) bool { return q[i].minutes < q[j].minutes }
+func (q roadGraphQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *roadGraphQueue) Push(x any)        { *q = append(*q, x.(roadGraphQueueItem)) }
+func (q *roadGraphQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// two north-south roads 0.02° (~1.4km) apart, joined by a single bridge at the north end
+const testRoadsGeoJSON = `{
+	"type": "FeatureCollection",
+	"features": [
+		{"type": "Feature", "properties": {"highway": "residential"},
+		 "geometry": {"type": "LineString", "coordinates": [[0.00, 51.00], [0.00, 51.01], [0.00, 51.02]]}},
+		{"type": "Feature", "properties": {"highway": "residential", "maxspeed": "20 mph"},
+		 "geometry": {"type": "LineString", "coordinates": [[0.02, 51.00], [0.02, 51.01], [0.02, 51.02]]}},
+		{"type": "Feature", "properties": {"highway": "primary", "name": "bridge"},
+		 "geometry": {"type": "LineString", "coordinates": [[0.00, 51.02], [0.02, 51.02]]}},
+		{"type": "Feature", "properties": {"waterway": "river"},
+		 "geometry": {"type": "LineString", "coordinates": [[0.00, 51.00], [0.02, 51.00]]}},
+		{"type": "Feature", "properties": {"amenity": "cafe"},
+		 "geometry": {"type": "Point", "coordinates": [0.01, 51.01]}}
+	]
+}`
+
+func loadTestRoadGraph(t *testing.T) *RoadGraph {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "roads.geojson")
+	require.NoError(t, os.WriteFile(path, []byte(testRoadsGeoJSON), 0o644))
+	graph, err := LoadRoadGraphFromGeoJSON(path)
+	require.NoError(t, err)
+	return graph
+}
+
+func TestRoadGraph_TravelTimes(t *testing.T) {
+	graph := loadTestRoadGraph(t)
+	west := dto.Location{Latitude: 51.00, Longitude: 0.00}
+	east := dto.Location{Latitude: 51.00, Longitude: 0.02}
+
+	times, err := graph.TravelTimes(context.Background(), []dto.Location{west}, []dto.Location{west, east})
+	require.NoError(t, err)
+	assert.InDelta(t, 0, times[0][0], 0.001)
+
+	// the only way across is north to the bridge and back down again, the river isn't a road
+	expected := geo.DistanceKm(west, dto.Location{Latitude: 51.02, Longitude: 0.00})/30*60 +
+		geo.DistanceKm(dto.Location{Latitude: 51.02, Longitude: 0.00}, dto.Location{Latitude: 51.02, Longitude: 0.02})/60*60 +
+		geo.DistanceKm(dto.Location{Latitude: 51.02, Longitude: 0.02}, east)/(20*1.609344)*60
+	assert.InDelta(t, expected, times[0][1], 0.001)
+}
+
+func TestRoadGraph_EmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.geojson")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type": "FeatureCollection", "features": []}`), 0o644))
+	_, err := LoadRoadGraphFromGeoJSON(path)
+	assert.Error(t, err)
+}
+
+func TestRoadGraph_OnewayAndUnreachable(t *testing.T) {
+	graph := NewRoadGraph()
+	a := dto.Location{Latitude: 10, Longitude: 10}
+	b := dto.Location{Latitude: 10, Longitude: 10.01}
+	graph.AddRoad([]dto.Location{a, b}, 30, true)
+
+	times, err := graph.TravelTimes(context.Background(), []dto.Location{a, b}, []dto.Location{a, b})
+	require.NoError(t, err)
+	assert.False(t, math.IsInf(times[0][1], 1))
+	assert.True(t, math.IsInf(times[1][0], 1))
+
+	// far away from any road
+	times, err = graph.TravelTimes(context.Background(), []dto.Location{{Latitude: 40, Longitude: 40}}, []dto.Location{a})
+	require.NoError(t, err)
+	assert.True(t, math.IsInf(times[0][0], 1))
+}
+
+func TestRoadGraph_NearestNode(t *testing.T) {
+	graph := NewRoadGraph()
+	loc := dto.Location{Latitude: 0.0001, Longitude: 0.0001}
+	// in the corner of the first ring of cells around loc
+	corner := dto.Location{Latitude: 0.0199, Longitude: 0.0199}
+	graph.AddRoad([]dto.Location{corner, {Latitude: 0.0199, Longitude: 0.0299}}, 30, false)
+	// closer, but three rings south
+	south := dto.Location{Latitude: -0.0201, Longitude: 0.0001}
+	graph.AddRoad([]dto.Location{south, {Latitude: -0.0301, Longitude: 0.0001}}, 30, false)
+
+	node, km := graph.nearestNode(loc)
+	assert.Equal(t, 2, node)
+	assert.InDelta(t, geo.DistanceKm(loc, south), km, 0.0001)
+}
+
+func TestOSRMTravelTimeProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/table/v1/driving/0.000000,51.000000;0.020000,51.000000;"))
+		assert.Equal(t, "sources=0;1&destinations=2&annotations=duration", r.URL.RawQuery)
+		w.Write([]byte(`{"code": "Ok", "durations": [[600], [null]]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOSRMTravelTimeProvider(server.URL+"/", "")
+	times, err := provider.TravelTimes(context.Background(),
+		[]dto.Location{{Latitude: 51, Longitude: 0}, {Latitude: 51, Longitude: 0.02}},
+		[]dto.Location{{Latitude: 51.01, Longitude: 0.01}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, times[0][0])
+	assert.True(t, math.IsInf(times[1][0], 1))
+}
+
+func travellersFrom(locations []dto.Location, mode config.TravelMode) []Traveller {
+	travellers := make([]Traveller, len(locations))
+	for i, loc := range locations {
+		travellers[i] = Traveller{Location: loc, Mode: mode}
+	}
+	return travellers
+}
+
+func fakeTravelTimeEstimator(provider TravelTimeProvider) *TravelTimeEstimator {
+	estimator := NewTravelTimeEstimator()
+	for _, mode := range config.SupportedTravelModes() {
+		estimator.WithProvider(mode, provider)
+	}
+	return estimator
+}
+
+func TestSelectMidpoint(t *testing.T) {
+	locations := []dto.Location{
+		{Latitude: 51.00, Longitude: 0.00},
+		{Latitude: 51.00, Longitude: 0.06},
+		{Latitude: 51.06, Longitude: 0.00},
+	}
+	travellers := travellersFrom(locations, config.TravelModeDrive)
+
+	centroid, err := SelectMidpoint(context.Background(), nil, travellers, config.MidpointStrategyCentroid)
+	require.NoError(t, err)
+	assert.Equal(t, geo.Centroid(locations), centroid)
+
+	// the fake makes everything south of 51.03 twice as slow to reach, like a river in the way
+	estimator := fakeTravelTimeEstimator(&FakeTravelTimeProvider{Minutes: func(origin dto.Location, destination dto.Location) float64 {
+		minutes := geo.DistanceKm(origin, destination)
+		if destination.Latitude < 51.03 {
+			minutes *= 2
+		}
+		return minutes
+	}})
+	for _, strategy := range []config.MidpointStrategy{config.MidpointStrategyMinTotalTime, config.MidpointStrategyMinMaxTime} {
+		midpoint, err := SelectMidpoint(context.Background(), estimator, travellers, strategy)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, midpoint.Latitude, 51.03, strategy)
+	}
+}
+
+func TestSelectMidpoint_TravelModes(t *testing.T) {
+	walker := Traveller{Location: dto.Location{Latitude: 51.00, Longitude: 0.00}, Mode: config.TravelModeWalk}
+	driver := Traveller{Location: dto.Location{Latitude: 51.00, Longitude: 0.10}, Mode: config.TravelModeDrive}
+
+	// the walker is much slower, so the fairest place is much closer to them
+	midpoint, err := SelectMidpoint(context.Background(), NewTravelTimeEstimator(), []Traveller{walker, driver}, config.MidpointStrategyMinMaxTime)
+	require.NoError(t, err)
+	assert.Less(t, midpoint.Longitude, 0.03)
+}
+
+func TestSelectMidpoint_Unreachable(t *testing.T) {
+	locations := []dto.Location{
+		{Latitude: 51.00, Longitude: 0.00},
+		{Latitude: 51.00, Longitude: 0.06},
+	}
+	estimator := fakeTravelTimeEstimator(&FakeTravelTimeProvider{Minutes: func(dto.Location, dto.Location) float64 { return math.Inf(1) }})
+
+	midpoint, err := SelectMidpoint(context.Background(), estimator, travellersFrom(locations, config.TravelModeWalk), config.MidpointStrategyMinMaxTime)
+	require.NoError(t, err)
+	assert.Equal(t, geo.Centroid(locations), midpoint)
+}
+
+func TestTravelTimeEstimator_TravelTimes(t *testing.T) {
+	origin := dto.Location{Latitude: 51.00, Longitude: 0.00}
+	destination := dto.Location{Latitude: 51.00, Longitude: 0.10}
+	routed := &FakeTravelTimeProvider{Minutes: func(dto.Location, dto.Location) float64 { return 42 }}
+	estimator := NewTravelTimeEstimator().WithProvider(config.TravelModeTransit, routed)
+
+	times, err := estimator.TravelTimes(context.Background(), []Traveller{
+		{Location: origin, Mode: config.TravelModeWalk},
+		{Location: origin, Mode: config.TravelModeBike},
+		{Location: origin, Mode: config.TravelModeTransit},
+		{Location: origin, Mode: ""},
+	}, []dto.Location{destination})
+	require.NoError(t, err)
+
+	km := geo.DistanceKm(origin, destination)
+	assert.InDelta(t, km*1.25/4.8*60, times[0][0], 0.001)
+	assert.InDelta(t, 2+km*1.25/15*60, times[1][0], 0.001)
+	assert.Equal(t, 42.0, times[2][0])
+	// no travel mode means the default (drive)
+	assert.InDelta(t, 5+km*1.3/30*60, times[3][0], 0.001)
+}
+
+func TestMidpointCost(t *testing.T) {
+	assert.Equal(t, 30.0, MidpointCost([]float64{10, 20}, config.MidpointStrategyMinTotalTime))
+	assert.Equal(t, 20.0, MidpointCost([]float64{10, 20}, config.MidpointStrategyMinMaxTime))
+}
@@ -0,0 +1,22 @@
+package services
+
+import "github.com/samber/lo"
+
+// BordaCount scores ranked ballots, most preferred first: on a ballot of n places, the r-th gets n-r+1 points
+// Places which aren't candidates (any more) are dropped from the ballots first, so they don't give the rest extra points
+func BordaCount(ballots [][]string, candidates []string) map[string]int {
+	points := make(map[string]int, len(candidates))
+	for _, candidate := range candidates {
+		points[candidate] = 0
+	}
+	for _, ballot := range ballots {
+		ranked := lo.Uniq(lo.Filter(ballot, func(placeID string, _ int) bool {
+			_, ok := points[placeID]
+			return ok
+		}))
+		for r, placeID := range ranked {
+			points[placeID] += len(ranked) - r
+		}
+	}
+	return points
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBordaCount(t *testing.T) {
+	candidates := []string{"a", "b", "c"}
+
+	t.Run("no ballots", func(t *testing.T) {
+		assert.Equal(t, map[string]int{"a": 0, "b": 0, "c": 0}, BordaCount(nil, candidates))
+	})
+
+	t.Run("points by rank", func(t *testing.T) {
+		points := BordaCount([][]string{{"a", "b", "c"}, {"b", "a"}, {"c"}}, candidates)
+		assert.Equal(t, map[string]int{"a": 3 + 1, "b": 2 + 2, "c": 1 + 1}, points)
+	})
+
+	t.Run("places which are gone are dropped from ballots", func(t *testing.T) {
+		points := BordaCount([][]string{{"gone", "a", "b"}}, candidates)
+		assert.Equal(t, map[string]int{"a": 2, "b": 1, "c": 0}, points)
+	})
+}
@@ -0,0 +1,88 @@
+package geo
+
+import (
+	"math"
+	"sort"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/umahmood/haversine"
+)
+
+// DistanceKm returns the great-circle distance between two locations in kilometers
+func DistanceKm(loc1 dto.Location, loc2 dto.Location) float64 {
+	_, km := haversine.Distance(
+		haversine.Coord{Lat: loc1.Latitude, Lon: loc1.Longitude},
+		haversine.Coord{Lat: loc2.Latitude, Lon: loc2.Longitude},
+	)
+	return km
+}
+
+// Centroid returns the arithmetic mean of the latitudes and longitudes
+// returns a zero location if there are no points
+func Centroid(points []dto.Location) dto.Location {
+	if len(points) == 0 {
+		return dto.Location{}
+	}
+	var sumLat, sumLng float64
+	for _, p := range points {
+		sumLat += p.Latitude
+		sumLng += p.Longitude
+	}
+	return dto.Location{
+		Latitude:  sumLat / float64(len(points)),
+		Longitude: sumLng / float64(len(points)),
+	}
+}
+
+// ComponentMedian returns the median latitude and median longitude of the points
+// it is a robust centre that a single far-away point cannot drag around
+func ComponentMedian(points []dto.Location) dto.Location {
+	lats := make([]float64, len(points))
+	lngs := make([]float64, len(points))
+	for i, p := range points {
+		lats[i] = p.Latitude
+		lngs[i] = p.Longitude
+	}
+	return dto.Location{
+		Latitude:  Median(lats),
+		Longitude: Median(lngs),
+	}
+}
+
+// Median returns the median of the values, or 0 if there are none
+// the input slice is not modified
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// MedianAbsoluteDeviation returns the median of the absolute deviations from the median
+func MedianAbsoluteDeviation(values []float64) float64 {
+	median := Median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return Median(deviations)
+}
+
+// SnapToGrid returns the centre of the cell of a grid of roughly cellMeters squares which the location is in,
+// so every location in the cell snaps to the same place, at most half the cell's diagonal away
+func SnapToGrid(loc dto.Location, cellMeters float64) dto.Location {
+	const metersPerDegree = 111320
+	latStep := cellMeters / metersPerDegree
+	lat := math.Max(-90, math.Min(90, (math.Floor(loc.Latitude/latStep)+0.5)*latStep))
+	// cells keep their width in meters by spanning more degrees of longitude away from the equator
+	lngStep := math.Min(latStep/math.Max(math.Cos(lat*math.Pi/180), 0.01), 360)
+	lng := (math.Floor(loc.Longitude/lngStep) + 0.5) * lngStep
+	return dto.Location{Latitude: lat, Longitude: lng}
+}
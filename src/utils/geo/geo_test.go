@@ -0,0 +1,25 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapToGrid(t *testing.T) {
+	home := dto.Location{Latitude: 51.5074, Longitude: -0.1278}
+	snapped := SnapToGrid(home, 500)
+	assert.NotEqual(t, home, snapped)
+	assert.LessOrEqual(t, DistanceKm(home, snapped), 0.36)
+
+	// everywhere in the same cell snaps to the same place
+	assert.Equal(t, snapped, SnapToGrid(snapped, 500))
+	nearby := dto.Location{Latitude: snapped.Latitude + 0.001, Longitude: snapped.Longitude - 0.001}
+	assert.Equal(t, snapped, SnapToGrid(nearby, 500))
+
+	// cells are about as wide as they are tall, even far from the equator
+	north := SnapToGrid(dto.Location{Latitude: 69.6492, Longitude: 18.9553}, 500)
+	assert.LessOrEqual(t, DistanceKm(dto.Location{Latitude: 69.6492, Longitude: 18.9553}, north), 0.36)
+	assert.LessOrEqual(t, SnapToGrid(dto.Location{Latitude: 89.9999, Longitude: 10}, 500).Latitude, 90.0)
+}
@@ -0,0 +1,87 @@
+package geo
+
+import (
+	"math"
+	"strings"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+)
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash encodes a location as a geohash of the given number of characters
+func Geohash(loc dto.Location, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLng, maxLng := -180.0, 180.0
+
+	var hash strings.Builder
+	bit, ch, even := 0, 0, true
+	for hash.Len() < precision {
+		// bits alternate between longitude and latitude, starting with longitude
+		if even {
+			mid := (minLng + maxLng) / 2
+			if loc.Longitude >= mid {
+				ch = ch<<1 | 1
+				minLng = mid
+			} else {
+				ch <<= 1
+				maxLng = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if loc.Latitude >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch <<= 1
+				maxLat = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			hash.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return hash.String()
+}
+
+// GeohashCellSize returns the height and width in degrees of geohash cells of the given precision
+func GeohashCellSize(precision int) (latDegrees float64, lngDegrees float64) {
+	lngBits := (5*precision + 1) / 2
+	latBits := 5 * precision / 2
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lngBits))
+}
+
+// GeohashesInRadius returns the geohashes of all cells which overlap a circle of radiusKm around loc
+// (a few cells just outside it may be included too)
+func GeohashesInRadius(loc dto.Location, radiusKm float64, precision int) []string {
+	latDelta := radiusKm / 111.32
+	lngDelta := radiusKm / (111.32 * math.Max(math.Cos(loc.Latitude*math.Pi/180), 0.01))
+	cellLat, cellLng := GeohashCellSize(precision)
+
+	seen := map[string]bool{}
+	var hashes []string
+	add := func(lat float64, lng float64) {
+		hash := Geohash(dto.Location{Latitude: math.Max(-90, math.Min(90, lat)), Longitude: math.Max(-180, math.Min(180, lng))}, precision)
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+	// step through the bounding box a cell at a time, making sure the far edges are covered too
+	for lat := loc.Latitude - latDelta; ; lat += cellLat {
+		lat = math.Min(lat, loc.Latitude+latDelta)
+		for lng := loc.Longitude - lngDelta; ; lng += cellLng {
+			lng = math.Min(lng, loc.Longitude+lngDelta)
+			add(lat, lng)
+			if lng >= loc.Longitude+lngDelta {
+				break
+			}
+		}
+		if lat >= loc.Latitude+latDelta {
+			break
+		}
+	}
+	return hashes
+}
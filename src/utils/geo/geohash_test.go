@@ -0,0 +1,30 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeohash(t *testing.T) {
+	// well known example from the geohash wikipedia page
+	assert.Equal(t, "ezs42", Geohash(dto.Location{Latitude: 42.605, Longitude: -5.603}, 5))
+	assert.Equal(t, "gcpvj0", Geohash(dto.Location{Latitude: 51.5072, Longitude: -0.1276}, 6))
+}
+
+func TestGeohashCellSize(t *testing.T) {
+	lat, lng := GeohashCellSize(5)
+	assert.InDelta(t, 0.0439, lat, 0.0001)
+	assert.InDelta(t, 0.0439, lng, 0.0001)
+}
+
+func TestGeohashesInRadius(t *testing.T) {
+	center := dto.Location{Latitude: 51.5072, Longitude: -0.1276}
+	hashes := GeohashesInRadius(center, 5, 5)
+	assert.Contains(t, hashes, Geohash(center, 5))
+	// points on the edge of the circle are covered
+	assert.Contains(t, hashes, Geohash(dto.Location{Latitude: 51.5072 + 5/111.32, Longitude: -0.1276}, 5))
+	assert.Contains(t, hashes, Geohash(dto.Location{Latitude: 51.5072, Longitude: -0.1276 - 0.072}, 5))
+	assert.NotContains(t, hashes, Geohash(dto.Location{Latitude: 51.7520, Longitude: -1.2577}, 5))
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/championswimmer/api.midpoint.place/src/config"
 	"github.com/championswimmer/api.midpoint.place/src/dto"
@@ -70,3 +71,24 @@ func TestUtil_CreateGroup(t *testing.T, creatorToken string, groupName string) (
 
 	return &response
 }
+
+// TestUtil_WaitForGroupRefresh waits for the group's midpoint and places to be refreshed after a change
+func TestUtil_WaitForGroupRefresh(t *testing.T, token string, groupID string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		req := httptest.NewRequest("GET", "/v1/groups/"+groupID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(App.Test(req, -1))
+
+		// error responses don't say if the refresh is pending, so they are retried
+		var response dto.GroupResponse
+		body := lo.Must(io.ReadAll(resp.Body))
+		if resp.StatusCode == fiber.StatusOK && json.Unmarshal(body, &response) == nil && !response.RefreshPending {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("group refresh still pending for", groupID, "status", resp.StatusCode, string(body))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,50 @@
+package e2e
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminJobs(t *testing.T) {
+	admin := tests.TestUtil_CreateUser(t, "testadmin5901@test.com", "testpassword5901")
+	user := tests.TestUtil_CreateUser(t, "testuser5901@test.com", "testpassword5901")
+
+	request := func(method string, path string, token string) (int, []byte) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+
+	t.Run("non admin is forbidden", func(t *testing.T) {
+		status, _ := request(fiber.MethodGet, "/v1/admin/jobs", user.Token)
+		assert.Equal(t, fiber.StatusForbidden, status)
+	})
+
+	t.Run("admin lists dead jobs", func(t *testing.T) {
+		status, body := request(fiber.MethodGet, "/v1/admin/jobs?status=dead", admin.Token)
+		assert.Equal(t, fiber.StatusOK, status)
+		var jobs []dto.JobResponse
+		assert.NoError(t, json.Unmarshal(body, &jobs))
+	})
+
+	t.Run("invalid status is rejected", func(t *testing.T) {
+		status, _ := request(fiber.MethodGet, "/v1/admin/jobs?status=done", admin.Token)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("retrying a job which isn't dead fails", func(t *testing.T) {
+		status, _ := request(fiber.MethodPost, "/v1/admin/jobs/999999/retry", admin.Token)
+		assert.Equal(t, fiber.StatusNotFound, status)
+		status, _ = request(fiber.MethodPost, "/v1/admin/jobs/abc/retry", admin.Token)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+}
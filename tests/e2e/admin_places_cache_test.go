@@ -0,0 +1,38 @@
+package e2e
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminPlacesCacheStats(t *testing.T) {
+	admin := tests.TestUtil_CreateUser(t, "testadmin5801@test.com", "testpassword5801")
+	user := tests.TestUtil_CreateUser(t, "testuser5801@test.com", "testpassword5801")
+
+	t.Run("non admin is forbidden", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/v1/admin/places-cache", nil)
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+		assert.Equal(t, fiber.StatusForbidden, lo.Must(tests.App.Test(req, -1)).StatusCode)
+	})
+
+	t.Run("admin sees cache counters", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/v1/admin/places-cache", nil)
+		req.Header.Set("Authorization", "Bearer "+admin.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var stats dto.PlacesCacheStats
+		assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &stats))
+		assert.True(t, stats.Enabled)
+		assert.Equal(t, "memory", stats.Backend)
+		assert.GreaterOrEqual(t, stats.Hits+stats.Misses, int64(0))
+	})
+}
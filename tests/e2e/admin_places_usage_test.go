@@ -0,0 +1,76 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminPlacesUsage(t *testing.T) {
+	admin := tests.TestUtil_CreateUser(t, "testadmin6801@test.com", "testpassword6801")
+	user := tests.TestUtil_CreateUser(t, "testuser6801@test.com", "testpassword6801")
+	group := tests.TestUtil_CreateGroup(t, user.Token, "Test Group 6801")
+
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1"+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	getUsage := func(query string) dto.PlacesUsage {
+		status, body := send(fiber.MethodGet, "/admin/places-usage"+query, admin.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var usage dto.PlacesUsage
+		require.NoError(t, json.Unmarshal(body, &usage))
+		return usage
+	}
+
+	status, _ := send(fiber.MethodPut, "/groups/"+group.ID+"/join", user.Token, dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 12.9716, Longitude: 77.5946}})
+	require.Equal(t, fiber.StatusAccepted, status)
+	tests.TestUtil_WaitForGroupRefresh(t, user.Token, group.ID)
+	status, _ = send(fiber.MethodGet, "/places/search?lat=12.9716&lng=77.5946&radius=1500&query=coffee", user.Token, nil)
+	require.Equal(t, fiber.StatusOK, status)
+
+	t.Run("non admin is forbidden", func(t *testing.T) {
+		status, _ := send(fiber.MethodGet, "/admin/places-usage", user.Token, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+	})
+
+	t.Run("calls are counted for the group and user", func(t *testing.T) {
+		usage := getUsage("")
+		assert.True(t, usage.Metered)
+		assert.Equal(t, time.Now().UTC().Format(time.DateOnly), usage.Day)
+		assert.False(t, usage.BreakerOpen)
+		assert.Positive(t, usage.Operations[config.PlacesOperationNearbySearch])
+		assert.Positive(t, usage.Operations[config.PlacesOperationTextSearch])
+		assert.Equal(t, usage.Calls, lo.Sum(lo.Values(usage.Operations)))
+
+		groupUsage, ok := lo.Find(usage.Groups, func(count dto.PlacesUsageCount) bool { return count.GroupID == group.ID })
+		assert.True(t, ok)
+		assert.Positive(t, groupUsage.Calls)
+		userUsage, ok := lo.Find(usage.Users, func(count dto.PlacesUsageCount) bool { return count.UserID == user.ID })
+		assert.True(t, ok)
+		assert.Equal(t, int64(1), userUsage.Calls)
+	})
+
+	t.Run("other days", func(t *testing.T) {
+		usage := getUsage("?day=2000-01-01")
+		assert.Zero(t, usage.Calls)
+		assert.Empty(t, usage.Groups)
+
+		status, _ := send(fiber.MethodGet, "/admin/places-usage?day=yesterday", admin.Token, nil)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+}
@@ -0,0 +1,105 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupClusters(t *testing.T) {
+	creator := tests.TestUtil_CreateUser(t, "testuser5401@test.com", "testpassword5401")
+	group := tests.TestUtil_CreateGroup(t, creator.Token, "Test Group 5401")
+	assert.Equal(t, config.ClusterMethodNone, group.ClusterMethod)
+
+	locations := []dto.Location{
+		{Latitude: 51.5450, Longitude: -0.2050},
+		{Latitude: 51.4620, Longitude: -0.0100},
+		{Latitude: 51.5470, Longitude: -0.2000},
+		{Latitude: 51.4600, Longitude: -0.0150},
+	}
+	members := make([]*dto.UserResponse, len(locations))
+	for i, location := range locations {
+		members[i] = tests.TestUtil_CreateUser(t, fmt.Sprintf("testuser54%d1@test.com", i+1), "testpassword5401")
+		// cluster midpoints are only shown exactly for exact locations
+		body := lo.Must(json.Marshal(dto.GroupUserJoinRequest{Location: location, LocationPrivacy: config.LocationPrivacyExact}))
+		req := httptest.NewRequest(fiber.MethodPut, "/v1/groups/"+group.ID+"/join", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+members[i].Token)
+		assert.Equal(t, fiber.StatusAccepted, lo.Must(tests.App.Test(req, -1)).StatusCode)
+	}
+
+	updateGroup := func(token string, body string) int {
+		req := httptest.NewRequest(fiber.MethodPatch, "/v1/groups/"+group.ID, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return lo.Must(tests.App.Test(req, -1)).StatusCode
+	}
+	getGroup := func(t *testing.T) dto.GroupResponse {
+		tests.TestUtil_WaitForGroupRefresh(t, creator.Token, group.ID)
+		req := httptest.NewRequest(fiber.MethodGet, "/v1/groups/"+group.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+creator.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var groupResp dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &groupResp))
+		return groupResp
+	}
+
+	t.Run("group is not clustered by default", func(t *testing.T) {
+		assert.Empty(t, getGroup(t).Clusters)
+	})
+
+	t.Run("non admin cannot change clustering", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusForbidden, updateGroup(members[0].Token, `{"cluster_method": "kmeans", "cluster_count": 2}`))
+	})
+
+	t.Run("kmeans needs a cluster count", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusUnprocessableEntity, updateGroup(creator.Token, `{"cluster_method": "kmeans"}`))
+	})
+
+	t.Run("kmeans splits the group", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusAccepted, updateGroup(creator.Token, `{"cluster_method": "kmeans", "cluster_count": 2}`))
+		groupResp := getGroup(t)
+		assert.Equal(t, config.ClusterMethodKMeans, groupResp.ClusterMethod)
+		assert.Len(t, groupResp.Clusters, 2)
+		assert.ElementsMatch(t, []uint{members[0].ID, members[2].ID}, groupResp.Clusters[0].MemberIDs)
+		assert.ElementsMatch(t, []uint{members[1].ID, members[3].ID}, groupResp.Clusters[1].MemberIDs)
+		assert.InDelta(t, 51.546, groupResp.Clusters[0].MidpointLatitude, 0.001)
+		assert.InDelta(t, 51.461, groupResp.Clusters[1].MidpointLatitude, 0.001)
+		for _, cluster := range groupResp.Clusters {
+			assert.Equal(t, groupResp.Radius, cluster.PlacesRadius)
+		}
+	})
+
+	t.Run("dbscan with a large radius keeps everyone together", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusAccepted, updateGroup(creator.Token, `{"cluster_method": "dbscan", "cluster_radius": 50000}`))
+		groupResp := getGroup(t)
+		assert.Len(t, groupResp.Clusters, 1)
+		assert.Len(t, groupResp.Clusters[0].MemberIDs, len(members))
+	})
+
+	t.Run("dbscan leaves members with nobody nearby out of the clusters", func(t *testing.T) {
+		// the members are ~400m from their nearest neighbour
+		assert.Equal(t, fiber.StatusAccepted, updateGroup(creator.Token, `{"cluster_method": "dbscan", "cluster_radius": 100}`))
+		assert.Empty(t, getGroup(t).Clusters)
+
+		assert.Equal(t, fiber.StatusAccepted, updateGroup(creator.Token, `{"cluster_method": "dbscan", "cluster_radius": 1000}`))
+		groupResp := getGroup(t)
+		assert.Len(t, groupResp.Clusters, 2)
+	})
+
+	t.Run("clustering can be turned off", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusAccepted, updateGroup(creator.Token, `{"cluster_method": "none"}`))
+		assert.Empty(t, getGroup(t).Clusters)
+	})
+}
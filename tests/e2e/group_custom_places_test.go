@@ -0,0 +1,103 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupCustomPlaces(t *testing.T) {
+	admin := tests.TestUtil_CreateUser(t, "testuser6201@test.com", "testpassword6201")
+	member1 := tests.TestUtil_CreateUser(t, "testuser6202@test.com", "testpassword6202")
+	member2 := tests.TestUtil_CreateUser(t, "testuser6203@test.com", "testpassword6203")
+	outsider := tests.TestUtil_CreateUser(t, "testuser6204@test.com", "testpassword6204")
+	group := tests.TestUtil_CreateGroup(t, admin.Token, "Test Group 6201")
+
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1/groups/"+group.ID+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	join := func(token string, location dto.Location) {
+		status, _ := send(fiber.MethodPut, "/join", token, dto.GroupUserJoinRequest{Location: location})
+		assert.Equal(t, fiber.StatusAccepted, status)
+	}
+	listPlaces := func() []dto.GroupPlaceResponse {
+		status, body := send(fiber.MethodGet, "/places", admin.Token, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		var places []dto.GroupPlaceResponse
+		assert.NoError(t, json.Unmarshal(body, &places))
+		return places
+	}
+
+	join(admin.Token, dto.Location{Latitude: 48.8566, Longitude: 2.3522})
+	join(member1.Token, dto.Location{Latitude: 48.8606, Longitude: 2.3376})
+	join(member2.Token, dto.Location{Latitude: 48.8530, Longitude: 2.3499})
+	tests.TestUtil_WaitForGroupRefresh(t, admin.Token, group.ID)
+
+	flat := dto.CustomPlaceAddRequest{
+		Name:     "Sam's flat",
+		Address:  "2 Rue de Rivoli",
+		Type:     config.PlaceTypeCafe,
+		Location: dto.Location{Latitude: 48.8556, Longitude: 2.3600},
+	}
+	var custom dto.GroupPlaceResponse
+
+	t.Run("members add custom places", func(t *testing.T) {
+		status, body := send(fiber.MethodPost, "/places", member1.Token, flat)
+		assert.Equal(t, fiber.StatusCreated, status)
+		assert.NoError(t, json.Unmarshal(body, &custom))
+		assert.Equal(t, config.PlaceSourceCustom, custom.Source)
+		assert.Equal(t, member1.ID, custom.AddedBy)
+		assert.Equal(t, "Sam's flat", custom.Name)
+
+		places := listPlaces()
+		listed, found := lo.Find(places, func(place dto.GroupPlaceResponse) bool { return place.PlaceID == custom.PlaceID })
+		assert.True(t, found)
+		assert.Equal(t, config.PlaceSourceCustom, listed.Source)
+		assert.True(t, lo.SomeBy(places, func(place dto.GroupPlaceResponse) bool { return place.Source == config.PlaceSourceProvider }))
+	})
+
+	t.Run("invalid custom places are rejected", func(t *testing.T) {
+		status, _ := send(fiber.MethodPost, "/places", outsider.Token, flat)
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = send(fiber.MethodPost, "/places", member1.Token, dto.CustomPlaceAddRequest{Name: "Nowhere"})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("custom places are kept when the group is refreshed", func(t *testing.T) {
+		join(member2.Token, dto.Location{Latitude: 48.8049, Longitude: 2.1204})
+		tests.TestUtil_WaitForGroupRefresh(t, admin.Token, group.ID)
+
+		assert.True(t, lo.SomeBy(listPlaces(), func(place dto.GroupPlaceResponse) bool { return place.PlaceID == custom.PlaceID }))
+	})
+
+	t.Run("only the member who added it or admins remove a custom place", func(t *testing.T) {
+		path := "/places/" + url.PathEscape(custom.PlaceID)
+		status, _ := send(fiber.MethodDelete, path, member2.Token, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = send(fiber.MethodDelete, path, admin.Token, nil)
+		assert.Equal(t, fiber.StatusNoContent, status)
+		status, _ = send(fiber.MethodDelete, path, admin.Token, nil)
+		assert.Equal(t, fiber.StatusNotFound, status)
+
+		provider := listPlaces()[0]
+		assert.Equal(t, config.PlaceSourceProvider, provider.Source)
+		status, _ = send(fiber.MethodDelete, "/places/"+url.PathEscape(provider.PlaceID), admin.Token, nil)
+		assert.Equal(t, fiber.StatusNotFound, status)
+
+		assert.False(t, lo.SomeBy(listPlaces(), func(place dto.GroupPlaceResponse) bool { return place.PlaceID == custom.PlaceID }))
+	})
+}
@@ -0,0 +1,254 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/src/utils/geo"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupLocationPrivacy(t *testing.T) {
+	user1 := tests.TestUtil_CreateUser(t, "testuser7201@test.com", "testpassword7201")
+	user2 := tests.TestUtil_CreateUser(t, "testuser7202@test.com", "testpassword7202")
+	group := tests.TestUtil_CreateGroup(t, user1.Token, "Test Group 7201")
+	home1 := dto.Location{Latitude: 51.5074, Longitude: -0.1278}
+	home2 := dto.Location{Latitude: 51.5300, Longitude: -0.1000}
+
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1"+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	getMember := func(token string, userID uint) dto.GroupUserResponse {
+		status, body := send(fiber.MethodGet, "/groups/"+group.ID+"?includeUsers=true", token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var groupResp dto.GroupResponse
+		require.NoError(t, json.Unmarshal(body, &groupResp))
+		member, found := lo.Find(groupResp.Members, func(member dto.GroupUserResponse) bool { return member.UserID == userID })
+		require.True(t, found)
+		return member
+	}
+
+	t.Run("invalid location privacy is rejected", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, "/groups/"+group.ID+"/join", user1.Token, dto.GroupUserJoinRequest{
+			Location:        home1,
+			LocationPrivacy: "secret",
+		})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("locations are approximate by default", func(t *testing.T) {
+		status, body := send(fiber.MethodPut, "/groups/"+group.ID+"/join", user1.Token, dto.GroupUserJoinRequest{Location: home1})
+		require.Equal(t, fiber.StatusAccepted, status)
+		var groupUserResp dto.GroupUserResponse
+		require.NoError(t, json.Unmarshal(body, &groupUserResp))
+		assert.Equal(t, config.LocationPrivacyApproximate, groupUserResp.LocationPrivacy)
+		// members see their own location exactly
+		assert.Equal(t, home1.Latitude, groupUserResp.Latitude)
+
+		status, _ = send(fiber.MethodPut, "/groups/"+group.ID+"/join", user2.Token, dto.GroupUserJoinRequest{
+			Location:        home2,
+			LocationPrivacy: config.LocationPrivacyHidden,
+		})
+		require.Equal(t, fiber.StatusAccepted, status)
+		tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+
+		own := getMember(user1.Token, user1.ID)
+		assert.Equal(t, home1, dto.Location{Latitude: own.Latitude, Longitude: own.Longitude})
+
+		seen := getMember(user2.Token, user1.ID)
+		seenLocation := dto.Location{Latitude: seen.Latitude, Longitude: seen.Longitude}
+		assert.NotEqual(t, home1, seenLocation)
+		assert.LessOrEqual(t, geo.DistanceKm(home1, seenLocation), 0.36)
+	})
+
+	t.Run("hidden locations are 0, 0", func(t *testing.T) {
+		hidden := getMember(user1.Token, user2.ID)
+		assert.Zero(t, hidden.Latitude)
+		assert.Zero(t, hidden.Longitude)
+		assert.Equal(t, config.LocationPrivacyHidden, hidden.LocationPrivacy)
+	})
+
+	getGroup := func(token string) dto.GroupResponse {
+		status, body := send(fiber.MethodGet, "/groups/"+group.ID, token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var groupResp dto.GroupResponse
+		require.NoError(t, json.Unmarshal(body, &groupResp))
+		return groupResp
+	}
+
+	t.Run("midpoint is shown like the members' locations", func(t *testing.T) {
+		// with a hidden member, it is hidden too
+		groupResp := getGroup(user1.Token)
+		assert.Zero(t, groupResp.MidpointLatitude)
+		assert.Zero(t, groupResp.MidpointLongitude)
+		assert.Empty(t, groupResp.MidpointLabel)
+
+		// and so is that of the places history
+		status, body := send(fiber.MethodGet, "/groups/"+group.ID+"/places/history", user1.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var snapshots []dto.GroupPlacesSnapshotResponse
+		require.NoError(t, json.Unmarshal(body, &snapshots))
+		require.NotEmpty(t, snapshots)
+		assert.Zero(t, snapshots[0].MidpointLatitude)
+		assert.Zero(t, snapshots[0].MidpointLongitude)
+	})
+
+	getReportMember := func(token string, userID uint) (dto.MidpointReportResponse, dto.MemberMidpointReport) {
+		status, body := send(fiber.MethodGet, "/groups/"+group.ID+"/midpoint/report", token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var report dto.MidpointReportResponse
+		require.NoError(t, json.Unmarshal(body, &report))
+		member, found := lo.Find(report.Members, func(member dto.MemberMidpointReport) bool { return member.UserID == userID })
+		require.True(t, found)
+		return report, member
+	}
+
+	t.Run("midpoint report rounds other members' distances", func(t *testing.T) {
+		_, approximate := getReportMember(user2.Token, user1.ID)
+		require.NotNil(t, approximate.DistanceKm)
+		assert.InDelta(t, 0, math.Remainder(*approximate.DistanceKm, 0.5), 0.0001)
+
+		report, _ := getReportMember(user1.Token, user1.ID)
+		require.NotEmpty(t, report.Places)
+		own, found := lo.Find(report.Places[0].Members, func(member dto.MemberMidpointReport) bool { return member.UserID == user1.ID })
+		require.True(t, found)
+		require.NotNil(t, own.DistanceKm)
+		assert.InDelta(t, geo.DistanceKm(home1, report.Places[0].Location), *own.DistanceKm, 0.0001)
+
+		// fairness is measured over the rounded distances too, the other one being the hidden member's
+		hiddenDistanceKm := 2*report.Places[0].Fairness.MeanKm - *own.DistanceKm
+		assert.InDelta(t, 0, math.Remainder(hiddenDistanceKm, 0.5), 0.0001)
+	})
+
+	t.Run("midpoint report shows midpoints like the group midpoint", func(t *testing.T) {
+		report, _ := getReportMember(user1.Token, user1.ID)
+		assert.Equal(t, dto.Location{}, report.Midpoint)
+		require.NotEmpty(t, report.Strategies)
+		for _, strategy := range report.Strategies {
+			assert.Equal(t, dto.Location{}, strategy.Midpoint)
+		}
+	})
+
+	t.Run("midpoint report leaves out hidden members' distances", func(t *testing.T) {
+		_, hidden := getReportMember(user1.Token, user2.ID)
+		assert.Nil(t, hidden.DistanceKm)
+		assert.Nil(t, hidden.Minutes)
+
+		// members still see their own
+		_, own := getReportMember(user2.Token, user2.ID)
+		assert.NotNil(t, own.DistanceKm)
+		assert.NotNil(t, own.Minutes)
+	})
+
+	t.Run("place ETAs of hidden members only reach themselves", func(t *testing.T) {
+		getPlaces := func(token string) []dto.GroupPlaceResponse {
+			status, body := send(fiber.MethodGet, "/groups/"+group.ID+"?includePlaces=true", token, nil)
+			require.Equal(t, fiber.StatusOK, status)
+			var groupResp dto.GroupResponse
+			require.NoError(t, json.Unmarshal(body, &groupResp))
+			require.NotEmpty(t, groupResp.Places)
+			return groupResp.Places
+		}
+		etaOf := func(place dto.GroupPlaceResponse, userID uint) (dto.MemberETA, bool) {
+			return lo.Find(place.MemberETAs, func(eta dto.MemberETA) bool { return eta.UserID == userID })
+		}
+
+		for _, place := range getPlaces(user1.Token) {
+			_, found := etaOf(place, user2.ID)
+			assert.False(t, found)
+			own, found := etaOf(place, user1.ID)
+			require.True(t, found)
+			require.NotNil(t, own.Minutes)
+		}
+		for _, place := range getPlaces(user2.Token) {
+			own, found := etaOf(place, user2.ID)
+			require.True(t, found)
+			assert.NotNil(t, own.Minutes)
+			// the approximate member's ETAs are rounded to 5 minutes
+			approximate, found := etaOf(place, user1.ID)
+			require.True(t, found)
+			require.NotNil(t, approximate.Minutes)
+			assert.InDelta(t, 0, math.Remainder(*approximate.Minutes, 5), 0.0001)
+		}
+	})
+
+	t.Run("cluster midpoints are shown like their members' locations", func(t *testing.T) {
+		status, _ := send(fiber.MethodPatch, "/groups/"+group.ID, user1.Token, dto.UpdateGroupRequest{
+			ClusterMethod: config.ClusterMethodKMeans,
+			ClusterCount:  2,
+		})
+		require.Equal(t, fiber.StatusAccepted, status)
+		tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+
+		status, body := send(fiber.MethodGet, "/groups/"+group.ID, user1.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var groupResp dto.GroupResponse
+		require.NoError(t, json.Unmarshal(body, &groupResp))
+		// each member is in a cluster of their own, whose midpoint is their location
+		require.Len(t, groupResp.Clusters, 2)
+		for _, cluster := range groupResp.Clusters {
+			require.Len(t, cluster.MemberIDs, 1)
+			midpoint := dto.Location{Latitude: cluster.MidpointLatitude, Longitude: cluster.MidpointLongitude}
+			if cluster.MemberIDs[0] == user2.ID {
+				assert.Equal(t, dto.Location{}, midpoint)
+			} else {
+				assert.NotEqual(t, home1, midpoint)
+				assert.LessOrEqual(t, geo.DistanceKm(home1, midpoint), 0.36)
+			}
+		}
+
+		status, body = send(fiber.MethodGet, "/groups/"+group.ID+"/places/history", user1.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var snapshots []dto.GroupPlacesSnapshotResponse
+		require.NoError(t, json.Unmarshal(body, &snapshots))
+		require.NotEmpty(t, snapshots)
+		require.Len(t, snapshots[0].Clusters, 2)
+		for _, cluster := range snapshots[0].Clusters {
+			midpoint := dto.Location{Latitude: cluster.MidpointLatitude, Longitude: cluster.MidpointLongitude}
+			assert.NotEqual(t, home1, midpoint)
+			assert.NotEqual(t, home2, midpoint)
+		}
+	})
+
+	t.Run("members can share their exact location", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, "/groups/"+group.ID+"/join", user2.Token, dto.GroupUserJoinRequest{
+			Location:        home2,
+			LocationPrivacy: config.LocationPrivacyExact,
+		})
+		require.Equal(t, fiber.StatusAccepted, status)
+
+		exact := getMember(user1.Token, user2.ID)
+		assert.Equal(t, home2, dto.Location{Latitude: exact.Latitude, Longitude: exact.Longitude})
+
+		// joining again without a privacy keeps it
+		status, _ = send(fiber.MethodPut, "/groups/"+group.ID+"/join", user2.Token, dto.GroupUserJoinRequest{Location: home2})
+		require.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, config.LocationPrivacyExact, getMember(user1.Token, user2.ID).LocationPrivacy)
+	})
+
+	t.Run("midpoint of exact and approximate locations is approximate", func(t *testing.T) {
+		status, _ := send(fiber.MethodPatch, "/groups/"+group.ID, user1.Token, dto.UpdateGroupRequest{ClusterMethod: config.ClusterMethodNone})
+		require.Equal(t, fiber.StatusAccepted, status)
+		tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+
+		// the midpoint is still found from the exact locations, and snapped to the grid like them
+		groupResp := getGroup(user2.Token)
+		midpoint := dto.Location{Latitude: groupResp.MidpointLatitude, Longitude: groupResp.MidpointLongitude}
+		exact := dto.Location{Latitude: (home1.Latitude + home2.Latitude) / 2, Longitude: (home1.Longitude + home2.Longitude) / 2}
+		assert.Equal(t, geo.SnapToGrid(exact, config.ApproximateLocationGridSize), midpoint)
+	})
+}
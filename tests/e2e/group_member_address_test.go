@@ -0,0 +1,91 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addresses are geocoded with the gazetteer in tests/testdata/gazetteer.txt
+func TestGroupMemberAddress(t *testing.T) {
+	user1 := tests.TestUtil_CreateUser(t, "testuser7001@test.com", "testpassword7001")
+	user2 := tests.TestUtil_CreateUser(t, "testuser7002@test.com", "testpassword7002")
+	group := tests.TestUtil_CreateGroup(t, user1.Token, "Test Group 7001")
+
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1"+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	getGroup := func() dto.GroupResponse {
+		status, body := send(fiber.MethodGet, "/groups/"+group.ID, user1.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var groupResp dto.GroupResponse
+		require.NoError(t, json.Unmarshal(body, &groupResp))
+		return groupResp
+	}
+
+	t.Run("unknown address is rejected", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, "/groups/"+group.ID+"/join", user1.Token, dto.GroupUserJoinRequest{Address: "Atlantis"})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+
+		status, _ = send(fiber.MethodPut, "/groups/"+group.ID+"/join", user1.Token, dto.GroupUserJoinRequest{
+			Address: strings.Repeat("a", config.MaxAddressLength+1),
+		})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("join with an address", func(t *testing.T) {
+		// the midpoint and its label are only shown for exact locations
+		status, body := send(fiber.MethodPut, "/groups/"+group.ID+"/join", user1.Token, dto.GroupUserJoinRequest{
+			Address:         "Soho, London",
+			LocationPrivacy: config.LocationPrivacyExact,
+		})
+		require.Equal(t, fiber.StatusAccepted, status)
+		var groupUserResp dto.GroupUserResponse
+		require.NoError(t, json.Unmarshal(body, &groupUserResp))
+		assert.InDelta(t, 51.51357, groupUserResp.Latitude, 0.00001)
+		assert.InDelta(t, -0.13680, groupUserResp.Longitude, 0.00001)
+	})
+
+	t.Run("midpoint is labelled with its neighbourhood", func(t *testing.T) {
+		tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+		assert.Equal(t, "Soho, London", getGroup().MidpointLabel)
+
+		// members can still join with a location
+		status, _ := send(fiber.MethodPut, "/groups/"+group.ID+"/join", user2.Token, dto.GroupUserJoinRequest{
+			Location:        dto.Location{Latitude: 51.5500, Longitude: -0.1450},
+			LocationPrivacy: config.LocationPrivacyExact,
+		})
+		require.Equal(t, fiber.StatusAccepted, status)
+		tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+		groupResp := getGroup()
+		assert.InDelta(t, (51.51357+51.5500)/2, groupResp.MidpointLatitude, 0.0001)
+		assert.Equal(t, "Camden Town, London", groupResp.MidpointLabel)
+	})
+
+	t.Run("update user location with an address", func(t *testing.T) {
+		status, body := send(fiber.MethodPost, "/users/"+strconv.Itoa(int(user2.ID)), user2.Token, dto.UserUpdateRequest{Address: "Le Marais"})
+		require.Equal(t, fiber.StatusAccepted, status)
+		var userResp dto.UserResponse
+		require.NoError(t, json.Unmarshal(body, &userResp))
+		assert.InDelta(t, 48.85900, userResp.Location.Latitude, 0.00001)
+
+		status, _ = send(fiber.MethodPost, "/users/"+strconv.Itoa(int(user2.ID)), user2.Token, dto.UserUpdateRequest{Address: "Atlantis"})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+}
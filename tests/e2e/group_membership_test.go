@@ -115,7 +115,7 @@ func TestGroupsRoute_AddUsersToGroupAndVerify(t *testing.T) {
 	resp := lo.Must(tests.App.Test(req, -1))
 	assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
 
-	time.Sleep(20 * time.Millisecond)
+	tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
 
 	// Verify that user2 is included in the group members
 	req = httptest.NewRequest("GET", "/v1/groups/"+group.ID+"?includeUsers=true", nil)
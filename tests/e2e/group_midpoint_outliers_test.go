@@ -0,0 +1,100 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMidpointOutliers(t *testing.T) {
+	creator := tests.TestUtil_CreateUser(t, "testuser5101@test.com", "testpassword5101")
+	group := tests.TestUtil_CreateGroup(t, creator.Token, "Test Group 5101")
+
+	locations := []dto.Location{
+		{Latitude: 51.5072, Longitude: -0.1276},
+		{Latitude: 51.5101, Longitude: -0.1340},
+		{Latitude: 51.5033, Longitude: -0.1195},
+		{Latitude: 51.5155, Longitude: -0.1419},
+		{Latitude: 51.7520, Longitude: -1.2577}, // joins from Oxford
+	}
+	members := make([]*dto.UserResponse, len(locations))
+	for i, location := range locations {
+		members[i] = tests.TestUtil_CreateUser(t, fmt.Sprintf("testuser51%d1@test.com", i+1), "testpassword5101")
+		// the midpoint is only shown exactly for exact locations
+		body := lo.Must(json.Marshal(dto.GroupUserJoinRequest{Location: location, LocationPrivacy: config.LocationPrivacyExact}))
+		req := httptest.NewRequest(fiber.MethodPut, "/v1/groups/"+group.ID+"/join", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+members[i].Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+	}
+	outlier := members[len(members)-1]
+
+	getGroup := func(t *testing.T) dto.GroupResponse {
+		tests.TestUtil_WaitForGroupRefresh(t, creator.Token, group.ID)
+		req := httptest.NewRequest(fiber.MethodGet, "/v1/groups/"+group.ID+"?includeUsers=true", nil)
+		req.Header.Set("Authorization", "Bearer "+creator.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var groupResp dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &groupResp))
+		return groupResp
+	}
+	findMember := func(groupResp dto.GroupResponse, userID uint) dto.GroupUserResponse {
+		member, _ := lo.Find(groupResp.Members, func(m dto.GroupUserResponse) bool { return m.UserID == userID })
+		return member
+	}
+	updateMember := func(token string, userID uint, inclusion config.MidpointInclusion) int {
+		body := lo.Must(json.Marshal(dto.GroupMemberUpdateRequest{MidpointInclusion: inclusion}))
+		req := httptest.NewRequest(fiber.MethodPatch, fmt.Sprintf("/v1/groups/%s/members/%d", group.ID, userID), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return lo.Must(tests.App.Test(req, -1)).StatusCode
+	}
+
+	t.Run("far away member is detected as outlier", func(t *testing.T) {
+		groupResp := getGroup(t)
+		member := findMember(groupResp, outlier.ID)
+		assert.True(t, member.ExcludedFromMidpoint)
+		assert.Equal(t, config.MidpointExclusionDistanceOutlier, member.ExclusionReason)
+		assert.False(t, findMember(groupResp, members[0].ID).ExcludedFromMidpoint)
+		assert.InDelta(t, 51.509, groupResp.MidpointLatitude, 0.01)
+		assert.InDelta(t, -0.130, groupResp.MidpointLongitude, 0.01)
+	})
+
+	t.Run("non admin cannot update members", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusForbidden, updateMember(members[0].Token, outlier.ID, config.MidpointInclusionIncluded))
+	})
+
+	t.Run("invalid inclusion is rejected", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusUnprocessableEntity, updateMember(creator.Token, outlier.ID, "sometimes"))
+	})
+
+	t.Run("admin can force include the outlier", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusAccepted, updateMember(creator.Token, outlier.ID, config.MidpointInclusionIncluded))
+		groupResp := getGroup(t)
+		member := findMember(groupResp, outlier.ID)
+		assert.False(t, member.ExcludedFromMidpoint)
+		assert.Equal(t, config.MidpointInclusionIncluded, member.MidpointInclusion)
+		assert.InDelta(t, 51.5576, groupResp.MidpointLatitude, 0.001)
+	})
+
+	t.Run("admin can exclude a member", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusAccepted, updateMember(creator.Token, members[0].ID, config.MidpointInclusionExcluded))
+		groupResp := getGroup(t)
+		member := findMember(groupResp, members[0].ID)
+		assert.True(t, member.ExcludedFromMidpoint)
+		assert.Equal(t, config.MidpointExclusionByAdmin, member.ExclusionReason)
+		assert.Len(t, groupResp.Members, len(members))
+	})
+}
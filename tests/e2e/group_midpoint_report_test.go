@@ -0,0 +1,71 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupMidpointReport(t *testing.T) {
+	user1 := tests.TestUtil_CreateUser(t, "testuser5601@test.com", "testpassword5601")
+	user2 := tests.TestUtil_CreateUser(t, "testuser5602@test.com", "testpassword5602")
+	group := tests.TestUtil_CreateGroup(t, user1.Token, "Test Group 5601")
+
+	for i, token := range []string{user1.Token, user2.Token} {
+		body := lo.Must(json.Marshal(dto.GroupUserJoinRequest{
+			Location:   dto.Location{Latitude: 51.50, Longitude: -0.20 + float64(i)*0.10},
+			TravelMode: config.TravelModeWalk,
+			// other members' distances are only reported exactly for exact locations
+			LocationPrivacy: config.LocationPrivacyExact,
+		}))
+		req := httptest.NewRequest(fiber.MethodPut, "/v1/groups/"+group.ID+"/join", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		assert.Equal(t, fiber.StatusAccepted, lo.Must(tests.App.Test(req, -1)).StatusCode)
+	}
+	tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/v1/groups/"+group.Code+"/midpoint/report", nil)
+	req.Header.Set("Authorization", "Bearer "+user2.Token)
+	resp := lo.Must(tests.App.Test(req, -1))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var report dto.MidpointReportResponse
+	assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &report))
+	assert.Equal(t, group.ID, report.GroupID)
+	assert.Equal(t, config.MidpointStrategyCentroid, report.Strategy)
+	assert.InDelta(t, -0.15, report.Midpoint.Longitude, 0.0001)
+
+	// both members are ~3.5km from the centroid
+	assert.Len(t, report.Members, 2)
+	for _, member := range report.Members {
+		require.NotNil(t, member.DistanceKm)
+		assert.InDelta(t, 3.47, *member.DistanceKm, 0.01)
+		assert.Equal(t, config.TravelModeWalk, member.TravelMode)
+		assert.NotNil(t, member.Minutes)
+	}
+	assert.InDelta(t, 3.47, report.Fairness.MaxKm, 0.01)
+	assert.InDelta(t, 0, report.Fairness.Gini, 0.001)
+
+	assert.Len(t, report.Strategies, len(config.SupportedMidpointStrategies()))
+	for _, strategy := range report.Strategies {
+		assert.Equal(t, strategy.Strategy == config.MidpointStrategyCentroid, strategy.Current)
+		assert.InDelta(t, 3.47, strategy.Fairness.MeanKm, 0.05)
+	}
+
+	t.Run("unknown group is not found", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/v1/groups/0000000000/midpoint/report", nil)
+		req.Header.Set("Authorization", "Bearer "+user1.Token)
+		assert.Equal(t, fiber.StatusNotFound, lo.Must(tests.App.Test(req, -1)).StatusCode)
+	})
+}
@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMidpointStrategy(t *testing.T) {
+	user1 := tests.TestUtil_CreateUser(t, "testuser5201@test.com", "testpassword5201")
+	user2 := tests.TestUtil_CreateUser(t, "testuser5202@test.com", "testpassword5202")
+	group := tests.TestUtil_CreateGroup(t, user1.Token, "Test Group 5201")
+	assert.Equal(t, config.MidpointStrategyCentroid, group.MidpointStrategy)
+
+	updateGroup := func(body string) *dto.GroupResponse {
+		req := httptest.NewRequest(fiber.MethodPatch, "/v1/groups/"+group.ID, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+user1.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		if resp.StatusCode != fiber.StatusAccepted {
+			assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+			return nil
+		}
+		var groupResp dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &groupResp))
+		return &groupResp
+	}
+
+	t.Run("invalid strategy is rejected", func(t *testing.T) {
+		assert.Nil(t, updateGroup(`{"midpoint_strategy": "closest_to_me"}`))
+	})
+
+	t.Run("strategy can be changed", func(t *testing.T) {
+		groupResp := updateGroup(`{"midpoint_strategy": "min_max_time"}`)
+		assert.NotNil(t, groupResp)
+		assert.Equal(t, config.MidpointStrategyMinMaxTime, groupResp.MidpointStrategy)
+	})
+
+	t.Run("midpoint minimises the longest travel time", func(t *testing.T) {
+		for i, token := range []string{user1.Token, user2.Token} {
+			body := lo.Must(json.Marshal(dto.GroupUserJoinRequest{Location: dto.Location{
+				Latitude:  51.50,
+				Longitude: -0.20 + float64(i)*0.10,
+			}, LocationPrivacy: config.LocationPrivacyExact}))
+			req := httptest.NewRequest(fiber.MethodPut, "/v1/groups/"+group.ID+"/join", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			assert.Equal(t, fiber.StatusAccepted, lo.Must(tests.App.Test(req, -1)).StatusCode)
+		}
+		tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+
+		req := httptest.NewRequest(fiber.MethodGet, "/v1/groups/"+group.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+user1.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		var groupResp dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &groupResp))
+		// with straight line travel times, halfway between the two is the fairest
+		assert.InDelta(t, 51.50, groupResp.MidpointLatitude, 0.001)
+		assert.InDelta(t, -0.15, groupResp.MidpointLongitude, 0.001)
+	})
+}
@@ -6,8 +6,8 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
-	"time"
 
+	"github.com/championswimmer/api.midpoint.place/src/config"
 	"github.com/championswimmer/api.midpoint.place/src/dto"
 	"github.com/championswimmer/api.midpoint.place/tests"
 	"github.com/gofiber/fiber/v2"
@@ -38,6 +38,8 @@ func TestGroupMidpointUpdate(t *testing.T) {
 					Latitude:  51.5051821,
 					Longitude: -0.2160895,
 				},
+				// the midpoint is only shown exactly for exact locations
+				LocationPrivacy: config.LocationPrivacyExact,
 			},
 			expectedStatus: fiber.StatusAccepted,
 			checkResponse: func(t *testing.T, body []byte) {
@@ -58,6 +60,7 @@ func TestGroupMidpointUpdate(t *testing.T) {
 					Latitude:  51.4974653,
 					Longitude: -0.1536909,
 				},
+				LocationPrivacy: config.LocationPrivacyExact,
 			},
 			expectedStatus: fiber.StatusAccepted,
 			checkResponse: func(t *testing.T, body []byte) {
@@ -85,8 +88,7 @@ func TestGroupMidpointUpdate(t *testing.T) {
 			resp := lo.Must(tests.App.Test(req, -1))
 			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
 
-			// sleep for 20ms
-			time.Sleep(20 * time.Millisecond)
+			tests.TestUtil_WaitForGroupRefresh(t, tc.userToken, tc.groupID)
 
 			// fetch group details to check new midpoint
 
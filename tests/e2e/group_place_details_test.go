@@ -0,0 +1,113 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupPlaceDetails(t *testing.T) {
+	admin := tests.TestUtil_CreateUser(t, "testuser6501@test.com", "testpassword6501")
+	outsider := tests.TestUtil_CreateUser(t, "testuser6502@test.com", "testpassword6502")
+	group := tests.TestUtil_CreateGroup(t, admin.Token, "Test Group 6501")
+
+	send := func(method string, path string, token string, body any) (*httptest.ResponseRecorder, []byte) {
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		recorder := httptest.NewRecorder()
+		recorder.Code = resp.StatusCode
+		for key, values := range resp.Header {
+			recorder.Header()[key] = values
+		}
+		return recorder, lo.Must(io.ReadAll(resp.Body))
+	}
+	groupPath := "/v1/groups/" + group.ID
+	getDetails := func(token string, placeID string) (int, dto.GroupPlaceDetailsResponse) {
+		resp, body := send(fiber.MethodGet, groupPath+"/places/"+url.PathEscape(placeID), token, nil)
+		var place dto.GroupPlaceDetailsResponse
+		if resp.Code == fiber.StatusOK {
+			assert.NoError(t, json.Unmarshal(body, &place))
+		}
+		return resp.Code, place
+	}
+
+	resp, _ := send(fiber.MethodPut, groupPath+"/join", admin.Token, dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}})
+	require.Equal(t, fiber.StatusAccepted, resp.Code)
+	tests.TestUtil_WaitForGroupRefresh(t, admin.Token, group.ID)
+
+	resp, body := send(fiber.MethodGet, groupPath+"/places", admin.Token, nil)
+	require.Equal(t, fiber.StatusOK, resp.Code)
+	var places []dto.GroupPlaceResponse
+	require.NoError(t, json.Unmarshal(body, &places))
+	require.NotEmpty(t, places)
+	place := places[0]
+
+	t.Run("members get details of a group place", func(t *testing.T) {
+		status, details := getDetails(admin.Token, place.PlaceID)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, place.PlaceID, details.PlaceID)
+		assert.Equal(t, place.Name, details.Name)
+		assert.NotEmpty(t, details.PhoneNumber)
+		assert.NotEmpty(t, details.Website)
+		assert.Len(t, details.OpeningHours, 7)
+		assert.NotEmpty(t, details.Photos)
+		for i, photo := range details.Photos {
+			assert.Equal(t, fmt.Sprintf("%s/places/%s/photos/%d", groupPath, url.PathEscape(place.PlaceID), i), photo.URL)
+		}
+
+		status, _ = getDetails(outsider.Token, place.PlaceID)
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = getDetails(admin.Token, "not-a-place")
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+
+	t.Run("photos are served through the API", func(t *testing.T) {
+		_, details := getDetails(admin.Token, place.PlaceID)
+		require.NotEmpty(t, details.Photos)
+
+		resp, body := send(fiber.MethodGet, details.Photos[0].URL+"?max_width=200", admin.Token, nil)
+		assert.Equal(t, fiber.StatusOK, resp.Code)
+		assert.Equal(t, "image/svg+xml", resp.Header().Get(fiber.HeaderContentType))
+		assert.NotEmpty(t, resp.Header().Get(fiber.HeaderCacheControl))
+		assert.Contains(t, string(body), `width="200"`)
+
+		resp, _ = send(fiber.MethodGet, details.Photos[0].URL, outsider.Token, nil)
+		assert.Equal(t, fiber.StatusForbidden, resp.Code)
+		resp, _ = send(fiber.MethodGet, details.Photos[0].URL+"?max_width=100000", admin.Token, nil)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.Code)
+		resp, _ = send(fiber.MethodGet, groupPath+"/places/"+url.PathEscape(place.PlaceID)+"/photos/99", admin.Token, nil)
+		assert.Equal(t, fiber.StatusNotFound, resp.Code)
+	})
+
+	t.Run("custom places have no details", func(t *testing.T) {
+		resp, body := send(fiber.MethodPost, groupPath+"/places", admin.Token, dto.CustomPlaceAddRequest{
+			Name:     "Our Flat",
+			Address:  "1 Rue de Rivoli",
+			Type:     config.PlaceTypeCafe,
+			Location: dto.Location{Latitude: 48.8556, Longitude: 2.3600},
+		})
+		require.Equal(t, fiber.StatusCreated, resp.Code)
+		var custom dto.GroupPlaceResponse
+		require.NoError(t, json.Unmarshal(body, &custom))
+
+		status, details := getDetails(admin.Token, custom.PlaceID)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "Our Flat", details.Name)
+		assert.Empty(t, details.PhoneNumber)
+		assert.Empty(t, details.Photos)
+	})
+}
@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupPlaceScores(t *testing.T) {
+	user1 := tests.TestUtil_CreateUser(t, "testuser6601@test.com", "testpassword6601")
+	user2 := tests.TestUtil_CreateUser(t, "testuser6602@test.com", "testpassword6602")
+	group := tests.TestUtil_CreateGroup(t, user1.Token, "Test Group 6601")
+
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1/groups/"+group.ID+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	listPlaces := func(query string) []dto.GroupPlaceResponse {
+		status, body := send(fiber.MethodGet, "/places"+query, user1.Token, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		var places []dto.GroupPlaceResponse
+		assert.NoError(t, json.Unmarshal(body, &places))
+		return places
+	}
+	scores := func(places []dto.GroupPlaceResponse) []float64 {
+		return lo.Map(places, func(place dto.GroupPlaceResponse, _ int) float64 { return place.Score.Score })
+	}
+
+	status, _ := send(fiber.MethodPut, "/join", user1.Token, dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 40.7128, Longitude: -74.0060}})
+	require.Equal(t, fiber.StatusAccepted, status)
+	status, _ = send(fiber.MethodPut, "/join", user2.Token, dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 40.7306, Longitude: -73.9866}})
+	require.Equal(t, fiber.StatusAccepted, status)
+	tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+
+	t.Run("places are sorted by score with a breakdown", func(t *testing.T) {
+		places := listPlaces("")
+		require.GreaterOrEqual(t, len(places), 2)
+		assert.True(t, sort.IsSorted(sort.Reverse(sort.Float64Slice(scores(places)))))
+		for _, place := range places {
+			require.NotNil(t, place.Score)
+			assert.Len(t, place.Score.Breakdown, len(config.PlaceScoreInputs))
+			assert.InDelta(t, place.Score.Score, lo.SumBy(place.Score.Breakdown, func(c dto.PlaceScoreComponent) float64 { return c.Points }), 0.01)
+			assert.Positive(t, place.ReviewCount)
+		}
+
+		// other sort orders still explain the score
+		for _, place := range listPlaces("?sort=travel_time") {
+			assert.NotNil(t, place.Score)
+		}
+	})
+
+	t.Run("votes raise a place's score", func(t *testing.T) {
+		places := listPlaces("")
+		last := places[len(places)-1]
+		voteInput := func(place dto.GroupPlaceResponse) dto.PlaceScoreComponent {
+			return lo.Must(lo.Find(place.Score.Breakdown, func(c dto.PlaceScoreComponent) bool { return c.Input == config.PlaceScoreInputVotes }))
+		}
+		assert.Equal(t, 0.0, voteInput(last).Points)
+
+		for _, token := range []string{user1.Token, user2.Token} {
+			status, _ := send(fiber.MethodPut, "/places/"+url.PathEscape(last.PlaceID)+"/vote", token, dto.GroupPlaceVoteRequest{Vote: config.PlaceVoteUp})
+			require.Equal(t, fiber.StatusOK, status)
+		}
+
+		voted := lo.Must(lo.Find(listPlaces(""), func(place dto.GroupPlaceResponse) bool { return place.PlaceID == last.PlaceID }))
+		assert.Equal(t, 2.0, voteInput(voted).Value)
+		assert.Equal(t, 1.0, voteInput(voted).Normalized)
+		assert.Greater(t, voted.Score.Score, last.Score.Score)
+	})
+}
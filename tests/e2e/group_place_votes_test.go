@@ -0,0 +1,114 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupPlaceVotes(t *testing.T) {
+	user1 := tests.TestUtil_CreateUser(t, "testuser6001@test.com", "testpassword6001")
+	user2 := tests.TestUtil_CreateUser(t, "testuser6002@test.com", "testpassword6002")
+	outsider := tests.TestUtil_CreateUser(t, "testuser6003@test.com", "testpassword6003")
+	group := tests.TestUtil_CreateGroup(t, user1.Token, "Test Group 6001")
+
+	join := func(token string) {
+		body := lo.Must(json.Marshal(dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}}))
+		req := httptest.NewRequest(fiber.MethodPut, "/v1/groups/"+group.ID+"/join", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		assert.Equal(t, fiber.StatusAccepted, lo.Must(tests.App.Test(req, -1)).StatusCode)
+	}
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1/groups/"+group.ID+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	listPlaces := func(sort string) []dto.GroupPlaceResponse {
+		status, body := send(fiber.MethodGet, "/places?sort="+sort, user1.Token, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		var places []dto.GroupPlaceResponse
+		assert.NoError(t, json.Unmarshal(body, &places))
+		return places
+	}
+	placeByID := func(places []dto.GroupPlaceResponse, placeID string) dto.GroupPlaceResponse {
+		place, found := lo.Find(places, func(place dto.GroupPlaceResponse) bool { return place.PlaceID == placeID })
+		assert.True(t, found)
+		return place
+	}
+
+	join(user1.Token)
+	join(user2.Token)
+	tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+
+	places := listPlaces("travel_time")
+	assert.GreaterOrEqual(t, len(places), 2)
+	placeA, placeB := places[0].PlaceID, places[1].PlaceID
+
+	t.Run("members vote and rank places", func(t *testing.T) {
+		status, body := send(fiber.MethodPut, "/places/"+url.PathEscape(placeA)+"/vote", user1.Token, dto.GroupPlaceVoteRequest{Vote: config.PlaceVoteUp})
+		assert.Equal(t, fiber.StatusOK, status)
+		var votes dto.GroupPlaceVotes
+		assert.NoError(t, json.Unmarshal(body, &votes))
+		assert.Equal(t, 1, votes.Upvotes)
+		assert.Equal(t, config.PlaceVoteUp, votes.MyVote)
+
+		status, _ = send(fiber.MethodPut, "/places/"+url.PathEscape(placeA)+"/vote", user2.Token, dto.GroupPlaceVoteRequest{Vote: config.PlaceVoteDown})
+		assert.Equal(t, fiber.StatusOK, status)
+		status, _ = send(fiber.MethodPut, "/places/ranking", user2.Token, dto.GroupPlaceRankingRequest{PlaceIDs: []string{placeB, placeA}})
+		assert.Equal(t, fiber.StatusOK, status)
+	})
+
+	t.Run("places sorted by votes", func(t *testing.T) {
+		places := listPlaces("votes")
+		assert.Equal(t, placeB, places[0].PlaceID)
+		assert.Equal(t, dto.GroupPlaceVotes{BordaPoints: 2, Score: 2}, *places[0].Votes)
+		assert.Equal(t, placeA, places[1].PlaceID)
+		assert.Equal(t, dto.GroupPlaceVotes{Upvotes: 1, Downvotes: 1, BordaPoints: 1, Score: 1, MyVote: config.PlaceVoteUp}, *places[1].Votes)
+	})
+
+	t.Run("invalid votes are rejected", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, "/places/"+url.PathEscape(placeA)+"/vote", outsider.Token, dto.GroupPlaceVoteRequest{Vote: config.PlaceVoteUp})
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = send(fiber.MethodPut, "/places/"+url.PathEscape(placeA)+"/vote", user1.Token, dto.GroupPlaceVoteRequest{Vote: "sideways"})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		status, _ = send(fiber.MethodPut, "/places/not-a-place/vote", user1.Token, dto.GroupPlaceVoteRequest{Vote: config.PlaceVoteUp})
+		assert.Equal(t, fiber.StatusNotFound, status)
+		status, _ = send(fiber.MethodPut, "/places/ranking", user1.Token, dto.GroupPlaceRankingRequest{PlaceIDs: []string{"not-a-place"}})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		status, _ = send(fiber.MethodGet, "/places?sort=name", user1.Token, nil)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("votes survive a refresh which finds the same places", func(t *testing.T) {
+		join(user1.Token)
+		tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+
+		places := listPlaces("votes")
+		assert.Equal(t, 1, placeByID(places, placeA).Votes.Upvotes)
+		assert.Equal(t, 2, placeByID(places, placeB).Votes.BordaPoints)
+	})
+
+	t.Run("votes can be taken back", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, "/places/"+url.PathEscape(placeA)+"/vote", user1.Token, dto.GroupPlaceVoteRequest{Vote: config.PlaceVoteNone})
+		assert.Equal(t, fiber.StatusOK, status)
+		status, _ = send(fiber.MethodPut, "/places/ranking", user2.Token, dto.GroupPlaceRankingRequest{PlaceIDs: []string{}})
+		assert.Equal(t, fiber.StatusOK, status)
+
+		places := listPlaces("votes")
+		assert.Equal(t, dto.GroupPlaceVotes{Downvotes: 1, Score: -1}, *placeByID(places, placeA).Votes)
+		assert.Equal(t, dto.GroupPlaceVotes{}, *placeByID(places, placeB).Votes)
+	})
+}
@@ -0,0 +1,116 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupPlacesHistory(t *testing.T) {
+	admin := tests.TestUtil_CreateUser(t, "testuser6901@test.com", "testpassword6901")
+	member := tests.TestUtil_CreateUser(t, "testuser6902@test.com", "testpassword6902")
+	group := tests.TestUtil_CreateGroup(t, admin.Token, "Test Group 6901")
+
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1/groups/"+group.ID+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	listHistory := func() []dto.GroupPlacesSnapshotResponse {
+		status, body := send(fiber.MethodGet, "/places/history", member.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var snapshots []dto.GroupPlacesSnapshotResponse
+		require.NoError(t, json.Unmarshal(body, &snapshots))
+		return snapshots
+	}
+	getSnapshot := func(id uint) dto.GroupPlacesSnapshotResponse {
+		status, body := send(fiber.MethodGet, fmt.Sprintf("/places/history/%d", id), member.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var snapshot dto.GroupPlacesSnapshotResponse
+		require.NoError(t, json.Unmarshal(body, &snapshot))
+		return snapshot
+	}
+	placeIDs := func(places []dto.GroupPlaceResponse) []string {
+		return lo.FilterMap(places, func(place dto.GroupPlaceResponse, _ int) (string, bool) {
+			return place.PlaceID, place.Source == config.PlaceSourceProvider
+		})
+	}
+
+	// the midpoint is only shown exactly for exact locations
+	status, _ := send(fiber.MethodPut, "/join", admin.Token, dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}, LocationPrivacy: config.LocationPrivacyExact})
+	require.Equal(t, fiber.StatusAccepted, status)
+	tests.TestUtil_WaitForGroupRefresh(t, admin.Token, group.ID)
+	status, _ = send(fiber.MethodPut, "/join", member.Token, dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 48.8800, Longitude: 2.3900}, LocationPrivacy: config.LocationPrivacyExact})
+	require.Equal(t, fiber.StatusAccepted, status)
+	tests.TestUtil_WaitForGroupRefresh(t, admin.Token, group.ID)
+
+	t.Run("each refresh saves a snapshot", func(t *testing.T) {
+		snapshots := listHistory()
+		require.Len(t, snapshots, 2)
+		latest, first := snapshots[0], snapshots[1]
+		assert.Greater(t, latest.ID, first.ID)
+		assert.InDelta(t, 48.8566, first.MidpointLatitude, 0.0001)
+		assert.InDelta(t, 48.8683, latest.MidpointLatitude, 0.0001)
+		assert.Equal(t, config.MidpointStrategyCentroid, first.MidpointStrategy)
+		assert.Positive(t, first.PlaceCount)
+		assert.Empty(t, first.Places)
+
+		snapshot := getSnapshot(first.ID)
+		assert.Len(t, snapshot.Places, snapshot.PlaceCount)
+	})
+
+	t.Run("unknown snapshot", func(t *testing.T) {
+		status, _ := send(fiber.MethodGet, "/places/history/999999", member.Token, nil)
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+
+	t.Run("only admins can restore", func(t *testing.T) {
+		first := listHistory()[1]
+		status, _ := send(fiber.MethodPost, fmt.Sprintf("/places/history/%d/restore", first.ID), member.Token, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+	})
+
+	t.Run("restore brings back the midpoint and places", func(t *testing.T) {
+		first := getSnapshot(listHistory()[1].ID)
+		status, body := send(fiber.MethodPost, fmt.Sprintf("/places/history/%d/restore", first.ID), admin.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+
+		var groupResp dto.GroupResponse
+		require.NoError(t, json.Unmarshal(body, &groupResp))
+		assert.InDelta(t, first.MidpointLatitude, groupResp.MidpointLatitude, 0.0001)
+		assert.InDelta(t, first.MidpointLongitude, groupResp.MidpointLongitude, 0.0001)
+		assert.ElementsMatch(t, lo.Map(first.Places, func(place dto.GroupPlacesSnapshotPlace, _ int) string { return place.PlaceID }), placeIDs(groupResp.Places))
+
+		snapshots := listHistory()
+		require.Len(t, snapshots, 3)
+		assert.Equal(t, first.ID, snapshots[0].RestoredFrom)
+		assert.Equal(t, first.PlaceCount, snapshots[0].PlaceCount)
+	})
+
+	t.Run("no restore while a venue is chosen", func(t *testing.T) {
+		snapshots := listHistory()
+		status, body := send(fiber.MethodGet, "/places", admin.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var places []dto.GroupPlaceResponse
+		require.NoError(t, json.Unmarshal(body, &places))
+		require.NotEmpty(t, places)
+
+		status, _ = send(fiber.MethodPut, "/venue", admin.Token, dto.GroupVenueRequest{PlaceID: places[0].PlaceID})
+		require.Equal(t, fiber.StatusOK, status)
+		status, _ = send(fiber.MethodPost, fmt.Sprintf("/places/history/%d/restore", snapshots[1].ID), admin.Token, nil)
+		assert.Equal(t, fiber.StatusConflict, status)
+	})
+}
@@ -0,0 +1,46 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupPlaces(t *testing.T) {
+	user := tests.TestUtil_CreateUser(t, "testuser5701@test.com", "testpassword5701")
+	group := tests.TestUtil_CreateGroup(t, user.Token, "Test Group 5701")
+
+	body := lo.Must(json.Marshal(dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 28.6139, Longitude: 77.2090}}))
+	req := httptest.NewRequest(fiber.MethodPut, "/v1/groups/"+group.ID+"/join", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+user.Token)
+	assert.Equal(t, fiber.StatusAccepted, lo.Must(tests.App.Test(req, -1)).StatusCode)
+	tests.TestUtil_WaitForGroupRefresh(t, user.Token, group.ID)
+
+	req = httptest.NewRequest(fiber.MethodGet, "/v1/groups/"+group.ID+"?includePlaces=true", nil)
+	req.Header.Set("Authorization", "Bearer "+user.Token)
+	resp := lo.Must(tests.App.Test(req, -1))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var groupResp dto.GroupResponse
+	assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &groupResp))
+	// the fake places provider finds a few places of every type
+	assert.NotEmpty(t, groupResp.Places)
+	// so they are found without widening the search
+	assert.Equal(t, groupResp.Radius, groupResp.PlacesRadius)
+	for _, placeType := range groupResp.PlaceTypes {
+		assert.True(t, lo.ContainsBy(groupResp.Places, func(place dto.GroupPlaceResponse) bool { return place.Type == placeType }))
+	}
+	for _, place := range groupResp.Places {
+		assert.Len(t, place.MemberETAs, 1)
+		assert.NotNil(t, place.MemberETAs[0].Minutes)
+	}
+}
@@ -0,0 +1,158 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupSavedLocations(t *testing.T) {
+	user1 := tests.TestUtil_CreateUser(t, "testuser7101@test.com", "testpassword7101")
+	user2 := tests.TestUtil_CreateUser(t, "testuser7102@test.com", "testpassword7102")
+	group := tests.TestUtil_CreateGroup(t, user1.Token, "Test Group 7101")
+	locationsPath := fmt.Sprintf("/users/%d/locations", user1.ID)
+
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1"+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	join := func(token string, req dto.GroupUserJoinRequest) (int, dto.GroupUserResponse) {
+		status, body := send(fiber.MethodPut, "/groups/"+group.ID+"/join", token, req)
+		var groupUserResp dto.GroupUserResponse
+		_ = json.Unmarshal(body, &groupUserResp)
+		return status, groupUserResp
+	}
+	getMember := func(userID uint) dto.GroupUserResponse {
+		tests.TestUtil_WaitForGroupRefresh(t, user1.Token, group.ID)
+		status, body := send(fiber.MethodGet, "/groups/"+group.ID+"?includeUsers=true", user1.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var groupResp dto.GroupResponse
+		require.NoError(t, json.Unmarshal(body, &groupResp))
+		member, found := lo.Find(groupResp.Members, func(member dto.GroupUserResponse) bool { return member.UserID == userID })
+		require.True(t, found)
+		return member
+	}
+
+	t.Run("save named locations", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, locationsPath+"/home", user1.Token, dto.SavedLocationRequest{Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}})
+		require.Equal(t, fiber.StatusOK, status)
+		status, _ = send(fiber.MethodPut, locationsPath+"/Work%202", user1.Token, dto.SavedLocationRequest{Address: "Le Marais"})
+		require.Equal(t, fiber.StatusOK, status)
+
+		status, body := send(fiber.MethodGet, locationsPath, user1.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var locations []dto.SavedLocationResponse
+		require.NoError(t, json.Unmarshal(body, &locations))
+		// no default location yet, as user1 never set their own location
+		require.Len(t, locations, 2)
+		assert.Equal(t, "Work 2", locations[0].Name)
+		assert.InDelta(t, 48.8590, locations[0].Latitude, 0.0001)
+		assert.Equal(t, "home", locations[1].Name)
+	})
+
+	t.Run("invalid saved locations", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, locationsPath+"/..%2Fhome", user1.Token, dto.SavedLocationRequest{Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		status, _ = send(fiber.MethodPut, locationsPath+"/home", user2.Token, dto.SavedLocationRequest{Location: dto.Location{Latitude: 48.8566, Longitude: 2.3522}})
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = join(user1.Token, dto.GroupUserJoinRequest{SavedLocation: "gym"})
+		assert.Equal(t, fiber.StatusNotFound, status)
+		status, _ = join(user1.Token, dto.GroupUserJoinRequest{SavedLocation: config.DefaultSavedLocation})
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+
+	t.Run("join from a saved location", func(t *testing.T) {
+		// the midpoint is only shown exactly for exact locations
+		status, member := join(user1.Token, dto.GroupUserJoinRequest{SavedLocation: "home", LocationPrivacy: config.LocationPrivacyExact})
+		require.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, "home", member.SavedLocation)
+		assert.InDelta(t, 48.8566, member.Latitude, 0.0001)
+
+		status, _ = join(user2.Token, dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 48.8700, Longitude: 2.3500}, LocationPrivacy: config.LocationPrivacyExact})
+		require.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, "home", getMember(user1.ID).SavedLocation)
+	})
+
+	t.Run("moving a saved location moves the membership and the midpoint", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, locationsPath+"/home", user1.Token, dto.SavedLocationRequest{Location: dto.Location{Latitude: 48.8500, Longitude: 2.3500}})
+		require.Equal(t, fiber.StatusOK, status)
+
+		assert.InDelta(t, 48.8500, getMember(user1.ID).Latitude, 0.0001)
+		status, body := send(fiber.MethodGet, "/groups/"+group.ID, user1.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var groupResp dto.GroupResponse
+		require.NoError(t, json.Unmarshal(body, &groupResp))
+		assert.InDelta(t, (48.8500+48.8700)/2, groupResp.MidpointLatitude, 0.0001)
+	})
+
+	t.Run("switch to another saved location", func(t *testing.T) {
+		status, member := join(user1.Token, dto.GroupUserJoinRequest{SavedLocation: "Work 2"})
+		require.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, "Work 2", member.SavedLocation)
+		assert.InDelta(t, 48.8590, getMember(user1.ID).Latitude, 0.0001)
+
+		// home doesn't move the membership anymore
+		status, _ = send(fiber.MethodPut, locationsPath+"/home", user1.Token, dto.SavedLocationRequest{Location: dto.Location{Latitude: 48.8400, Longitude: 2.3500}})
+		require.Equal(t, fiber.StatusOK, status)
+		assert.InDelta(t, 48.8590, getMember(user1.ID).Latitude, 0.0001)
+	})
+
+	t.Run("moving a saved location too far away stops following it", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, locationsPath+"/Work%202", user1.Token, dto.SavedLocationRequest{Location: dto.Location{Latitude: 40.7128, Longitude: -74.0060}})
+		require.Equal(t, fiber.StatusOK, status)
+
+		member := getMember(user1.ID)
+		assert.InDelta(t, 48.8590, member.Latitude, 0.0001)
+		assert.Empty(t, member.SavedLocation)
+	})
+
+	t.Run("default location is the user's own location", func(t *testing.T) {
+		status, _ := send(fiber.MethodPost, fmt.Sprintf("/users/%d", user1.ID), user1.Token, dto.UserUpdateRequest{Location: dto.Location{Latitude: 48.8600, Longitude: 2.3400}})
+		require.Equal(t, fiber.StatusAccepted, status)
+		status, member := join(user1.Token, dto.GroupUserJoinRequest{SavedLocation: config.DefaultSavedLocation})
+		require.Equal(t, fiber.StatusAccepted, status)
+		assert.InDelta(t, 48.8600, member.Latitude, 0.0001)
+
+		status, _ = send(fiber.MethodPost, fmt.Sprintf("/users/%d", user1.ID), user1.Token, dto.UserUpdateRequest{Location: dto.Location{Latitude: 48.8650, Longitude: 2.3400}})
+		require.Equal(t, fiber.StatusAccepted, status)
+		assert.InDelta(t, 48.8650, getMember(user1.ID).Latitude, 0.0001)
+
+		status, _ = send(fiber.MethodDelete, locationsPath+"/"+config.DefaultSavedLocation, user1.Token, nil)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("joining from a location stops following the saved location", func(t *testing.T) {
+		status, member := join(user1.Token, dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 48.8550, Longitude: 2.3450}})
+		require.Equal(t, fiber.StatusAccepted, status)
+		assert.Empty(t, member.SavedLocation)
+	})
+
+	t.Run("delete a saved location", func(t *testing.T) {
+		status, _ := send(fiber.MethodDelete, locationsPath+"/Work%202", user1.Token, nil)
+		assert.Equal(t, fiber.StatusNoContent, status)
+		status, _ = send(fiber.MethodDelete, locationsPath+"/Work%202", user1.Token, nil)
+		assert.Equal(t, fiber.StatusNotFound, status)
+
+		status, body := send(fiber.MethodGet, locationsPath, user1.Token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var locations []dto.SavedLocationResponse
+		require.NoError(t, json.Unmarshal(body, &locations))
+		assert.Equal(t, []string{config.DefaultSavedLocation, "home"}, lo.Map(locations, func(location dto.SavedLocationResponse, _ int) string {
+			return location.Name
+		}))
+	})
+}
@@ -0,0 +1,84 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupSearchPreferences(t *testing.T) {
+	user := tests.TestUtil_CreateUser(t, "testuser6301@test.com", "testpassword6301")
+	group := tests.TestUtil_CreateGroup(t, user.Token, "Test Group 6301")
+
+	send := func(method string, path string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1/groups/"+group.ID+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	update := func(body string) (int, dto.GroupResponse) {
+		req := httptest.NewRequest(fiber.MethodPatch, "/v1/groups/"+group.ID, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		var group dto.GroupResponse
+		_ = json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &group)
+		return resp.StatusCode, group
+	}
+	placesByType := func() map[config.PlaceType]int {
+		status, body := send(fiber.MethodGet, "?includePlaces=true", nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		var group dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(body, &group))
+		return lo.CountValuesBy(group.Places, func(place dto.GroupPlaceResponse) config.PlaceType { return place.Type })
+	}
+
+	status, _ := send(fiber.MethodPut, "/join", dto.GroupUserJoinRequest{Location: dto.Location{Latitude: 40.7128, Longitude: -74.0060}})
+	assert.Equal(t, fiber.StatusAccepted, status)
+	tests.TestUtil_WaitForGroupRefresh(t, user.Token, group.ID)
+
+	t.Run("default number of places per type", func(t *testing.T) {
+		for placeType, count := range placesByType() {
+			assert.Equal(t, config.PlacesResultsPerType, count, placeType)
+		}
+	})
+
+	t.Run("more places per type", func(t *testing.T) {
+		status, updated := update(`{"place_types": ["cafe", "bar"], "search_preferences": {"results_per_type": 6}}`)
+		assert.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, 6, updated.SearchPreferences.ResultsPerType)
+		tests.TestUtil_WaitForGroupRefresh(t, user.Token, group.ID)
+
+		assert.Equal(t, map[config.PlaceType]int{config.PlaceTypeCafe: 6, config.PlaceTypeBar: 6}, placesByType())
+	})
+
+	t.Run("filtered places", func(t *testing.T) {
+		status, updated := update(`{"search_preferences": {"results_per_type": 6, "max_price_level": 3, "open_now": true, "wheelchair_accessible": true}}`)
+		assert.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, dto.PlaceSearchPreferences{ResultsPerType: 6, MaxPriceLevel: config.PriceLevelModerate, OpenNow: true, WheelchairAccessible: true}, updated.SearchPreferences)
+		tests.TestUtil_WaitForGroupRefresh(t, user.Token, group.ID)
+
+		// made up places are found 20 at a time when filtering, enough for some to match
+		for _, count := range placesByType() {
+			assert.Greater(t, count, 0)
+			assert.LessOrEqual(t, count, 6)
+		}
+	})
+
+	t.Run("invalid preferences are rejected", func(t *testing.T) {
+		status, _ := update(`{"search_preferences": {"results_per_type": 50}}`)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		status, _ = update(`{"search_preferences": {"min_price_level": 4, "max_price_level": 2}}`)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+}
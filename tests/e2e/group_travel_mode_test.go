@@ -0,0 +1,53 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupTravelMode(t *testing.T) {
+	user1 := tests.TestUtil_CreateUser(t, "testuser5301@test.com", "testpassword5301")
+	group := tests.TestUtil_CreateGroup(t, user1.Token, "Test Group 5301")
+
+	join := func(body string) (int, dto.GroupUserResponse) {
+		req := httptest.NewRequest(fiber.MethodPut, "/v1/groups/"+group.ID+"/join", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+user1.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		var groupUserResp dto.GroupUserResponse
+		_ = json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &groupUserResp)
+		return resp.StatusCode, groupUserResp
+	}
+
+	t.Run("invalid travel mode is rejected", func(t *testing.T) {
+		status, _ := join(`{"latitude": 51.5, "longitude": -0.1, "travel_mode": "teleport"}`)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("travel mode defaults to drive", func(t *testing.T) {
+		status, groupUserResp := join(`{"latitude": 51.5, "longitude": -0.1}`)
+		assert.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, config.TravelModeDrive, groupUserResp.TravelMode)
+	})
+
+	t.Run("travel mode can be changed by joining again", func(t *testing.T) {
+		status, groupUserResp := join(`{"latitude": 51.5, "longitude": -0.1, "travel_mode": "bike"}`)
+		assert.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, config.TravelModeBike, groupUserResp.TravelMode)
+
+		// joining again without a travel mode keeps the one picked before
+		status, groupUserResp = join(`{"latitude": 51.6, "longitude": -0.1}`)
+		assert.Equal(t, fiber.StatusAccepted, status)
+		assert.Equal(t, config.TravelModeBike, groupUserResp.TravelMode)
+	})
+}
@@ -0,0 +1,119 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupVenue(t *testing.T) {
+	admin := tests.TestUtil_CreateUser(t, "testuser6101@test.com", "testpassword6101")
+	member := tests.TestUtil_CreateUser(t, "testuser6102@test.com", "testpassword6102")
+	group := tests.TestUtil_CreateGroup(t, admin.Token, "Test Group 6101")
+
+	send := func(method string, path string, token string, body any) (int, []byte) {
+		req := httptest.NewRequest(method, "/v1/groups/"+group.ID+path, bytes.NewBuffer(lo.Must(json.Marshal(body))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+	join := func(token string, location dto.Location) {
+		status, _ := send(fiber.MethodPut, "/join", token, dto.GroupUserJoinRequest{Location: location})
+		assert.Equal(t, fiber.StatusAccepted, status)
+	}
+	getGroup := func() dto.GroupResponse {
+		status, body := send(fiber.MethodGet, "?includePlaces=true", admin.Token, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		var group dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(body, &group))
+		return group
+	}
+	placeIDs := func(group dto.GroupResponse) []string {
+		return lo.Map(group.Places, func(place dto.GroupPlaceResponse, _ int) string { return place.PlaceID })
+	}
+
+	join(admin.Token, dto.Location{Latitude: 48.8566, Longitude: 2.3522})
+	join(member.Token, dto.Location{Latitude: 48.8606, Longitude: 2.3376})
+	tests.TestUtil_WaitForGroupRefresh(t, admin.Token, group.ID)
+
+	before := getGroup()
+	assert.Nil(t, before.Venue)
+	require.NotEmpty(t, before.Places)
+	chosen := before.Places[0]
+
+	t.Run("only admins choose a venue of the group", func(t *testing.T) {
+		status, _ := send(fiber.MethodPut, "/venue", member.Token, dto.GroupVenueRequest{PlaceID: chosen.PlaceID})
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = send(fiber.MethodPut, "/venue", admin.Token, dto.GroupVenueRequest{PlaceID: "not-a-place"})
+		assert.Equal(t, fiber.StatusNotFound, status)
+		status, _ = send(fiber.MethodPut, "/venue", admin.Token, dto.GroupVenueRequest{})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("admin chooses one of the group places", func(t *testing.T) {
+		status, body := send(fiber.MethodPut, "/venue", admin.Token, dto.GroupVenueRequest{PlaceID: chosen.PlaceID})
+		assert.Equal(t, fiber.StatusOK, status)
+		var group dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(body, &group))
+		assert.NotNil(t, group.Venue)
+		assert.Equal(t, chosen.PlaceID, group.Venue.PlaceID)
+		assert.Equal(t, chosen.Name, group.Venue.Name)
+		assert.False(t, group.Venue.Custom)
+		assert.Equal(t, admin.ID, group.Venue.ChosenBy)
+	})
+
+	t.Run("places are kept while the venue is chosen", func(t *testing.T) {
+		join(member.Token, dto.Location{Latitude: 48.8049, Longitude: 2.1204})
+		tests.TestUtil_WaitForGroupRefresh(t, admin.Token, group.ID)
+
+		after := getGroup()
+		assert.NotEqual(t, before.MidpointLatitude, after.MidpointLatitude)
+		assert.ElementsMatch(t, placeIDs(before), placeIDs(after))
+		require.NotNil(t, after.Venue)
+		assert.Equal(t, chosen.PlaceID, after.Venue.PlaceID)
+	})
+
+	t.Run("admin chooses a custom place", func(t *testing.T) {
+		custom := &dto.CustomVenueRequest{Name: "Our Flat", Address: "1 Rue de Rivoli", Location: dto.Location{Latitude: 48.8556, Longitude: 2.3600}}
+		status, body := send(fiber.MethodPut, "/venue", admin.Token, dto.GroupVenueRequest{Custom: custom})
+		assert.Equal(t, fiber.StatusOK, status)
+		var group dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(body, &group))
+		require.NotNil(t, group.Venue)
+		assert.True(t, group.Venue.Custom)
+		assert.Empty(t, group.Venue.PlaceID)
+		assert.Equal(t, "Our Flat", group.Venue.Name)
+		assert.Equal(t, 48.8556, group.Venue.Latitude)
+
+		saved := getGroup()
+		require.NotNil(t, saved.Venue)
+		assert.Equal(t, "Our Flat", saved.Venue.Name)
+	})
+
+	t.Run("clearing the venue resumes place suggestions", func(t *testing.T) {
+		status, _ := send(fiber.MethodDelete, "/venue", member.Token, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+
+		status, body := send(fiber.MethodDelete, "/venue", admin.Token, nil)
+		assert.Equal(t, fiber.StatusAccepted, status)
+		var group dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(body, &group))
+		assert.Nil(t, group.Venue)
+		tests.TestUtil_WaitForGroupRefresh(t, admin.Token, group.ID)
+
+		after := getGroup()
+		assert.Nil(t, after.Venue)
+		assert.NotEmpty(t, after.Places)
+		assert.NotElementsMatch(t, placeIDs(before), placeIDs(after))
+	})
+}
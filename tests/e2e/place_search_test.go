@@ -0,0 +1,79 @@
+package e2e
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceSearch(t *testing.T) {
+	user := tests.TestUtil_CreateUser(t, "testuser6701@test.com", "testpassword6701")
+
+	search := func(query string, token string) (int, dto.PlaceSearchResponse) {
+		req := httptest.NewRequest(fiber.MethodGet, "/v1/places/search?"+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := lo.Must(tests.App.Test(req, -1))
+		var results dto.PlaceSearchResponse
+		if resp.StatusCode == fiber.StatusOK {
+			assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &results))
+		}
+		return resp.StatusCode, results
+	}
+	around := "lat=35.6762&lng=139.6503&radius=2000"
+
+	t.Run("nearby places of a type, page by page", func(t *testing.T) {
+		status, first := search(around+"&type=cafe&page_size=15", user.Token)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, config.MaxPlacesResultsPerType, first.Total)
+		assert.Len(t, first.Places, 15)
+		assert.True(t, first.HasMore)
+		for _, place := range first.Places {
+			assert.Equal(t, config.PlaceTypeCafe, place.Type)
+		}
+
+		status, second := search(around+"&type=cafe&page_size=15&page=2", user.Token)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Len(t, second.Places, config.MaxPlacesResultsPerType-15)
+		assert.False(t, second.HasMore)
+		assert.NotEqual(t, first.Places[0].Id, second.Places[0].Id)
+
+		status, beyond := search(around+"&type=cafe&page=9", user.Token)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Empty(t, beyond.Places)
+	})
+
+	t.Run("places matching a query", func(t *testing.T) {
+		status, results := search(around+"&query=ramen", user.Token)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.NotEmpty(t, results.Places)
+		assert.Len(t, results.Places, config.DefaultPlaceSearchPageSize)
+		for _, place := range results.Places {
+			assert.True(t, strings.Contains(place.Name, "ramen"), place.Name)
+		}
+	})
+
+	t.Run("invalid searches", func(t *testing.T) {
+		status, _ := search(around+"&type=cafe", "")
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+		status, _ = search("radius=2000&type=cafe", user.Token)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		status, _ = search(around, user.Token)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		status, _ = search(around+"&type=castle", user.Token)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		status, _ = search(around+"&type=cafe&page_size=50", user.Token)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+}
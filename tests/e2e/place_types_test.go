@@ -0,0 +1,52 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/championswimmer/api.midpoint.place/src/config"
+	"github.com/championswimmer/api.midpoint.place/src/dto"
+	"github.com/championswimmer/api.midpoint.place/tests"
+	"github.com/gofiber/fiber/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaceTypes(t *testing.T) {
+	user := tests.TestUtil_CreateUser(t, "testuser6401@test.com", "testpassword6401")
+	group := tests.TestUtil_CreateGroup(t, user.Token, "Test Group 6401")
+	updateGroup := func(body string) (int, []byte) {
+		req := httptest.NewRequest(fiber.MethodPatch, "/v1/groups/"+group.ID, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+		resp := lo.Must(tests.App.Test(req, -1))
+		return resp.StatusCode, lo.Must(io.ReadAll(resp.Body))
+	}
+
+	t.Run("list place types", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/v1/places/types", nil)
+		resp := lo.Must(tests.App.Test(req, -1))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var placeTypes []dto.PlaceTypeResponse
+		assert.NoError(t, json.Unmarshal(lo.Must(io.ReadAll(resp.Body)), &placeTypes))
+		cafe, found := lo.Find(placeTypes, func(placeType dto.PlaceTypeResponse) bool { return placeType.ID == config.PlaceTypeCafe })
+		assert.True(t, found)
+		assert.Equal(t, dto.PlaceTypeResponse{ID: config.PlaceTypeCafe, Label: "Cafe", Icon: "local_cafe", Default: true}, cafe)
+		assert.True(t, lo.ContainsBy(placeTypes, func(placeType dto.PlaceTypeResponse) bool { return placeType.ID == "cinema" && !placeType.Default }))
+	})
+
+	t.Run("groups search for place types from the taxonomy", func(t *testing.T) {
+		status, body := updateGroup(`{"place_types": ["gym", "cinema"]}`)
+		assert.Equal(t, fiber.StatusAccepted, status)
+		var updated dto.GroupResponse
+		assert.NoError(t, json.Unmarshal(body, &updated))
+		assert.Equal(t, []config.PlaceType{"gym", "cinema"}, updated.PlaceTypes)
+
+		status, _ = updateGroup(`{"place_types": ["castle"]}`)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+}